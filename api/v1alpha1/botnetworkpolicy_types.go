@@ -4,8 +4,12 @@ package v1alpha1
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
 	"strings"
 
+	"github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,15 +37,31 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 
 // BotNetworkPolicySpec defines the desired state of BotNetworkPolicy.
 type BotNetworkPolicySpec struct {
-	// PodSelector selects the pods to which the NetworkPolicy will apply. If omitted, it targets all pods in the namespace.
+	// PodSelector selects the pods to which the NetworkPolicy will apply. It is required
+	// unless TargetAllPods is set.
 	// +optional
 	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
 
-	// NamespaceSelector optionally restricts target namespaces. Currently informational.
+	// TargetAllPods must be true to apply the NetworkPolicy to every pod in the namespace
+	// with an empty pod selector. It guards against namespace-wide policies created by
+	// omitting podSelector by accident, and cannot be combined with a non-empty podSelector.
+	// +optional
+	TargetAllPods *bool `json:"targetAllPods,omitempty"`
+
+	// PeerPodSelector additionally allows pods in the same namespace matching this selector.
+	// It is added as a separate peer next to the IPBlock peers of every generated rule.
+	// +optional
+	PeerPodSelector *metav1.LabelSelector `json:"peerPodSelector,omitempty"`
+
+	// NamespaceSelector additionally allows pods in namespaces matching this selector. It is
+	// added as a separate peer next to the IPBlock peers of every generated rule; an empty
+	// selector matches all namespaces.
 	// +optional
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 
-	// PolicyTypes explicitly sets the policy types. If empty, they are derived from ingress/egress flags.
+	// PolicyTypes explicitly sets the policy types. If omitted, they are derived from ingress/egress flags.
+	// An explicitly empty list is rejected: a NetworkPolicy always isolates the pods it selects for at
+	// least ingress, so a policy that restricts nothing cannot be expressed.
 	// +optional
 	PolicyTypes []networkingv1.PolicyType `json:"policyTypes,omitempty"`
 
@@ -53,27 +73,150 @@ type BotNetworkPolicySpec struct {
 	// +optional
 	Egress *bool `json:"egress,omitempty"`
 
+	// Ports restricts every generated ingress and egress rule to these ports and protocols.
+	// Empty allows all ports, as before.
+	// +optional
+	Ports []networkingv1.NetworkPolicyPort `json:"ports,omitempty"`
+
 	// Providers declares the providers that should be consulted for IP ranges.
 	Providers []ProviderSpec `json:"providers"`
 
+	// IngressProviders overrides Providers for ingress rules when set.
+	// +optional
+	IngressProviders []ProviderSpec `json:"ingressProviders,omitempty"`
+
+	// EgressProviders overrides Providers for egress rules when set.
+	// +optional
+	EgressProviders []ProviderSpec `json:"egressProviders,omitempty"`
+
+	// EgressPodNamespaceSelector adds the IPs of running pods in namespaces matching this
+	// selector as egress IPBlock peers, for in-cluster destinations. Pod IPs are resolved
+	// on every reconcile, at least once a minute, so pods created or moved in between are
+	// not allowed until the next one.
+	// +optional
+	EgressPodNamespaceSelector *metav1.LabelSelector `json:"egressPodNamespaceSelector,omitempty"`
+
 	// CustomCIDRs adds additional CIDRs that should be included in the generated NetworkPolicy.
 	// +optional
 	CustomCIDRs []string `json:"customCidrs,omitempty"`
 
+	// ExceptCIDRs carves ranges out of the allowed CIDRs. Each entry is added to the except
+	// list of every generated IPBlock whose CIDR strictly contains it; entries contained in
+	// no allowed CIDR are reported with an UnusedExcept event.
+	// +optional
+	ExceptCIDRs []string `json:"exceptCidrs,omitempty"`
+
+	// AggregateCIDRs collapses the collected CIDRs into the smallest set covering the same
+	// addresses, dropping ranges contained in others and merging adjacent ones, which
+	// shrinks policies built from overlapping providers.
+	// +optional
+	AggregateCIDRs *bool `json:"aggregateCidrs,omitempty"`
+
+	// MaxCIDRs caps how many CIDRs each direction of the generated rules holds, for CNIs
+	// that mishandle large policies. A larger set keeps the first MaxCIDRs in sorted order,
+	// emits a warning and sets the CIDRsTruncated condition. Zero means no limit.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxCIDRs int `json:"maxCidrs,omitempty"`
+
+	// DryRun computes the generated NetworkPolicies and reports how they differ from the
+	// applied ones in events and status.plannedCidrCount, without creating, updating or
+	// deleting any NetworkPolicy.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// IPFamily keeps only the CIDRs of one address family, IPv4 or IPv6, from customCidrs
+	// and from every provider that does not set its own ipFamily. Empty keeps both.
+	// +optional
+	// +kubebuilder:validation:Enum=IPv4;IPv6
+	IPFamily string `json:"ipFamily,omitempty"`
+
 	// SyncPeriod defines how frequently the controller should refresh the provider data.
 	// +optional
 	SyncPeriod metav1.Duration `json:"syncPeriod,omitempty"`
+
+	// MaxPeersPerPolicy splits the generated rules across several NetworkPolicies, each holding at
+	// most this many peers per direction. Additional policies are suffixed -1, -2, and so on.
+	// Zero keeps everything in a single NetworkPolicy.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxPeersPerPolicy int `json:"maxPeersPerPolicy,omitempty"`
+
+	// BaselinePolicyRef references a NetworkPolicy holding a mandatory allowlist. Every
+	// ipBlock CIDR in the baseline must also be allowed by the generated rules for the same
+	// direction, lying within a generated CIDR and outside ExceptCIDRs; otherwise the
+	// BaselineViolation condition is set.
+	// +optional
+	BaselinePolicyRef *BaselinePolicyReference `json:"baselinePolicyRef,omitempty"`
+
+	// MaintenanceWindow defers changes that remove CIDRs from the generated NetworkPolicies
+	// until the next window opens. Changes that only add CIDRs apply immediately unless
+	// DeferAdditions is set.
+	// +optional
+	MaintenanceWindow *MaintenanceWindowSpec `json:"maintenanceWindow,omitempty"`
+
+	// AuditMode annotates the generated NetworkPolicies so that CNIs supporting a log-only
+	// mode observe matching traffic without enforcing the policy. The annotation key is set
+	// on the operator and must match the installed CNI; other CNIs enforce as usual.
+	// +optional
+	AuditMode *bool `json:"auditMode,omitempty"`
+}
+
+// MaintenanceWindowSpec describes recurring windows in which deferred changes are applied.
+type MaintenanceWindowSpec struct {
+	// Schedule is a standard five-field cron expression for when each window opens, e.g.
+	// "0 2 * * *". It is evaluated in the controller's time zone unless prefixed with
+	// CRON_TZ=, e.g. "CRON_TZ=Europe/Berlin 0 2 * * 6".
+	Schedule string `json:"schedule"`
+
+	// Duration is how long each window stays open.
+	Duration metav1.Duration `json:"duration"`
+
+	// DeferAdditions also defers changes that only add CIDRs.
+	// +optional
+	DeferAdditions bool `json:"deferAdditions,omitempty"`
+}
+
+// Validate checks that the schedule parses and the window has a positive duration.
+func (w *MaintenanceWindowSpec) Validate() error {
+	if _, err := cron.ParseStandard(w.Schedule); err != nil {
+		return fmt.Errorf("maintenanceWindow has invalid schedule %q: %w", w.Schedule, err)
+	}
+	if w.Duration.Duration <= 0 {
+		return fmt.Errorf("maintenanceWindow requires a positive duration")
+	}
+	return nil
+}
+
+// BaselinePolicyReference identifies a NetworkPolicy used as a baseline.
+type BaselinePolicyReference struct {
+	// Name is the name of the NetworkPolicy.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the NetworkPolicy. Defaults to the namespace of the BotNetworkPolicy.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // ProviderSpec describes a single provider.
 type ProviderSpec struct {
-	// Name identifies the provider type. Supported values: google, aws, github, configMap, jsonEndpoint.
+	// Name identifies the provider type. Supported values: google, aws, azure, github, cloudflare, configMap, jsonEndpoint, directory, redis, scrape.
 	Name string `json:"name"`
 
+	// DisplayName distinguishes this provider in events, metrics and status, e.g. when two
+	// providers of the same type are configured. Defaults to Name.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
 	// ConfigMap configures the built-in config map provider.
 	// +optional
 	ConfigMap *ConfigMapProviderSpec `json:"configMap,omitempty"`
 
+	// ConfigRef reuses a provider configuration shared through a ConfigMap. The fields
+	// set here take precedence over the shared ones, top-level field by field.
+	// +optional
+	ConfigRef *ProviderConfigRef `json:"configRef,omitempty"`
+
 	// JSONEndpoint configures the JSON endpoint provider that extracts CIDRs from a JSON response body.
 	// +optional
 	JSONEndpoint *JSONEndpointProviderSpec `json:"jsonEndpoint,omitempty"`
@@ -86,9 +229,116 @@ type ProviderSpec struct {
 	// +optional
 	AWS *AWSProviderSpec `json:"aws,omitempty"`
 
+	// Azure configures the Azure service tags provider.
+	// +optional
+	Azure *AzureProviderSpec `json:"azure,omitempty"`
+
 	// GitHub configures the GitHub provider with role selection.
 	// +optional
 	GitHub *GitHubProviderSpec `json:"github,omitempty"`
+
+	// Cloudflare configures the Cloudflare provider.
+	// +optional
+	Cloudflare *CloudflareProviderSpec `json:"cloudflare,omitempty"`
+
+	// Directory configures the directory provider that reads CIDRs from an attribute of
+	// directory entries served as JSON.
+	// +optional
+	Directory *DirectoryProviderSpec `json:"directory,omitempty"`
+
+	// Redis configures the Redis provider that reads CIDRs from a set or list key.
+	// +optional
+	Redis *RedisProviderSpec `json:"redis,omitempty"`
+
+	// Scrape configures the scrape provider that extracts CIDRs from a web page or feed.
+	// +optional
+	Scrape *ScrapeProviderSpec `json:"scrape,omitempty"`
+
+	// AllowEmpty treats an empty result as valid instead of an error.
+	// Only supported by the configMap, jsonEndpoint, directory, redis and scrape providers; the built-in feeds always error when empty.
+	// +optional
+	AllowEmpty *bool `json:"allowEmpty,omitempty"`
+
+	// AllowedSupernets restricts this provider to CIDRs contained in one of these ranges,
+	// e.g. ["13.0.0.0/8"]. CIDRs outside every supernet are dropped with a warning.
+	// +optional
+	AllowedSupernets []string `json:"allowedSupernets,omitempty"`
+
+	// MaxCIDRs caps how many CIDRs this provider contributes. A larger result keeps the
+	// first MaxCIDRs in sorted order and emits a warning. Zero means no limit.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxCIDRs int `json:"maxCidrs,omitempty"`
+
+	// IPFamily keeps only the CIDRs of one address family, IPv4 or IPv6, e.g. for CNIs
+	// without IPv6 IPBlock support. Empty defers to the policy's ipFamily.
+	// +optional
+	// +kubebuilder:validation:Enum=IPv4;IPv6
+	IPFamily string `json:"ipFamily,omitempty"`
+
+	// TimeoutSeconds bounds how long fetching from this provider may take, retries
+	// included. A provider that times out is skipped with a ProviderTimeout warning event
+	// instead of failing the reconcile. Zero leaves the fetch bounded only by the HTTP client.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// HMACSigning signs every HTTP request of this provider with HMAC-SHA256 using a key
+	// read from a Secret. Not supported by the configMap and redis providers.
+	// +optional
+	HMACSigning *HMACSigningSpec `json:"hmacSigning,omitempty"`
+
+	// ProxyURL sends every HTTP request of this provider through this proxy, e.g.
+	// http://proxy.corp.example:3128, instead of the proxy configured through the
+	// HTTP_PROXY and HTTPS_PROXY environment variables. Not supported by the configMap and
+	// redis providers.
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// ProxyCredentialsSecretRef authenticates to ProxyURL with a username and password read
+	// from a Secret. Requires ProxyURL.
+	// +optional
+	ProxyCredentialsSecretRef *BasicAuthSecretRef `json:"proxyCredentialsSecretRef,omitempty"`
+}
+
+// IP families accepted by ProviderSpec.IPFamily and BotNetworkPolicySpec.IPFamily.
+const (
+	IPFamilyIPv4 = "IPv4"
+	IPFamilyIPv6 = "IPv6"
+)
+
+// DefaultProviderConfigKey is the ConfigMap key read by a ProviderConfigRef without Key.
+const DefaultProviderConfigKey = "provider.yaml"
+
+// ProviderConfigRef names a ConfigMap in the namespace of the BotNetworkPolicy whose key
+// holds a ProviderSpec as YAML or JSON, e.g. a jsonEndpoint configuration shared by
+// many resources.
+type ProviderConfigRef struct {
+	// Name is the name of the ConfigMap.
+	Name string `json:"name"`
+
+	// Key is the data key holding the provider configuration. Defaults to provider.yaml.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ConfigKey returns Key, or DefaultProviderConfigKey when it is empty.
+func (r *ProviderConfigRef) ConfigKey() string {
+	if r.Key == "" {
+		return DefaultProviderConfigKey
+	}
+	return r.Key
+}
+
+// HMACSigningSpec configures HMAC-SHA256 request signing. The signature covers the
+// method, request URI and a Unix timestamp sent in the X-Signature-Timestamp header.
+type HMACSigningSpec struct {
+	// SecretKeyRef selects the Secret key holding the HMAC key.
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+
+	// Header receives the hex-encoded signature. Defaults to X-Signature.
+	// +optional
+	Header string `json:"header,omitempty"`
 }
 
 // ConfigMapProviderSpec fetches CIDRs from a ConfigMap key.
@@ -100,8 +350,58 @@ type ConfigMapProviderSpec struct {
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 
+	// NamespaceSelector reads the ConfigMap from every namespace matching this selector
+	// instead of a single namespace, and unions their CIDRs. Namespaces without the
+	// ConfigMap are skipped. Cannot be combined with Namespace.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
 	// Key selects the data key within the ConfigMap that contains newline or comma-separated CIDRs.
 	Key string `json:"key"`
+
+	// VersionCommentPrefix marks a line carrying the feed version or checksum, e.g.
+	// "# serial " or "# sha256:". Matching lines are not parsed as CIDRs; the text after
+	// the prefix on the first one is reported in the provider status as feedVersion.
+	// +optional
+	VersionCommentPrefix string `json:"versionCommentPrefix,omitempty"`
+}
+
+// RedisProviderSpec fetches CIDRs from a Redis key holding a set or a list, for allowlists
+// that applications push to a key-value store.
+type RedisProviderSpec struct {
+	// Key is the Redis key holding the CIDRs. Set members and list elements are both accepted.
+	Key string `json:"key"`
+
+	// ConnectionSecretRef names a Secret in the BotNetworkPolicy namespace holding the
+	// connection details: "address" (host:port) and optionally "username" and "password".
+	ConnectionSecretRef corev1.LocalObjectReference `json:"connectionSecretRef"`
+
+	// DB selects the Redis logical database. Defaults to 0.
+	// +optional
+	DB int `json:"db,omitempty"`
+
+	// TLS connects to Redis over TLS.
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+}
+
+// ScrapeProviderSpec fetches a page, such as HTML or an RSS/Atom feed, and extracts every
+// token matching a pattern, for vendors that only publish ranges on a web page.
+type ScrapeProviderSpec struct {
+	// URL is the page to fetch.
+	URL string `json:"url"`
+
+	// Pattern is the regular expression matching CIDRs in the page. When it has a
+	// capturing group, the first group is used. Matches that do not parse as CIDRs are
+	// ignored. Defaults to matching IPv4 and IPv6 CIDRs.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// MaxBodyBytes bounds the size of the page. Larger responses fail the fetch.
+	// Defaults to 5 MiB.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty"`
 }
 
 // JSONEndpointProviderSpec fetches CIDRs from a JSON REST endpoint.
@@ -109,8 +409,12 @@ type JSONEndpointProviderSpec struct {
 	// URL is the HTTP endpoint to query.
 	URL string `json:"url"`
 
-	// FieldPath selects the JSON path (dot-separated) that contains the CIDR list.
-	FieldPath string `json:"fieldPath"`
+	// FieldPath selects the JSON path (dot-separated unless PathSeparator is set) that contains the CIDR list.
+	// Numeric segments index into arrays, e.g. regions.0.cidrs, and a segment ending in [*]
+	// collects the rest of the path from every element of an array, e.g. regions[*].cidr.
+	// Empty selects the document root, for endpoints returning a bare JSON array.
+	// +optional
+	FieldPath string `json:"fieldPath,omitempty"`
 
 	// Headers optionally adds headers to the HTTP request.
 	// +optional
@@ -120,27 +424,168 @@ type JSONEndpointProviderSpec struct {
 	// +optional
 	HeaderSecretRefs []HTTPHeaderSecretRef `json:"headerSecretRefs,omitempty"`
 
+	// BasicAuthSecretRef authenticates requests with HTTP basic auth using a username and
+	// password read from a Secret. Cannot be combined with BearerTokenSecretRef.
+	// +optional
+	BasicAuthSecretRef *BasicAuthSecretRef `json:"basicAuthSecretRef,omitempty"`
+
+	// BearerTokenSecretRef selects the Secret key holding a token sent as
+	// "Authorization: Bearer <token>". Cannot be combined with BasicAuthSecretRef.
+	// +optional
+	BearerTokenSecretRef *corev1.SecretKeySelector `json:"bearerTokenSecretRef,omitempty"`
+
 	// Filter optionally filters array elements based on field conditions.
 	// Only elements matching all filter conditions will be included.
 	// +optional
 	Filter *JSONFilterSpec `json:"filter,omitempty"`
+
+	// PathSeparator splits FieldPath and NextPageFieldPath into segments. Defaults to ".".
+	// Set it to another character when the JSON keys themselves contain dots, e.g. "/" for
+	// "data/region.name".
+	// +optional
+	PathSeparator string `json:"pathSeparator,omitempty"`
+
+	// MinItems fails the fetch when the value at FieldPath holds fewer items than this.
+	// Use it to catch upstream schema drift early. Zero disables the check. With paging,
+	// the items of every page count.
+	// +optional
+	MinItems int `json:"minItems,omitempty"`
+
+	// NextPageFieldPath selects the URL of the next page within each page, e.g.
+	// "links.next", for endpoints that split their results into pages. The values at
+	// FieldPath of every page are then concatenated. Relative URLs are resolved against the
+	// current page, and paging stops when the value is missing or empty. A next page on another
+	// scheme or host than URL is rejected, since it would receive the request headers.
+	// +optional
+	NextPageFieldPath string `json:"nextPageFieldPath,omitempty"`
+
+	// MaxPages bounds the number of pages fetched when NextPageFieldPath is set; the fetch
+	// fails once it is exceeded. Defaults to 100.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxPages int `json:"maxPages,omitempty"`
+
+	// InsecureSkipTLSVerify skips verification of the endpoint certificate, e.g. for an
+	// internal service with a self-signed certificate. Prefer caSecretRef or caConfigMapRef;
+	// every fetch logs an error and records an InsecureTLS event while this is set.
+	// +optional
+	InsecureSkipTLSVerify *bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// TLSClientSecretRef names a Secret holding tls.crt and tls.key, such as a Secret of
+	// type kubernetes.io/tls, presented as the client certificate for mutual TLS. When the
+	// Secret also holds ca.crt, the server certificate is verified against it instead of
+	// the system roots.
+	// +optional
+	TLSClientSecretRef *corev1.LocalObjectReference `json:"tlsClientSecretRef,omitempty"`
+
+	// CASecretRef selects a Secret key holding a PEM bundle of the certificate authorities
+	// trusted to sign the endpoint's certificate, in place of the system roots. Cannot be
+	// combined with CAConfigMapRef.
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// CAConfigMapRef selects a ConfigMap key holding the PEM bundle, like CASecretRef, for
+	// CA certificates that need not be secret.
+	// +optional
+	CAConfigMapRef *corev1.ConfigMapKeySelector `json:"caConfigMapRef,omitempty"`
+
+	// StripJSONP removes a JSONP callback wrapper such as `callback({...});` from the
+	// response before decoding. Bodies that are not wrapped are decoded unchanged.
+	// +optional
+	StripJSONP bool `json:"stripJSONP,omitempty"`
+
+	// MetadataFields names fields of array-of-objects entries (e.g. "asn", "org") to capture
+	// alongside each CIDR. The captured values are logged for debugging and do not affect
+	// the generated NetworkPolicy.
+	// +optional
+	MetadataFields []string `json:"metadataFields,omitempty"`
+}
+
+// DirectoryProviderSpec fetches directory entries (e.g. from an LDAP-to-JSON gateway) and
+// extracts CIDRs from a multi-valued attribute of each entry.
+type DirectoryProviderSpec struct {
+	// URL is the HTTP endpoint returning the first page of entries.
+	URL string `json:"url"`
+
+	// EntriesPath selects the array of directory entries within each page.
+	EntriesPath string `json:"entriesPath"`
+
+	// Attribute selects the attribute holding CIDRs within each entry. It may be a path,
+	// e.g. "attributes.ipNetworkNumber". Single values and arrays are both accepted.
+	Attribute string `json:"attribute"`
+
+	// NextPagePath selects the URL of the next page within each page. Relative URLs are
+	// resolved against the current page. Paging stops when the value is missing or empty. A
+	// next page on another scheme or host than URL is rejected, since it would receive the
+	// request headers.
+	// +optional
+	NextPagePath string `json:"nextPagePath,omitempty"`
+
+	// MaxPages bounds the number of pages fetched. Defaults to 100.
+	// +optional
+	MaxPages int `json:"maxPages,omitempty"`
+
+	// PathSeparator splits EntriesPath, Attribute and NextPagePath into segments. Defaults to ".".
+	// +optional
+	PathSeparator string `json:"pathSeparator,omitempty"`
+
+	// Headers optionally adds headers to each HTTP request.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// HeaderSecretRefs composes request headers from Kubernetes Secrets.
+	// +optional
+	HeaderSecretRefs []HTTPHeaderSecretRef `json:"headerSecretRefs,omitempty"`
 }
 
 // GoogleProviderSpec configures Google Cloud IP range fetching.
 type GoogleProviderSpec struct {
-	// URL overrides the default Google Cloud IP ranges endpoint.
-	// Default endpoints:
-	//   - goog.json: https://www.gstatic.com/ipranges/goog.json (all Google services)
-	//   - cloud.json: https://www.gstatic.com/ipranges/cloud.json (Google Cloud only)
+	// Feed selects the published range list and its default endpoint:
+	//   - goog: https://www.gstatic.com/ipranges/goog.json (all Google services, default)
+	//   - cloud: https://www.gstatic.com/ipranges/cloud.json (Google Cloud only, with a
+	//     region in each entry's scope)
+	// +optional
+	// +kubebuilder:validation:Enum=goog;cloud
+	Feed string `json:"feed,omitempty"`
+
+	// URL overrides the default endpoint of the selected Feed.
 	// +optional
 	URL string `json:"url,omitempty"`
 
+	// FallbackURLs lists mirror endpoints tried in order when the primary endpoint fails
+	// or returns an unusable document. Mirrors are fetched without the credentials and
+	// request signature of the primary endpoint.
+	// +optional
+	FallbackURLs []string `json:"fallbackURLs,omitempty"`
+
 	// Scope filters which Google services to include. If empty, all services are included.
 	// Examples: "google-cloud-platform", "google"
 	// +optional
 	Scope []string `json:"scope,omitempty"`
+
+	// InsecureSkipTLSVerify skips certificate verification of URL and FallbackURLs, for
+	// mirrors of the Google feeds without a trusted certificate.
+	// +optional
+	InsecureSkipTLSVerify *bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// CASecretRef selects a Secret key holding a PEM bundle of the certificate authorities
+	// trusted to sign the endpoint's certificate, in place of the system roots. Cannot be
+	// combined with CAConfigMapRef.
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// CAConfigMapRef selects a ConfigMap key holding the PEM bundle, like CASecretRef, for
+	// CA certificates that need not be secret.
+	// +optional
+	CAConfigMapRef *corev1.ConfigMapKeySelector `json:"caConfigMapRef,omitempty"`
 }
 
+// Google feed names accepted by GoogleProviderSpec.Feed.
+const (
+	GoogleFeedGoog  = "goog"
+	GoogleFeedCloud = "cloud"
+)
+
 // AWSProviderSpec configures AWS IP range fetching with filtering.
 type AWSProviderSpec struct {
 	// URL overrides the default AWS IP ranges endpoint.
@@ -148,12 +593,19 @@ type AWSProviderSpec struct {
 	// +optional
 	URL string `json:"url,omitempty"`
 
+	// FallbackURLs lists mirror endpoints tried in order when the primary endpoint fails
+	// or returns an unusable document. Mirrors are fetched without the credentials and
+	// request signature of the primary endpoint.
+	// +optional
+	FallbackURLs []string `json:"fallbackURLs,omitempty"`
+
 	// Services filters which AWS services to include. If empty, all services are included.
 	// Examples: "AMAZON", "EC2", "S3", "CLOUDFRONT"
 	// +optional
 	Services []string `json:"services,omitempty"`
 
-	// Regions filters which AWS regions to include. If empty, all regions are included.
+	// Regions filters which AWS regions to include. If empty, all regions except those
+	// excluded by the operator's --aws-exclude-regions flag are included.
 	// Examples: "us-east-1", "eu-west-1", "GLOBAL"
 	// +optional
 	Regions []string `json:"regions,omitempty"`
@@ -161,6 +613,42 @@ type AWSProviderSpec struct {
 	// NetworkBorderGroups filters by network border group. If empty, all groups are included.
 	// +optional
 	NetworkBorderGroups []string `json:"networkBorderGroups,omitempty"`
+
+	// MaxFeedAge fails the fetch when the feed's createDate (or syncToken) is older than
+	// this, guarding against stale or incorrectly cached copies. Zero disables the check.
+	// +optional
+	MaxFeedAge metav1.Duration `json:"maxFeedAge,omitempty"`
+
+	// InsecureSkipTLSVerify skips certificate verification of URL and FallbackURLs, for an
+	// ip-ranges.json mirror without a trusted certificate.
+	// +optional
+	InsecureSkipTLSVerify *bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// CASecretRef selects a Secret key holding a PEM bundle of the certificate authorities
+	// trusted to sign the endpoint's certificate, in place of the system roots. Cannot be
+	// combined with CAConfigMapRef.
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// CAConfigMapRef selects a ConfigMap key holding the PEM bundle, like CASecretRef, for
+	// CA certificates that need not be secret.
+	// +optional
+	CAConfigMapRef *corev1.ConfigMapKeySelector `json:"caConfigMapRef,omitempty"`
+}
+
+// AzureProviderSpec configures fetching of the Azure service tags document.
+type AzureProviderSpec struct {
+	// URL of the service tags JSON document. Microsoft publishes it under a dated URL that
+	// changes with every weekly release, so there is no built-in default; the provider
+	// fails unless this or the operator-wide Azure endpoint is set.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// ServiceTags selects the entries, by name, whose address prefixes are included.
+	// If empty, all entries are included.
+	// Examples: "AzureFrontDoor.Backend", "AzureCloud.westeurope"
+	// +optional
+	ServiceTags []string `json:"serviceTags,omitempty"`
 }
 
 // GitHubProviderSpec configures GitHub IP range fetching.
@@ -170,10 +658,45 @@ type GitHubProviderSpec struct {
 	// +optional
 	URL string `json:"url,omitempty"`
 
+	// FallbackURLs lists mirror endpoints tried in order when the primary endpoint fails
+	// or returns an unusable document. Mirrors are fetched without the credentials and
+	// request signature of the primary endpoint.
+	// +optional
+	FallbackURLs []string `json:"fallbackURLs,omitempty"`
+
 	// Roles selects which GitHub service roles to include. If empty, only "hooks" is used.
 	// Available roles: hooks, web, api, git, pages, importer, actions, dependabot
 	// +optional
 	Roles []string `json:"roles,omitempty"`
+
+	// TokenSecretRef selects a Secret key holding a GitHub token. When set, requests
+	// carry "Authorization: Bearer <token>", which raises the API rate limit.
+	// +optional
+	TokenSecretRef *corev1.SecretKeySelector `json:"tokenSecretRef,omitempty"`
+
+	// InsecureSkipTLSVerify skips certificate verification of URL and FallbackURLs, e.g. for
+	// the meta endpoint of a GitHub Enterprise Server with a self-signed certificate.
+	// +optional
+	InsecureSkipTLSVerify *bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// CASecretRef selects a Secret key holding a PEM bundle of the certificate authorities
+	// trusted to sign the endpoint's certificate, in place of the system roots. Cannot be
+	// combined with CAConfigMapRef.
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// CAConfigMapRef selects a ConfigMap key holding the PEM bundle, like CASecretRef, for
+	// CA certificates that need not be secret.
+	// +optional
+	CAConfigMapRef *corev1.ConfigMapKeySelector `json:"caConfigMapRef,omitempty"`
+}
+
+// CloudflareProviderSpec configures Cloudflare IP range fetching.
+type CloudflareProviderSpec struct {
+	// URL overrides the default Cloudflare IP ranges API endpoint.
+	// Default: https://api.cloudflare.com/client/v4/ips
+	// +optional
+	URL string `json:"url,omitempty"`
 }
 
 // JSONFilterSpec defines filtering conditions for JSON array elements.
@@ -201,6 +724,26 @@ type HTTPHeaderSecretRef struct {
 
 	// SecretKeyRef identifies the Secret key that contains the header value.
 	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+
+	// ValuePrefix is prepended to the secret value, e.g. "Bearer " to build
+	// an Authorization header from a bare token.
+	// +optional
+	ValuePrefix string `json:"valuePrefix,omitempty"`
+}
+
+// BasicAuthSecretRef names a Secret holding HTTP basic auth credentials, such as a Secret
+// of type kubernetes.io/basic-auth.
+type BasicAuthSecretRef struct {
+	// Name is the name of the Secret in the namespace of the BotNetworkPolicy.
+	Name string `json:"name"`
+
+	// UsernameKey is the Secret key holding the username. Defaults to username.
+	// +optional
+	UsernameKey string `json:"usernameKey,omitempty"`
+
+	// PasswordKey is the Secret key holding the password. Defaults to password.
+	// +optional
+	PasswordKey string `json:"passwordKey,omitempty"`
 }
 
 // BotNetworkPolicyStatus defines the observed state of BotNetworkPolicy.
@@ -209,12 +752,119 @@ type BotNetworkPolicyStatus struct {
 	// +optional
 	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
 
+	// LastChange records the CIDRs added and removed by the most recent change to the
+	// generated NetworkPolicies.
+	// +optional
+	LastChange *PolicyChange `json:"lastChange,omitempty"`
+
+	// PinnedSince records when the bot.networking.dev/pin annotation began holding the
+	// applied CIDRs. It is cleared once the annotation is removed.
+	// +optional
+	PinnedSince *metav1.Time `json:"pinnedSince,omitempty"`
+
+	// PlannedCIDRCount is the number of distinct CIDRs the generated NetworkPolicies would
+	// allow. It is only set while spec.dryRun is enabled.
+	// +optional
+	PlannedCIDRCount int `json:"plannedCidrCount,omitempty"`
+
 	// ProviderCount records how many providers were processed successfully.
 	// +optional
 	ProviderCount int `json:"providerCount,omitempty"`
+
+	// ProviderStatuses records the outcome of the last fetch for each provider.
+	// +optional
+	ProviderStatuses []ProviderStatus `json:"providerStatuses,omitempty"`
+
+	// StableReconciles counts consecutive syncs that neither created, updated nor deleted a
+	// generated NetworkPolicy, as a gauge of feed stability. It resets on any change.
+	// +optional
+	StableReconciles int `json:"stableReconciles,omitempty"`
+
+	// Conditions describe the latest observations of the resource's state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// PolicyChange summarizes how a change altered the CIDRs of the generated NetworkPolicies.
+// Long lists are truncated; the counts always hold the full totals.
+type PolicyChange struct {
+	// Time is when the change was applied.
+	Time metav1.Time `json:"time"`
+
+	// Added lists CIDRs that were not allowed before the change, in sorted order.
+	// +optional
+	Added []string `json:"added,omitempty"`
+
+	// AddedCount is the number of added CIDRs, including any truncated from Added.
+	// +optional
+	AddedCount int `json:"addedCount,omitempty"`
+
+	// Removed lists CIDRs that are no longer allowed after the change, in sorted order.
+	// +optional
+	Removed []string `json:"removed,omitempty"`
+
+	// RemovedCount is the number of removed CIDRs, including any truncated from Removed.
+	// +optional
+	RemovedCount int `json:"removedCount,omitempty"`
+}
+
+// ProviderStatus records the outcome of the last fetch for a single provider.
+type ProviderStatus struct {
+	// Name is the provider type.
+	Name string `json:"name"`
+
+	// DisplayName is the provider's configured display name, if any.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// CIDRCount is the number of CIDRs the provider returned.
+	CIDRCount int `json:"cidrCount"`
+
+	// LastError holds the error of the last fetch, if it failed.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// FeedVersion is the feed version read from the provider's version comment, if configured.
+	// +optional
+	FeedVersion string `json:"feedVersion,omitempty"`
+
+	// DataTimestamp is when the provider's data was produced, when the source reports it,
+	// e.g. the AWS feed's createDate or an HTTP Last-Modified header.
+	// +optional
+	DataTimestamp *metav1.Time `json:"dataTimestamp,omitempty"`
+}
+
+const (
+	// ConditionReady indicates that providers have been synchronised at least once and
+	// the generated NetworkPolicy has been applied.
+	ConditionReady = "Ready"
+
+	// ConditionProvidersHealthy is False when any provider failed in the latest sync; its
+	// message names the failed providers and their errors.
+	ConditionProvidersHealthy = "ProvidersHealthy"
+
+	// ConditionBaselineViolation is True when the generated rules omit CIDRs present in the
+	// referenced baseline NetworkPolicy.
+	ConditionBaselineViolation = "BaselineViolation"
+
+	// ConditionNoSources is True when the resource declares no providers and no custom
+	// CIDRs, so the generated NetworkPolicy denies all traffic it selects.
+	ConditionNoSources = "NoSources"
+
+	// ConditionOverlappingSelectors is True when another BotNetworkPolicy in the namespace
+	// selects some of the same pods. Only maintained when the controller runs with
+	// --warn-overlapping-selectors.
+	ConditionOverlappingSelectors = "OverlappingSelectors"
+
+	// ConditionCIDRsTruncated is True when the collected CIDRs exceeded spec.maxCidrs and
+	// some were left out of the generated rules. Only maintained when maxCidrs is set.
+	ConditionCIDRsTruncated = "CIDRsTruncated"
+)
+
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 
 // BotNetworkPolicy is the Schema for the botnetworkpolicies API.
 type BotNetworkPolicy struct {
@@ -274,6 +924,18 @@ func (in *BotNetworkPolicySpec) DeepCopyInto(out *BotNetworkPolicySpec) {
 		out.NamespaceSelector = new(metav1.LabelSelector)
 		in.NamespaceSelector.DeepCopyInto(out.NamespaceSelector)
 	}
+	if in.TargetAllPods != nil {
+		out.TargetAllPods = new(bool)
+		*out.TargetAllPods = *in.TargetAllPods
+	}
+	if in.PeerPodSelector != nil {
+		out.PeerPodSelector = new(metav1.LabelSelector)
+		in.PeerPodSelector.DeepCopyInto(out.PeerPodSelector)
+	}
+	if in.EgressPodNamespaceSelector != nil {
+		out.EgressPodNamespaceSelector = new(metav1.LabelSelector)
+		in.EgressPodNamespaceSelector.DeepCopyInto(out.EgressPodNamespaceSelector)
+	}
 	if in.PolicyTypes != nil {
 		out.PolicyTypes = append([]networkingv1.PolicyType{}, in.PolicyTypes...)
 	}
@@ -285,15 +947,56 @@ func (in *BotNetworkPolicySpec) DeepCopyInto(out *BotNetworkPolicySpec) {
 		out.Egress = new(bool)
 		*out.Egress = *in.Egress
 	}
+	if in.Ports != nil {
+		out.Ports = make([]networkingv1.NetworkPolicyPort, len(in.Ports))
+		for i := range in.Ports {
+			in.Ports[i].DeepCopyInto(&out.Ports[i])
+		}
+	}
 	if in.Providers != nil {
 		out.Providers = make([]ProviderSpec, len(in.Providers))
 		for i := range in.Providers {
 			in.Providers[i].DeepCopyInto(&out.Providers[i])
 		}
 	}
+	if in.IngressProviders != nil {
+		out.IngressProviders = make([]ProviderSpec, len(in.IngressProviders))
+		for i := range in.IngressProviders {
+			in.IngressProviders[i].DeepCopyInto(&out.IngressProviders[i])
+		}
+	}
+	if in.EgressProviders != nil {
+		out.EgressProviders = make([]ProviderSpec, len(in.EgressProviders))
+		for i := range in.EgressProviders {
+			in.EgressProviders[i].DeepCopyInto(&out.EgressProviders[i])
+		}
+	}
 	if in.CustomCIDRs != nil {
 		out.CustomCIDRs = append([]string{}, in.CustomCIDRs...)
 	}
+	if in.ExceptCIDRs != nil {
+		out.ExceptCIDRs = append([]string{}, in.ExceptCIDRs...)
+	}
+	if in.BaselinePolicyRef != nil {
+		out.BaselinePolicyRef = new(BaselinePolicyReference)
+		*out.BaselinePolicyRef = *in.BaselinePolicyRef
+	}
+	if in.MaintenanceWindow != nil {
+		out.MaintenanceWindow = new(MaintenanceWindowSpec)
+		*out.MaintenanceWindow = *in.MaintenanceWindow
+	}
+	if in.AuditMode != nil {
+		out.AuditMode = new(bool)
+		*out.AuditMode = *in.AuditMode
+	}
+	if in.AggregateCIDRs != nil {
+		out.AggregateCIDRs = new(bool)
+		*out.AggregateCIDRs = *in.AggregateCIDRs
+	}
+	if in.DryRun != nil {
+		out.DryRun = new(bool)
+		*out.DryRun = *in.DryRun
+	}
 }
 
 // DeepCopyInto copies the receiver.
@@ -301,7 +1004,11 @@ func (in *ProviderSpec) DeepCopyInto(out *ProviderSpec) {
 	*out = *in
 	if in.ConfigMap != nil {
 		out.ConfigMap = new(ConfigMapProviderSpec)
-		*out.ConfigMap = *in.ConfigMap
+		in.ConfigMap.DeepCopyInto(out.ConfigMap)
+	}
+	if in.ConfigRef != nil {
+		out.ConfigRef = new(ProviderConfigRef)
+		*out.ConfigRef = *in.ConfigRef
 	}
 	if in.JSONEndpoint != nil {
 		out.JSONEndpoint = new(JSONEndpointProviderSpec)
@@ -315,10 +1022,68 @@ func (in *ProviderSpec) DeepCopyInto(out *ProviderSpec) {
 		out.AWS = new(AWSProviderSpec)
 		in.AWS.DeepCopyInto(out.AWS)
 	}
+	if in.Azure != nil {
+		out.Azure = new(AzureProviderSpec)
+		in.Azure.DeepCopyInto(out.Azure)
+	}
 	if in.GitHub != nil {
 		out.GitHub = new(GitHubProviderSpec)
 		in.GitHub.DeepCopyInto(out.GitHub)
 	}
+	if in.Cloudflare != nil {
+		out.Cloudflare = new(CloudflareProviderSpec)
+		in.Cloudflare.DeepCopyInto(out.Cloudflare)
+	}
+	if in.Directory != nil {
+		out.Directory = new(DirectoryProviderSpec)
+		in.Directory.DeepCopyInto(out.Directory)
+	}
+	if in.Redis != nil {
+		out.Redis = new(RedisProviderSpec)
+		*out.Redis = *in.Redis
+	}
+	if in.Scrape != nil {
+		out.Scrape = new(ScrapeProviderSpec)
+		*out.Scrape = *in.Scrape
+	}
+	if in.AllowEmpty != nil {
+		out.AllowEmpty = new(bool)
+		*out.AllowEmpty = *in.AllowEmpty
+	}
+	if in.AllowedSupernets != nil {
+		out.AllowedSupernets = append([]string{}, in.AllowedSupernets...)
+	}
+	if in.HMACSigning != nil {
+		out.HMACSigning = new(HMACSigningSpec)
+		in.HMACSigning.DeepCopyInto(out.HMACSigning)
+	}
+	if in.ProxyCredentialsSecretRef != nil {
+		out.ProxyCredentialsSecretRef = new(BasicAuthSecretRef)
+		*out.ProxyCredentialsSecretRef = *in.ProxyCredentialsSecretRef
+	}
+}
+
+// DeepCopyInto copies the receiver.
+func (in *HMACSigningSpec) DeepCopyInto(out *HMACSigningSpec) {
+	*out = *in
+	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+}
+
+// DeepCopyInto copies the receiver.
+func (in *DirectoryProviderSpec) DeepCopyInto(out *DirectoryProviderSpec) {
+	*out = *in
+	if in.Headers != nil {
+		out.Headers = make(map[string]string, len(in.Headers))
+		for k, v := range in.Headers {
+			out.Headers[k] = v
+		}
+	}
+	if in.HeaderSecretRefs != nil {
+		out.HeaderSecretRefs = make([]HTTPHeaderSecretRef, len(in.HeaderSecretRefs))
+		for i := range in.HeaderSecretRefs {
+			in.HeaderSecretRefs[i].DeepCopyInto(&out.HeaderSecretRefs[i])
+		}
+	}
 }
 
 // DeepCopyInto copies the receiver.
@@ -336,23 +1101,68 @@ func (in *JSONEndpointProviderSpec) DeepCopyInto(out *JSONEndpointProviderSpec)
 			in.HeaderSecretRefs[i].DeepCopyInto(&out.HeaderSecretRefs[i])
 		}
 	}
+	if in.BasicAuthSecretRef != nil {
+		out.BasicAuthSecretRef = new(BasicAuthSecretRef)
+		*out.BasicAuthSecretRef = *in.BasicAuthSecretRef
+	}
+	if in.BearerTokenSecretRef != nil {
+		out.BearerTokenSecretRef = new(corev1.SecretKeySelector)
+		in.BearerTokenSecretRef.DeepCopyInto(out.BearerTokenSecretRef)
+	}
 	if in.Filter != nil {
 		out.Filter = new(JSONFilterSpec)
 		in.Filter.DeepCopyInto(out.Filter)
 	}
+	if in.InsecureSkipTLSVerify != nil {
+		out.InsecureSkipTLSVerify = new(bool)
+		*out.InsecureSkipTLSVerify = *in.InsecureSkipTLSVerify
+	}
+	if in.CASecretRef != nil {
+		out.CASecretRef = new(corev1.SecretKeySelector)
+		in.CASecretRef.DeepCopyInto(out.CASecretRef)
+	}
+	if in.CAConfigMapRef != nil {
+		out.CAConfigMapRef = new(corev1.ConfigMapKeySelector)
+		in.CAConfigMapRef.DeepCopyInto(out.CAConfigMapRef)
+	}
+	if in.TLSClientSecretRef != nil {
+		out.TLSClientSecretRef = new(corev1.LocalObjectReference)
+		*out.TLSClientSecretRef = *in.TLSClientSecretRef
+	}
+	if in.MetadataFields != nil {
+		out.MetadataFields = append([]string{}, in.MetadataFields...)
+	}
 }
 
 // DeepCopyInto copies the receiver.
 func (in *GoogleProviderSpec) DeepCopyInto(out *GoogleProviderSpec) {
 	*out = *in
+	if in.FallbackURLs != nil {
+		out.FallbackURLs = append([]string{}, in.FallbackURLs...)
+	}
 	if in.Scope != nil {
 		out.Scope = append([]string{}, in.Scope...)
 	}
+	if in.InsecureSkipTLSVerify != nil {
+		out.InsecureSkipTLSVerify = new(bool)
+		*out.InsecureSkipTLSVerify = *in.InsecureSkipTLSVerify
+	}
+	if in.CASecretRef != nil {
+		out.CASecretRef = new(corev1.SecretKeySelector)
+		in.CASecretRef.DeepCopyInto(out.CASecretRef)
+	}
+	if in.CAConfigMapRef != nil {
+		out.CAConfigMapRef = new(corev1.ConfigMapKeySelector)
+		in.CAConfigMapRef.DeepCopyInto(out.CAConfigMapRef)
+	}
 }
 
 // DeepCopyInto copies the receiver.
 func (in *AWSProviderSpec) DeepCopyInto(out *AWSProviderSpec) {
 	*out = *in
+	if in.FallbackURLs != nil {
+		out.FallbackURLs = append([]string{}, in.FallbackURLs...)
+	}
 	if in.Services != nil {
 		out.Services = append([]string{}, in.Services...)
 	}
@@ -362,14 +1172,53 @@ func (in *AWSProviderSpec) DeepCopyInto(out *AWSProviderSpec) {
 	if in.NetworkBorderGroups != nil {
 		out.NetworkBorderGroups = append([]string{}, in.NetworkBorderGroups...)
 	}
+	if in.InsecureSkipTLSVerify != nil {
+		out.InsecureSkipTLSVerify = new(bool)
+		*out.InsecureSkipTLSVerify = *in.InsecureSkipTLSVerify
+	}
+	if in.CASecretRef != nil {
+		out.CASecretRef = new(corev1.SecretKeySelector)
+		in.CASecretRef.DeepCopyInto(out.CASecretRef)
+	}
+	if in.CAConfigMapRef != nil {
+		out.CAConfigMapRef = new(corev1.ConfigMapKeySelector)
+		in.CAConfigMapRef.DeepCopyInto(out.CAConfigMapRef)
+	}
+}
+
+// DeepCopyInto copies the receiver.
+func (in *AzureProviderSpec) DeepCopyInto(out *AzureProviderSpec) {
+	*out = *in
+	if in.ServiceTags != nil {
+		out.ServiceTags = append([]string{}, in.ServiceTags...)
+	}
 }
 
 // DeepCopyInto copies the receiver.
 func (in *GitHubProviderSpec) DeepCopyInto(out *GitHubProviderSpec) {
 	*out = *in
+	if in.FallbackURLs != nil {
+		out.FallbackURLs = append([]string{}, in.FallbackURLs...)
+	}
 	if in.Roles != nil {
 		out.Roles = append([]string{}, in.Roles...)
 	}
+	if in.TokenSecretRef != nil {
+		out.TokenSecretRef = new(corev1.SecretKeySelector)
+		in.TokenSecretRef.DeepCopyInto(out.TokenSecretRef)
+	}
+	if in.InsecureSkipTLSVerify != nil {
+		out.InsecureSkipTLSVerify = new(bool)
+		*out.InsecureSkipTLSVerify = *in.InsecureSkipTLSVerify
+	}
+	if in.CASecretRef != nil {
+		out.CASecretRef = new(corev1.SecretKeySelector)
+		in.CASecretRef.DeepCopyInto(out.CASecretRef)
+	}
+	if in.CAConfigMapRef != nil {
+		out.CAConfigMapRef = new(corev1.ConfigMapKeySelector)
+		in.CAConfigMapRef.DeepCopyInto(out.CAConfigMapRef)
+	}
 }
 
 // DeepCopyInto copies the receiver.
@@ -403,6 +1252,24 @@ func (in *BotNetworkPolicyStatus) DeepCopyInto(out *BotNetworkPolicyStatus) {
 	if in.LastSyncTime != nil {
 		out.LastSyncTime = in.LastSyncTime.DeepCopy()
 	}
+	if in.LastChange != nil {
+		out.LastChange = in.LastChange.DeepCopy()
+	}
+	if in.PinnedSince != nil {
+		out.PinnedSince = in.PinnedSince.DeepCopy()
+	}
+	if in.ProviderStatuses != nil {
+		out.ProviderStatuses = make([]ProviderStatus, len(in.ProviderStatuses))
+		for i := range in.ProviderStatuses {
+			in.ProviderStatuses[i].DeepCopyInto(&out.ProviderStatuses[i])
+		}
+	}
+	if in.Conditions != nil {
+		out.Conditions = make([]metav1.Condition, len(in.Conditions))
+		for i := range in.Conditions {
+			in.Conditions[i].DeepCopyInto(&out.Conditions[i])
+		}
+	}
 }
 
 // DeepCopyObject implements runtime.Object.
@@ -453,6 +1320,140 @@ func (s *BotNetworkPolicySpec) EgressEnabled() bool {
 	return *s.Egress
 }
 
+// IngressProviderSpecs returns the providers used for ingress rules.
+func (s *BotNetworkPolicySpec) IngressProviderSpecs() []ProviderSpec {
+	if len(s.IngressProviders) > 0 {
+		return s.IngressProviders
+	}
+	return s.Providers
+}
+
+// EgressProviderSpecs returns the providers used for egress rules.
+func (s *BotNetworkPolicySpec) EgressProviderSpecs() []ProviderSpec {
+	if len(s.EgressProviders) > 0 {
+		return s.EgressProviders
+	}
+	return s.Providers
+}
+
+// Label returns the name used to identify the provider in events, metrics and status.
+func (p *ProviderSpec) Label() string {
+	if name := strings.TrimSpace(p.DisplayName); name != "" {
+		return name
+	}
+	return p.Name
+}
+
+// InsecureSkipTLSVerifyEnabled reports whether the provider skips TLS certificate
+// verification.
+func (p *ProviderSpec) InsecureSkipTLSVerifyEnabled() bool {
+	var insecure *bool
+	switch {
+	case p.JSONEndpoint != nil:
+		insecure = p.JSONEndpoint.InsecureSkipTLSVerify
+	case p.Google != nil:
+		insecure = p.Google.InsecureSkipTLSVerify
+	case p.AWS != nil:
+		insecure = p.AWS.InsecureSkipTLSVerify
+	case p.GitHub != nil:
+		insecure = p.GitHub.InsecureSkipTLSVerify
+	}
+	return insecure != nil && *insecure
+}
+
+// CABundleRefs returns the caSecretRef and caConfigMapRef of the provider, if any.
+func (p *ProviderSpec) CABundleRefs() (*corev1.SecretKeySelector, *corev1.ConfigMapKeySelector) {
+	switch {
+	case p.JSONEndpoint != nil:
+		return p.JSONEndpoint.CASecretRef, p.JSONEndpoint.CAConfigMapRef
+	case p.Google != nil:
+		return p.Google.CASecretRef, p.Google.CAConfigMapRef
+	case p.AWS != nil:
+		return p.AWS.CASecretRef, p.AWS.CAConfigMapRef
+	case p.GitHub != nil:
+		return p.GitHub.CASecretRef, p.GitHub.CAConfigMapRef
+	}
+	return nil, nil
+}
+
+// AuditModeEnabled reports whether the generated NetworkPolicies run in log-only mode.
+func (s *BotNetworkPolicySpec) AuditModeEnabled() bool {
+	return s.AuditMode != nil && *s.AuditMode
+}
+
+// DryRunEnabled reports whether NetworkPolicy changes are only reported, not applied.
+func (s *BotNetworkPolicySpec) DryRunEnabled() bool {
+	return s.DryRun != nil && *s.DryRun
+}
+
+// AggregateCIDRsEnabled reports whether the collected CIDRs are collapsed before use.
+func (s *BotNetworkPolicySpec) AggregateCIDRsEnabled() bool {
+	return s.AggregateCIDRs != nil && *s.AggregateCIDRs
+}
+
+// validatePodSelector requires a non-empty pod selector unless TargetAllPods opts in to
+// selecting every pod, and rejects setting both.
+func (s *BotNetworkPolicySpec) validatePodSelector() error {
+	empty := s.PodSelector == nil || (len(s.PodSelector.MatchLabels) == 0 && len(s.PodSelector.MatchExpressions) == 0)
+	allPods := s.TargetAllPods != nil && *s.TargetAllPods
+	switch {
+	case empty && !allPods:
+		return fmt.Errorf("podSelector is required; set targetAllPods to true to select every pod in the namespace")
+	case !empty && allPods:
+		return fmt.Errorf("targetAllPods cannot be combined with a non-empty podSelector")
+	}
+	return nil
+}
+
+// validatePolicyTypes rejects policy type settings that would silently produce a policy
+// other than the one asked for. Kubernetes defaults an empty policyTypes to Ingress, so
+// neither an empty list nor disabling both directions yields a policy that restricts
+// nothing; both would deny all ingress to the selected pods instead.
+func (s *BotNetworkPolicySpec) validatePolicyTypes() error {
+	if s.PolicyTypes != nil && len(s.PolicyTypes) == 0 {
+		return fmt.Errorf("policyTypes must not be empty; a NetworkPolicy cannot restrict nothing, so omit policyTypes or list Ingress and/or Egress")
+	}
+	seen := make(map[networkingv1.PolicyType]bool, len(s.PolicyTypes))
+	for _, policyType := range s.PolicyTypes {
+		if policyType != networkingv1.PolicyTypeIngress && policyType != networkingv1.PolicyTypeEgress {
+			return fmt.Errorf("policyTypes entry %q must be Ingress or Egress", policyType)
+		}
+		if seen[policyType] {
+			return fmt.Errorf("policyTypes lists %s more than once", policyType)
+		}
+		seen[policyType] = true
+	}
+	if len(s.PolicyTypes) == 0 && !s.IngressEnabled() && !s.EgressEnabled() {
+		return fmt.Errorf("ingress and egress are both disabled, which would deny all ingress to the selected pods; enable one of them or set policyTypes")
+	}
+	return nil
+}
+
+// validateExceptCIDRs rejects exceptCidrs entries that are not CIDRs.
+func (s *BotNetworkPolicySpec) validateExceptCIDRs() error {
+	for _, cidr := range s.ExceptCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("exceptCidrs entry %q is not a CIDR", cidr)
+		}
+	}
+	return nil
+}
+
+// validateIPFamily accepts an empty family or one of IPFamilyIPv4 and IPFamilyIPv6.
+func validateIPFamily(family string) error {
+	switch family {
+	case "", IPFamilyIPv4, IPFamilyIPv6:
+		return nil
+	}
+	return fmt.Errorf("ipFamily must be %q or %q, got %q", IPFamilyIPv4, IPFamilyIPv6, family)
+}
+
+// HasSources reports whether any provider, custom CIDR or egress pod selector is declared.
+func (s *BotNetworkPolicySpec) HasSources() bool {
+	return len(s.Providers) > 0 || len(s.IngressProviders) > 0 || len(s.EgressProviders) > 0 || len(s.CustomCIDRs) > 0 ||
+		(s.EgressPodNamespaceSelector != nil && s.EgressEnabled())
+}
+
 // NetworkPolicyName returns the derived NetworkPolicy name.
 func (b *BotNetworkPolicy) NetworkPolicyName() string {
 	if name := strings.TrimSpace(b.Annotations["bot.networking.dev/networkpolicy-name"]); name != "" {
@@ -461,10 +1462,105 @@ func (b *BotNetworkPolicy) NetworkPolicyName() string {
 	return b.Name + "-allow-bots"
 }
 
+// validateProxy checks proxyURL and proxyCredentialsSecretRef.
+func (p *ProviderSpec) validateProxy() error {
+	if strings.EqualFold(p.Name, "configmap") || strings.EqualFold(p.Name, "redis") {
+		return fmt.Errorf("%s provider does not support proxyURL", p.Name)
+	}
+	if p.ProxyURL == "" {
+		return fmt.Errorf("%s proxyCredentialsSecretRef requires proxyURL", p.Name)
+	}
+	proxy, err := url.Parse(p.ProxyURL)
+	if err != nil {
+		return fmt.Errorf("%s provider has invalid proxyURL: %w", p.Name, err)
+	}
+	switch {
+	case proxy.Scheme != "http" && proxy.Scheme != "https" && proxy.Scheme != "socks5":
+		return fmt.Errorf("%s provider proxyURL %q must use the http, https or socks5 scheme", p.Name, p.ProxyURL)
+	case proxy.Host == "":
+		return fmt.Errorf("%s provider proxyURL %q has no host", p.Name, p.ProxyURL)
+	case proxy.User != nil:
+		return fmt.Errorf("%s provider proxyURL must not embed credentials; use proxyCredentialsSecretRef", p.Name)
+	}
+	if ref := p.ProxyCredentialsSecretRef; ref != nil && ref.Name == "" {
+		return fmt.Errorf("%s proxyCredentialsSecretRef requires secret name", p.Name)
+	}
+	return nil
+}
+
+// validateCABundle checks the caSecretRef and caConfigMapRef of the named provider.
+func validateCABundle(provider string, secret *corev1.SecretKeySelector, configMap *corev1.ConfigMapKeySelector) error {
+	if secret != nil && configMap != nil {
+		return fmt.Errorf("%s caSecretRef and caConfigMapRef are mutually exclusive", provider)
+	}
+	if secret != nil && (secret.Name == "" || secret.Key == "") {
+		return fmt.Errorf("%s caSecretRef requires secret name and key", provider)
+	}
+	if configMap != nil && (configMap.Name == "" || configMap.Key == "") {
+		return fmt.Errorf("%s caConfigMapRef requires configMap name and key", provider)
+	}
+	return nil
+}
+
 // Validate performs basic validation on provider spec.
 func (p *ProviderSpec) Validate() error {
+	if p.HMACSigning != nil {
+		if strings.EqualFold(p.Name, "configmap") || strings.EqualFold(p.Name, "redis") {
+			return fmt.Errorf("%s provider does not support hmacSigning", p.Name)
+		}
+		if p.HMACSigning.SecretKeyRef.Name == "" || p.HMACSigning.SecretKeyRef.Key == "" {
+			return fmt.Errorf("%s hmacSigning requires secret name and key", p.Name)
+		}
+	}
+	for _, supernet := range p.AllowedSupernets {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(supernet)); err != nil {
+			return fmt.Errorf("%s provider has invalid allowedSupernets entry %q", p.Name, supernet)
+		}
+	}
+	if p.ProxyURL != "" || p.ProxyCredentialsSecretRef != nil {
+		if err := p.validateProxy(); err != nil {
+			return err
+		}
+	}
+	if p.MaxCIDRs < 0 {
+		return fmt.Errorf("%s provider maxCidrs must not be negative", p.Name)
+	}
+	if p.TimeoutSeconds < 0 {
+		return fmt.Errorf("%s provider timeoutSeconds must not be negative", p.Name)
+	}
+	if err := validateIPFamily(p.IPFamily); err != nil {
+		return fmt.Errorf("%s provider %w", p.Name, err)
+	}
+	if p.ConfigRef != nil {
+		// The provider-specific settings may come from the shared configuration, which is
+		// validated once resolved.
+		if p.ConfigRef.Name == "" {
+			return fmt.Errorf("%s provider configRef requires a name", p.Name)
+		}
+		return nil
+	}
+
 	switch strings.ToLower(p.Name) {
-	case "google", "aws", "github":
+	case "google", "aws", "azure", "github", "cloudflare":
+		if p.AllowEmpty != nil && *p.AllowEmpty {
+			return fmt.Errorf("%s provider does not support allowEmpty", p.Name)
+		}
+		if p.Google != nil && p.Google.Feed != "" && p.Google.Feed != GoogleFeedGoog && p.Google.Feed != GoogleFeedCloud {
+			return fmt.Errorf("google feed must be %q or %q, got %q", GoogleFeedGoog, GoogleFeedCloud, p.Google.Feed)
+		}
+		if p.GitHub != nil && p.GitHub.TokenSecretRef != nil {
+			if p.GitHub.TokenSecretRef.Name == "" || p.GitHub.TokenSecretRef.Key == "" {
+				return fmt.Errorf("github tokenSecretRef requires secret name and key")
+			}
+		}
+		switch {
+		case p.Google != nil:
+			return validateCABundle("google", p.Google.CASecretRef, p.Google.CAConfigMapRef)
+		case p.AWS != nil:
+			return validateCABundle("aws", p.AWS.CASecretRef, p.AWS.CAConfigMapRef)
+		case p.GitHub != nil:
+			return validateCABundle("github", p.GitHub.CASecretRef, p.GitHub.CAConfigMapRef)
+		}
 		return nil
 	case "configmap":
 		if p.ConfigMap == nil {
@@ -473,13 +1569,24 @@ func (p *ProviderSpec) Validate() error {
 		if p.ConfigMap.Name == "" || p.ConfigMap.Key == "" {
 			return fmt.Errorf("configMap provider requires name and key")
 		}
+		if p.ConfigMap.NamespaceSelector != nil {
+			if p.ConfigMap.Namespace != "" {
+				return fmt.Errorf("configMap provider cannot set both namespace and namespaceSelector")
+			}
+			if _, err := metav1.LabelSelectorAsSelector(p.ConfigMap.NamespaceSelector); err != nil {
+				return fmt.Errorf("configMap provider has invalid namespaceSelector: %w", err)
+			}
+		}
 		return nil
 	case "jsonendpoint":
 		if p.JSONEndpoint == nil {
 			return fmt.Errorf("jsonEndpoint provider requires jsonEndpoint configuration")
 		}
-		if p.JSONEndpoint.URL == "" || p.JSONEndpoint.FieldPath == "" {
-			return fmt.Errorf("jsonEndpoint provider requires url and fieldPath")
+		if p.JSONEndpoint.URL == "" {
+			return fmt.Errorf("jsonEndpoint provider requires url")
+		}
+		if p.JSONEndpoint.MinItems < 0 {
+			return fmt.Errorf("jsonEndpoint minItems must not be negative")
 		}
 		for _, headerRef := range p.JSONEndpoint.HeaderSecretRefs {
 			if strings.TrimSpace(headerRef.Name) == "" {
@@ -489,6 +1596,67 @@ func (p *ProviderSpec) Validate() error {
 				return fmt.Errorf("jsonEndpoint headerSecretRefs requires secret name and key")
 			}
 		}
+		if p.JSONEndpoint.BasicAuthSecretRef != nil && p.JSONEndpoint.BearerTokenSecretRef != nil {
+			return fmt.Errorf("jsonEndpoint basicAuthSecretRef and bearerTokenSecretRef are mutually exclusive")
+		}
+		if p.JSONEndpoint.BasicAuthSecretRef != nil && p.JSONEndpoint.BasicAuthSecretRef.Name == "" {
+			return fmt.Errorf("jsonEndpoint basicAuthSecretRef requires secret name")
+		}
+		if ref := p.JSONEndpoint.BearerTokenSecretRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+			return fmt.Errorf("jsonEndpoint bearerTokenSecretRef requires secret name and key")
+		}
+		if p.JSONEndpoint.MaxPages < 0 {
+			return fmt.Errorf("jsonEndpoint maxPages must not be negative")
+		}
+		if ref := p.JSONEndpoint.TLSClientSecretRef; ref != nil && ref.Name == "" {
+			return fmt.Errorf("jsonEndpoint tlsClientSecretRef requires secret name")
+		}
+		return validateCABundle("jsonEndpoint", p.JSONEndpoint.CASecretRef, p.JSONEndpoint.CAConfigMapRef)
+	case "directory":
+		if p.Directory == nil {
+			return fmt.Errorf("directory provider requires directory configuration")
+		}
+		if p.Directory.URL == "" || p.Directory.EntriesPath == "" || p.Directory.Attribute == "" {
+			return fmt.Errorf("directory provider requires url, entriesPath and attribute")
+		}
+		if p.Directory.MaxPages < 0 {
+			return fmt.Errorf("directory maxPages must not be negative")
+		}
+		for _, headerRef := range p.Directory.HeaderSecretRefs {
+			if strings.TrimSpace(headerRef.Name) == "" {
+				return fmt.Errorf("directory headerSecretRefs requires name")
+			}
+			if headerRef.SecretKeyRef.Name == "" || headerRef.SecretKeyRef.Key == "" {
+				return fmt.Errorf("directory headerSecretRefs requires secret name and key")
+			}
+		}
+		return nil
+	case "redis":
+		if p.Redis == nil {
+			return fmt.Errorf("redis provider requires redis configuration")
+		}
+		if p.Redis.Key == "" || p.Redis.ConnectionSecretRef.Name == "" {
+			return fmt.Errorf("redis provider requires key and connectionSecretRef")
+		}
+		if p.Redis.DB < 0 {
+			return fmt.Errorf("redis db must not be negative")
+		}
+		return nil
+	case "scrape":
+		if p.Scrape == nil {
+			return fmt.Errorf("scrape provider requires scrape configuration")
+		}
+		if p.Scrape.URL == "" {
+			return fmt.Errorf("scrape provider requires url")
+		}
+		if p.Scrape.Pattern != "" {
+			if _, err := regexp.Compile(p.Scrape.Pattern); err != nil {
+				return fmt.Errorf("scrape provider has invalid pattern: %w", err)
+			}
+		}
+		if p.Scrape.MaxBodyBytes < 0 {
+			return fmt.Errorf("scrape maxBodyBytes must not be negative")
+		}
 		return nil
 	default:
 		return fmt.Errorf("unsupported provider: %s", p.Name)
@@ -497,8 +1665,30 @@ func (p *ProviderSpec) Validate() error {
 
 // Validate performs validation for the BotNetworkPolicy resource.
 func (b *BotNetworkPolicy) Validate() error {
-	for i := range b.Spec.Providers {
-		if err := b.Spec.Providers[i].Validate(); err != nil {
+	if err := b.Spec.validatePodSelector(); err != nil {
+		return err
+	}
+	if err := b.Spec.validatePolicyTypes(); err != nil {
+		return err
+	}
+	if err := validateIPFamily(b.Spec.IPFamily); err != nil {
+		return err
+	}
+	if err := b.Spec.validateExceptCIDRs(); err != nil {
+		return err
+	}
+	if b.Spec.MaxCIDRs < 0 {
+		return fmt.Errorf("maxCidrs must not be negative")
+	}
+	for _, list := range [][]ProviderSpec{b.Spec.Providers, b.Spec.IngressProviders, b.Spec.EgressProviders} {
+		for i := range list {
+			if err := list[i].Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if b.Spec.MaintenanceWindow != nil {
+		if err := b.Spec.MaintenanceWindow.Validate(); err != nil {
 			return err
 		}
 	}
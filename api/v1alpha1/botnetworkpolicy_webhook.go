@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating admission webhook for BotNetworkPolicy.
+func (b *BotNetworkPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(b).
+		WithValidator(&botNetworkPolicyValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-bot-networking-dev-v1alpha1-botnetworkpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=bot.networking.dev,resources=botnetworkpolicies,verbs=create;update,versions=v1alpha1,name=vbotnetworkpolicy.bot.networking.dev,admissionReviewVersions=v1
+
+// botNetworkPolicyValidator rejects specs that Validate rejects, so users see the error
+// when applying instead of in events. It is stricter than reconciliation in one respect:
+// customCidrs entries that are not CIDRs are rejected, whereas the controller skips them
+// with a warning so that objects admitted before the webhook keep working. For the same
+// reason updates that leave the spec unchanged, such as the controller adding its
+// finalizer, are admitted even when the spec is invalid.
+type botNetworkPolicyValidator struct{}
+
+var _ admission.CustomValidator = &botNetworkPolicyValidator{}
+
+func (v *botNetworkPolicyValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateAdmission(obj)
+}
+
+func (v *botNetworkPolicyValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPolicy, oldOK := oldObj.(*BotNetworkPolicy)
+	newPolicy, newOK := newObj.(*BotNetworkPolicy)
+	if oldOK && newOK && equality.Semantic.DeepEqual(oldPolicy.Spec, newPolicy.Spec) {
+		return nil, nil
+	}
+	return nil, validateAdmission(newObj)
+}
+
+func (v *botNetworkPolicyValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateAdmission(obj runtime.Object) error {
+	policy, ok := obj.(*BotNetworkPolicy)
+	if !ok {
+		return fmt.Errorf("expected a BotNetworkPolicy, got %T", obj)
+	}
+	// Objects being deleted only await finalization; rejecting them would block the
+	// finalizer's removal.
+	if !policy.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	for _, cidr := range policy.Spec.CustomCIDRs {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+			return fmt.Errorf("customCidrs entry %q is not a CIDR", cidr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBotNetworkPolicyValidator(t *testing.T) {
+	valid := func() *BotNetworkPolicy {
+		return &BotNetworkPolicy{Spec: BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Providers:   []ProviderSpec{{Name: "google"}},
+			CustomCIDRs: []string{"192.0.2.0/24", " 2001:db8::/32 "},
+		}}
+	}
+	tests := []struct {
+		name    string
+		mutate  func(*BotNetworkPolicy)
+		wantErr bool
+	}{
+		{name: "valid", mutate: func(*BotNetworkPolicy) {}},
+		{name: "unsupported provider", mutate: func(p *BotNetworkPolicy) { p.Spec.Providers[0].Name = "bing" }, wantErr: true},
+		{name: "configMap without key", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "configMap", ConfigMap: &ConfigMapProviderSpec{Name: "bots"}}}
+		}, wantErr: true},
+		{name: "jsonEndpoint without url", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "jsonEndpoint", JSONEndpoint: &JSONEndpointProviderSpec{FieldPath: "cidrs"}}}
+		}, wantErr: true},
+		{name: "jsonEndpoint without fieldPath", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "jsonEndpoint", JSONEndpoint: &JSONEndpointProviderSpec{URL: "https://example.com"}}}
+		}},
+		{name: "jsonEndpoint with basic and bearer auth", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "jsonEndpoint", JSONEndpoint: &JSONEndpointProviderSpec{
+				URL:                  "https://example.com",
+				BasicAuthSecretRef:   &BasicAuthSecretRef{Name: "creds"},
+				BearerTokenSecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}, Key: "token"},
+			}}}
+		}, wantErr: true},
+		{name: "jsonEndpoint with basic auth", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "jsonEndpoint", JSONEndpoint: &JSONEndpointProviderSpec{
+				URL:                "https://example.com",
+				BasicAuthSecretRef: &BasicAuthSecretRef{Name: "creds"},
+			}}}
+		}},
+		{name: "google with two CA bundles", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "google", Google: &GoogleProviderSpec{
+				CASecretRef:    &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+				CAConfigMapRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+			}}}
+		}, wantErr: true},
+		{name: "jsonEndpoint through a proxy", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "jsonEndpoint", JSONEndpoint: &JSONEndpointProviderSpec{URL: "https://example.com"}, ProxyURL: "http://proxy.corp.example:3128"}}
+		}},
+		{name: "unparsable proxyURL", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "google", ProxyURL: "http://proxy.corp.example:port"}}
+		}, wantErr: true},
+		{name: "proxyURL without scheme", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "google", ProxyURL: "proxy.corp.example:3128"}}
+		}, wantErr: true},
+		{name: "proxy credentials without proxyURL", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "google", ProxyCredentialsSecretRef: &BasicAuthSecretRef{Name: "proxy"}}}
+		}, wantErr: true},
+		{name: "configMap through a proxy", mutate: func(p *BotNetworkPolicy) {
+			p.Spec.Providers = []ProviderSpec{{Name: "configMap", ConfigMap: &ConfigMapProviderSpec{Name: "bots", Key: "cidrs"}, ProxyURL: "http://proxy.corp.example:3128"}}
+		}, wantErr: true},
+		{name: "malformed customCidrs", mutate: func(p *BotNetworkPolicy) { p.Spec.CustomCIDRs = append(p.Spec.CustomCIDRs, "192.0.2.1") }, wantErr: true},
+		{name: "invalid but being deleted", mutate: func(p *BotNetworkPolicy) {
+			now := metav1.Now()
+			p.DeletionTimestamp = &now
+			p.Spec.CustomCIDRs = []string{"not-a-cidr"}
+		}},
+	}
+	validator := &botNetworkPolicyValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := valid()
+			tt.mutate(policy)
+			if _, err := validator.ValidateCreate(context.Background(), policy); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := validator.ValidateUpdate(context.Background(), valid(), policy); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBotNetworkPolicyValidator_MetadataOnlyUpdate(t *testing.T) {
+	// Admitted before the webhook was installed; an empty podSelector is now rejected.
+	admitted := &BotNetworkPolicy{Spec: BotNetworkPolicySpec{
+		PodSelector: &metav1.LabelSelector{},
+		Providers:   []ProviderSpec{{Name: "google"}},
+	}}
+	validator := &botNetworkPolicyValidator{}
+
+	finalized := admitted.DeepCopy()
+	finalized.Finalizers = []string{"bot.networking.dev/finalizer"}
+	if _, err := validator.ValidateUpdate(context.Background(), admitted, finalized); err != nil {
+		t.Errorf("ValidateUpdate() adding a finalizer to an invalid object error = %v, want it admitted", err)
+	}
+
+	changed := finalized.DeepCopy()
+	changed.Spec.Providers = []ProviderSpec{{Name: "aws"}}
+	if _, err := validator.ValidateUpdate(context.Background(), finalized, changed); err == nil {
+		t.Error("ValidateUpdate() changing the spec of an invalid object succeeded, want an error")
+	}
+}
@@ -1,6 +1,11 @@
 package v1alpha1
 
-import "testing"
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 func TestExtractCIDRs(t *testing.T) {
 	payload := "10.0.0.0/24\n10.0.1.0/24, 2001:db8::/32"
@@ -9,3 +14,63 @@ func TestExtractCIDRs(t *testing.T) {
 		t.Fatalf("expected 3 cidrs, got %d", len(cidrs))
 	}
 }
+
+func TestValidate_TargetAllPods(t *testing.T) {
+	yes, no := true, false
+	web := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	tests := []struct {
+		name          string
+		podSelector   *metav1.LabelSelector
+		targetAllPods *bool
+		wantErr       bool
+	}{
+		{name: "selector", podSelector: web},
+		{name: "no selector", wantErr: true},
+		{name: "empty selector", podSelector: &metav1.LabelSelector{}, wantErr: true},
+		{name: "no selector, targetAllPods false", targetAllPods: &no, wantErr: true},
+		{name: "targetAllPods opt-in", targetAllPods: &yes},
+		{name: "targetAllPods with empty selector", podSelector: &metav1.LabelSelector{}, targetAllPods: &yes},
+		{name: "targetAllPods with selector", podSelector: web, targetAllPods: &yes, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &BotNetworkPolicy{Spec: BotNetworkPolicySpec{PodSelector: tt.podSelector, TargetAllPods: tt.targetAllPods}}
+			if err := policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_PolicyTypes(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name        string
+		policyTypes []networkingv1.PolicyType
+		ingress     *bool
+		egress      *bool
+		wantErr     bool
+	}{
+		{name: "derived"},
+		{name: "explicit", policyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}},
+		{name: "explicitly empty", policyTypes: []networkingv1.PolicyType{}, wantErr: true},
+		{name: "unknown type", policyTypes: []networkingv1.PolicyType{"Both"}, wantErr: true},
+		{name: "duplicate type", policyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress, networkingv1.PolicyTypeEgress}, wantErr: true},
+		{name: "both directions disabled", ingress: &no, egress: &no, wantErr: true},
+		{name: "egress only", ingress: &no, egress: &yes},
+		{name: "both disabled with explicit types", policyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, ingress: &no},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &BotNetworkPolicy{Spec: BotNetworkPolicySpec{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				PolicyTypes: tt.policyTypes,
+				Ingress:     tt.ingress,
+				Egress:      tt.egress,
+			}}
+			if err := policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@
 
 package v1alpha1
 
-import ()
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSProviderSpec.
 func (in *AWSProviderSpec) DeepCopy() *AWSProviderSpec {
@@ -16,6 +18,31 @@ func (in *AWSProviderSpec) DeepCopy() *AWSProviderSpec {
 	return out
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureProviderSpec.
+func (in *AzureProviderSpec) DeepCopy() *AzureProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BaselinePolicyReference) DeepCopyInto(out *BaselinePolicyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BaselinePolicyReference.
+func (in *BaselinePolicyReference) DeepCopy() *BaselinePolicyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(BaselinePolicyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BotNetworkPolicySpec.
 func (in *BotNetworkPolicySpec) DeepCopy() *BotNetworkPolicySpec {
 	if in == nil {
@@ -36,9 +63,29 @@ func (in *BotNetworkPolicyStatus) DeepCopy() *BotNetworkPolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudflareProviderSpec) DeepCopyInto(out *CloudflareProviderSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudflareProviderSpec.
+func (in *CloudflareProviderSpec) DeepCopy() *CloudflareProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudflareProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapProviderSpec) DeepCopyInto(out *ConfigMapProviderSpec) {
 	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapProviderSpec.
@@ -51,6 +98,16 @@ func (in *ConfigMapProviderSpec) DeepCopy() *ConfigMapProviderSpec {
 	return out
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectoryProviderSpec.
+func (in *DirectoryProviderSpec) DeepCopy() *DirectoryProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectoryProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldCondition.
 func (in *FieldCondition) DeepCopy() *FieldCondition {
 	if in == nil {
@@ -81,6 +138,16 @@ func (in *GoogleProviderSpec) DeepCopy() *GoogleProviderSpec {
 	return out
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HMACSigningSpec.
+func (in *HMACSigningSpec) DeepCopy() *HMACSigningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HMACSigningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPHeaderSecretRef.
 func (in *HTTPHeaderSecretRef) DeepCopy() *HTTPHeaderSecretRef {
 	if in == nil {
@@ -111,6 +178,63 @@ func (in *JSONFilterSpec) DeepCopy() *JSONFilterSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindowSpec) DeepCopyInto(out *MaintenanceWindowSpec) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowSpec.
+func (in *MaintenanceWindowSpec) DeepCopy() *MaintenanceWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyChange) DeepCopyInto(out *PolicyChange) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Removed != nil {
+		in, out := &in.Removed, &out.Removed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyChange.
+func (in *PolicyChange) DeepCopy() *PolicyChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfigRef) DeepCopyInto(out *ProviderConfigRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigRef.
+func (in *ProviderConfigRef) DeepCopy() *ProviderConfigRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfigRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderSpec.
 func (in *ProviderSpec) DeepCopy() *ProviderSpec {
 	if in == nil {
@@ -120,3 +244,52 @@ func (in *ProviderSpec) DeepCopy() *ProviderSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderStatus) DeepCopyInto(out *ProviderStatus) {
+	*out = *in
+	if in.DataTimestamp != nil {
+		out.DataTimestamp = in.DataTimestamp.DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderStatus.
+func (in *ProviderStatus) DeepCopy() *ProviderStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisProviderSpec) DeepCopyInto(out *RedisProviderSpec) {
+	*out = *in
+	out.ConnectionSecretRef = in.ConnectionSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisProviderSpec.
+func (in *RedisProviderSpec) DeepCopy() *RedisProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScrapeProviderSpec) DeepCopyInto(out *ScrapeProviderSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScrapeProviderSpec.
+func (in *ScrapeProviderSpec) DeepCopy() *ScrapeProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScrapeProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-logr/zapr"
@@ -12,11 +15,15 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/yaml"
 
 	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
 	"github.com/sugaf1204/botnetworkpolicy-operator/pkg/controllers"
+	"github.com/sugaf1204/botnetworkpolicy-operator/pkg/providers"
 )
 
 var (
@@ -30,12 +37,73 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "google-scopes":
+			os.Exit(runGoogleScopes(os.Args[2:]))
+		case "status":
+			os.Exit(runStatus(os.Args[2:]))
+		case "probe":
+			os.Exit(runProbe(os.Args[2:]))
+		}
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var retryAttempts int
+	var retryBaseDelay time.Duration
+	var retryMaxElapsed time.Duration
+	var minTLSVersion string
+	var awsExcludeRegions string
+	var warnEmptySelector bool
+	var warnOverlappingSelectors bool
+	var startupJitter time.Duration
+	var syncJitterFraction float64
+	var finalizerName string
+	var auditAnnotation string
+	var maxProviders int
+	var hostBitsLogLevel int
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var fieldManager string
+	var enableDebugSampling bool
+	var debugLogResponseBytes int
+	var providerCacheTTL time.Duration
+	var providerConcurrency int
+	var enableWebhook bool
+	var webhookPort int
+	var webhookCertDir string
+	var readyzWaitForSync bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.IntVar(&retryAttempts, "provider-retry-attempts", 1, "Maximum attempts per provider request. Values above 1 retry network errors and 5xx responses.")
+	flag.DurationVar(&retryBaseDelay, "provider-retry-base-delay", 500*time.Millisecond, "Initial delay between provider request retries; doubled on each retry.")
+	flag.DurationVar(&retryMaxElapsed, "provider-retry-max-elapsed", time.Minute, "Maximum total time spent retrying a single provider fetch. Zero disables the bound.")
+	flag.DurationVar(&providerCacheTTL, "provider-cache-ttl", providers.DefaultCacheTTL, "How long a successful fetch of a built-in feed (google, aws, azure, github, cloudflare) is reused by identical providers across BotNetworkPolicies and resyncs. Zero disables the cache.")
+	flag.IntVar(&providerConcurrency, "provider-concurrency", providers.DefaultConcurrency, "Maximum providers of one BotNetworkPolicy fetched at the same time. 1 fetches them serially.")
+	flag.StringVar(&minTLSVersion, "provider-min-tls-version", "1.2", "Oldest TLS version accepted by provider requests: 1.0, 1.1, 1.2 or 1.3.")
+	flag.StringVar(&awsExcludeRegions, "aws-exclude-regions", "", "Comma-separated AWS regions dropped from every aws provider that does not list its own regions, e.g. cn-north-1,cn-northwest-1.")
+	flag.BoolVar(&warnEmptySelector, "warn-empty-pod-selector", false, "Emit a warning event when a policy's pod selector matches no pods. Requires pod list permissions.")
+	flag.BoolVar(&warnOverlappingSelectors, "warn-overlapping-selectors", false, "Emit a warning event and set the OverlappingSelectors condition when BotNetworkPolicies in a namespace select the same pods. Requires pod list permissions.")
+	flag.DurationVar(&startupJitter, "startup-jitter", 0, "Maximum random delay before the first provider fetch of each BotNetworkPolicy, spreading load on mass creation or restart, e.g. 10s. Zero, the default, disables it.")
+	flag.Float64Var(&syncJitterFraction, "sync-jitter-fraction", 0, "Randomize each periodic resync, which polls the provider feeds, by up to this fraction of the sync period so replicas and operators sharing an upstream do not poll it at the same time. Zero disables it.")
+	flag.StringVar(&finalizerName, "finalizer-name", controllers.DefaultFinalizerName, "Finalizer added to BotNetworkPolicy objects to clean up generated NetworkPolicies on deletion.")
+	flag.StringVar(&auditAnnotation, "audit-annotation", controllers.DefaultAuditAnnotation, "Annotation set to \"true\" on generated NetworkPolicies of BotNetworkPolicies with auditMode enabled. Set it to the key your CNI reads for log-only policies.")
+	flag.IntVar(&maxProviders, "max-providers", 0, "Maximum number of providers a single BotNetworkPolicy may declare, e.g. 50. Zero, the default, disables the limit.")
+	flag.IntVar(&hostBitsLogLevel, "host-bits-log-level", 1, "Log verbosity at which CIDRs normalized by clearing host bits are reported. 0 logs at info level.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "OTLP/HTTP endpoint URL reconcile traces are exported to, e.g. http://otel-collector:4318. Tracing is disabled when neither this nor OTEL_EXPORTER_OTLP_ENDPOINT is set.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Export traces over plain HTTP instead of HTTPS.")
+	flag.StringVar(&fieldManager, "field-manager", controllers.DefaultFieldManager, "Field manager name used to server-side apply generated NetworkPolicies.")
+	flag.BoolVar(&enableDebugSampling, "debug-enable-sampling", false, "TESTING ONLY: honour the bot.networking.dev/debug-sample-fractions annotation, which drops CIDRs from provider feeds. Never enable in production.")
+	flag.IntVar(&debugLogResponseBytes, "debug-log-response-bytes", 0, "TESTING ONLY: log up to this many bytes of every HTTP provider response body at verbosity 3, with secret-looking values redacted. Bodies may still contain sensitive data. Zero disables it.")
+	flag.BoolVar(&enableWebhook, "enable-webhook", false, "Serve the validating admission webhook that rejects invalid BotNetworkPolicies on create and update. Requires a serving certificate in --webhook-cert-dir.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "Port the admission webhook server listens on.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "", "Directory holding tls.crt and tls.key for the webhook server. Defaults to the controller-runtime default.")
+	flag.BoolVar(&readyzWaitForSync, "readyz-wait-for-sync", false, "Report the leader not ready until every BotNetworkPolicy has applied its NetworkPolicies, or failed validation or apply, once since startup. A BotNetworkPolicy that never synced and whose CIDRs cannot be collected, e.g. during an outage, keeps the leader not ready until collection succeeds. Replicas that are not the leader always report ready.")
 	flag.Parse()
 
 	zapLog, err := zap.NewDevelopment()
@@ -44,6 +112,23 @@ func main() {
 	}
 	ctrl.SetLogger(zapr.NewLogger(zapLog))
 
+	tlsVersion, err := providers.ParseTLSVersion(minTLSVersion)
+	if err != nil {
+		setupLog.Error(err, "invalid --provider-min-tls-version")
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := setupTracing(context.Background(), otlpEndpoint, otlpInsecure)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to flush traces")
+		}
+	}()
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
@@ -51,21 +136,53 @@ func main() {
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "botnetworkpolicy-operator",
 		Cache:                  cache.Options{SyncPeriod: pointerToDuration(10 * time.Minute)},
+		WebhookServer:          webhook.NewServer(webhook.Options{Port: webhookPort, CertDir: webhookCertDir}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if err = (&controllers.BotNetworkPolicyReconciler{
+	reconciler := &controllers.BotNetworkPolicyReconciler{
 		Client:     mgr.GetClient(),
 		Scheme:     mgr.GetScheme(),
 		Recorder:   mgr.GetEventRecorderFor("botnetworkpolicy-controller"),
 		HTTPClient: controllers.DefaultHTTPClient(),
-	}).SetupWithManager(mgr); err != nil {
+		ProviderOptions: []providers.FactoryOption{
+			providers.WithRetry(retryAttempts, retryBaseDelay),
+			providers.WithRetryMaxElapsed(retryMaxElapsed),
+			providers.WithMinTLSVersion(tlsVersion),
+			providers.WithResponseBodyLogging(debugLogResponseBytes),
+			providers.WithAWSExcludedRegions(strings.Split(awsExcludeRegions, ",")...),
+			providers.WithCacheTTL(providerCacheTTL),
+			providers.WithConcurrency(providerConcurrency),
+		},
+		APIReader:                  mgr.GetAPIReader(),
+		WarnOnEmptySelector:        warnEmptySelector,
+		WarnOnOverlappingSelectors: warnOverlappingSelectors,
+		StartupJitter:              startupJitter,
+		SyncJitterFraction:         syncJitterFraction,
+		FinalizerName:              finalizerName,
+		AuditAnnotation:            auditAnnotation,
+		MaxProviders:               maxProviders,
+		HostBitsLogLevel:           hostBitsLogLevel,
+		FieldManager:               fieldManager,
+		EnableDebugSampling:        enableDebugSampling,
+	}
+	if readyzWaitForSync {
+		reconciler.SyncTracker = controllers.NewSyncTracker()
+	}
+	setupLog.Info("effective configuration", reconciler.EffectiveConfig()...)
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BotNetworkPolicy")
 		os.Exit(1)
 	}
+	if enableWebhook {
+		if err = (&botv1alpha1.BotNetworkPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BotNetworkPolicy")
+			os.Exit(1)
+		}
+	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -75,6 +192,12 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if reconciler.SyncTracker != nil {
+		if err := mgr.AddReadyzCheck("synced", reconciler.SyncTracker.Checker(mgr.GetClient(), mgr.Elected())); err != nil {
+			setupLog.Error(err, "unable to set up sync ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -83,6 +206,125 @@ func main() {
 	}
 }
 
+// runExport writes the desired NetworkPolicies for a namespace to a directory without
+// modifying the cluster.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	namespace := fs.String("n", "default", "Namespace whose BotNetworkPolicy objects are exported.")
+	outputDir := fs.String("o", "botnetworkpolicies", "Directory the NetworkPolicy YAML bundle is written to.")
+	_ = fs.Parse(args)
+
+	zapLog, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	ctrl.SetLogger(zapr.NewLogger(zapLog))
+
+	kubeClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		return 1
+	}
+
+	reconciler := &controllers.BotNetworkPolicyReconciler{
+		Client:     kubeClient,
+		Scheme:     scheme,
+		HTTPClient: controllers.DefaultHTTPClient(),
+	}
+	ctx := ctrl.LoggerInto(context.Background(), ctrl.Log.WithName("export"))
+	written, err := reconciler.ExportNetworkPolicies(ctx, *namespace, *outputDir)
+	if err != nil {
+		setupLog.Error(err, "export failed")
+		return 1
+	}
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	return 0
+}
+
+// runStatus prints the conditions, counts and last sync of every BotNetworkPolicy in a
+// namespace, read from the live cluster.
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	namespace := fs.String("n", "default", "Namespace whose BotNetworkPolicy objects are listed.")
+	_ = fs.Parse(args)
+
+	kubeClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create client: %v\n", err)
+		return 1
+	}
+	if err := controllers.WriteStatus(context.Background(), kubeClient, *namespace, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "status failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runProbe fetches every provider of the BotNetworkPolicy in a manifest once and prints a
+// pass/fail table, exiting non-zero when any provider fails. It only reads from the cluster,
+// and only when a kubeconfig is available for providers that need one.
+func runProbe(args []string) int {
+	fs := flag.NewFlagSet("probe", flag.ExitOnError)
+	file := fs.String("f", "", "BotNetworkPolicy manifest whose providers are probed.")
+	_ = fs.Parse(args)
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "probe requires -f <manifest>")
+		return 2
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to read manifest: %v\n", err)
+		return 1
+	}
+	var resource botv1alpha1.BotNetworkPolicy
+	if err := yaml.UnmarshalStrict(data, &resource); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to parse manifest: %v\n", err)
+		return 1
+	}
+
+	var kubeClient client.Client
+	if cfg, err := ctrl.GetConfig(); err == nil {
+		kubeClient, _ = client.New(cfg, client.Options{Scheme: scheme})
+	}
+	factory := providers.NewFactory(kubeClient, controllers.DefaultHTTPClient())
+	failed, err := controllers.ProbeProviders(context.Background(), factory, &resource, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "probe failed: %v\n", err)
+		return 1
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runGoogleScopes prints the scopes and services published in the Google IP ranges feed so
+// provider filters can be configured correctly.
+func runGoogleScopes(args []string) int {
+	fs := flag.NewFlagSet("google-scopes", flag.ExitOnError)
+	url := fs.String("url", "", "Feed URL to inspect. Defaults to the google provider endpoint.")
+	_ = fs.Parse(args)
+
+	factory := providers.NewFactory(nil, controllers.DefaultHTTPClient())
+	summary, err := factory.DescribeGoogleFeed(context.Background(), *url)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read google feed: %v\n", err)
+		return 1
+	}
+	fmt.Println("scopes:")
+	for _, scope := range summary.Scopes {
+		fmt.Printf("  %s\n", scope)
+	}
+	fmt.Println("services:")
+	for _, service := range summary.Services {
+		fmt.Printf("  %s\n", service)
+	}
+	return 0
+}
+
 func pointerToDuration(d time.Duration) *time.Duration {
 	return &d
 }
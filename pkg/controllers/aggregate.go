@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// aggregateCIDRs returns the smallest set of CIDRs covering the same addresses as cidrs:
+// prefixes contained in another are dropped and sibling prefixes are merged into their
+// parent, repeatedly. IPv4 and IPv6 are aggregated separately. The result is sorted like
+// the input of buildNetworkPolicy, so equal inputs always yield equal policies. Entries
+// that are not CIDRs are kept unchanged.
+func aggregateCIDRs(cidrs []string) []string {
+	var v4, v6 []netip.Prefix
+	var result []string
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			result = append(result, cidr)
+			continue
+		}
+		prefix = prefix.Masked()
+		if prefix.Addr().Is4() {
+			v4 = append(v4, prefix)
+		} else {
+			v6 = append(v6, prefix)
+		}
+	}
+	for _, prefix := range append(aggregatePrefixes(v4), aggregatePrefixes(v6)...) {
+		result = append(result, prefix.String())
+	}
+	sort.Strings(result)
+	return result
+}
+
+// aggregatePrefixes aggregates prefixes of a single address family.
+func aggregatePrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	sort.Slice(prefixes, func(i, j int) bool {
+		if c := prefixes[i].Addr().Compare(prefixes[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return prefixes[i].Bits() < prefixes[j].Bits()
+	})
+
+	// Sorted by address with shorter prefixes first, a prefix is either contained in the
+	// last one kept or starts after it. Merging a prefix with its left sibling on the stack
+	// may in turn make the parent a right sibling, so merging repeats.
+	stack := make([]netip.Prefix, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if n := len(stack); n > 0 && stack[n-1].Overlaps(prefix) {
+			continue
+		}
+		for n := len(stack); n > 0; n = len(stack) {
+			parent, ok := siblingsParent(stack[n-1], prefix)
+			if !ok {
+				break
+			}
+			stack = stack[:n-1]
+			prefix = parent
+		}
+		stack = append(stack, prefix)
+	}
+	return stack
+}
+
+// siblingsParent returns the prefix that left and right are the two halves of.
+func siblingsParent(left, right netip.Prefix) (netip.Prefix, bool) {
+	if left.Bits() != right.Bits() || left.Bits() == 0 || left == right {
+		return netip.Prefix{}, false
+	}
+	parent := netip.PrefixFrom(left.Addr(), left.Bits()-1).Masked()
+	if parent.Addr() != left.Addr() || !parent.Contains(right.Addr()) {
+		return netip.Prefix{}, false
+	}
+	return parent, true
+}
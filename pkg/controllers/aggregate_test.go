@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAggregateCIDRs(t *testing.T) {
+	tests := []struct {
+		name  string
+		cidrs []string
+		want  []string
+	}{
+		{name: "empty"},
+		{
+			name:  "contained ranges are dropped",
+			cidrs: []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.3/32", "192.0.2.0/24"},
+			want:  []string{"10.0.0.0/8", "192.0.2.0/24"},
+		},
+		{
+			name:  "adjacent siblings merge repeatedly",
+			cidrs: []string{"192.0.2.0/26", "192.0.2.64/26", "192.0.2.128/25", "198.51.100.0/24"},
+			want:  []string{"192.0.2.0/24", "198.51.100.0/24"},
+		},
+		{
+			name:  "adjacent ranges that are not siblings stay apart",
+			cidrs: []string{"192.0.2.128/25", "192.0.3.0/25"},
+			want:  []string{"192.0.2.128/25", "192.0.3.0/25"},
+		},
+		{
+			name:  "families are aggregated separately",
+			cidrs: []string{"2001:db8::/33", "2001:db8:8000::/33", "2001:db8:1::/48", "0.0.0.0/1", "128.0.0.0/1"},
+			want:  []string{"0.0.0.0/0", "2001:db8::/32"},
+		},
+		{
+			name:  "duplicates and unparsable entries",
+			cidrs: []string{"203.0.113.0/24", "203.0.113.0/24", "not-a-cidr"},
+			want:  []string{"203.0.113.0/24", "not-a-cidr"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := aggregateCIDRs(tt.cidrs)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("aggregateCIDRs(%v) = %v, want %v", tt.cidrs, got, tt.want)
+			}
+			// Aggregation is deterministic regardless of input order.
+			reversed := make([]string, len(tt.cidrs))
+			for i, cidr := range tt.cidrs {
+				reversed[len(tt.cidrs)-1-i] = cidr
+			}
+			if again := aggregateCIDRs(reversed); !slices.Equal(again, got) {
+				t.Errorf("aggregateCIDRs() of reversed input = %v, want %v", again, got)
+			}
+		})
+	}
+}
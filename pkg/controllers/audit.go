@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// DefaultAuditAnnotation is the annotation set to "true" on generated NetworkPolicies of
+// resources with auditMode enabled, unless AuditAnnotation names the key the installed CNI
+// reads for log-only policies.
+const DefaultAuditAnnotation = "bot.networking.dev/audit-mode"
+
+func (r *BotNetworkPolicyReconciler) auditAnnotation() string {
+	if r.AuditAnnotation != "" {
+		return r.AuditAnnotation
+	}
+	return DefaultAuditAnnotation
+}
+
+// annotateAuditMode marks the desired policies as log-only when the resource enables
+// auditMode.
+func (r *BotNetworkPolicyReconciler) annotateAuditMode(resource *botv1alpha1.BotNetworkPolicy, policies []*networkingv1.NetworkPolicy) {
+	if !resource.Spec.AuditModeEnabled() {
+		return
+	}
+	key := r.auditAnnotation()
+	for _, policy := range policies {
+		if policy.Annotations == nil {
+			policy.Annotations = map[string]string{}
+		}
+		policy.Annotations[key] = "true"
+	}
+}
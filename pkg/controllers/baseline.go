@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// checkBaseline compares the generated CIDRs against the referenced baseline NetworkPolicy
+// and records the BaselineViolation condition on the resource. The status is not persisted
+// here; it is written together with the Ready condition. It returns a non-empty message when
+// the baseline is violated.
+func (r *BotNetworkPolicyReconciler) checkBaseline(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, cidrs directionalCIDRs) (string, error) {
+	ref := resource.Spec.BaselinePolicyRef
+	if ref == nil {
+		meta.RemoveStatusCondition(&resource.Status.Conditions, botv1alpha1.ConditionBaselineViolation)
+		return "", nil
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = resource.Namespace
+	}
+	var baseline networkingv1.NetworkPolicy
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, &baseline); err != nil {
+		if apierrors.IsNotFound(err) {
+			message := fmt.Sprintf("baseline NetworkPolicy %s/%s not found", namespace, ref.Name)
+			r.setBaselineCondition(resource, metav1.ConditionUnknown, "BaselineNotFound", message)
+			return "", nil
+		}
+		return "", err
+	}
+
+	excepts := parsePrefixes(resource.Spec.ExceptCIDRs)
+	var missing []string
+	for _, rule := range baseline.Spec.Ingress {
+		missing = append(missing, missingCIDRs("ingress", rule.From, cidrs.Ingress, excepts)...)
+	}
+	for _, rule := range baseline.Spec.Egress {
+		missing = append(missing, missingCIDRs("egress", rule.To, cidrs.Egress, excepts)...)
+	}
+
+	if len(missing) == 0 {
+		r.setBaselineCondition(resource, metav1.ConditionFalse, "BaselineSatisfied", "generated rules include every baseline CIDR")
+		return "", nil
+	}
+	message := fmt.Sprintf("generated rules omit %d baseline CIDRs from %s/%s: %s", len(missing), namespace, ref.Name, summarizeCIDRs(missing))
+	r.setBaselineCondition(resource, metav1.ConditionTrue, "BaselineCIDRsMissing", message)
+	return message, nil
+}
+
+func (r *BotNetworkPolicyReconciler) setBaselineCondition(resource *botv1alpha1.BotNetworkPolicy, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&resource.Status.Conditions, metav1.Condition{
+		Type:               botv1alpha1.ConditionBaselineViolation,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: resource.Generation,
+	})
+}
+
+// missingCIDRs returns the ipBlock CIDRs of peers that the generated CIDRs, less the
+// excepts carved out of them, do not allow, prefixed with the direction for reporting.
+// Containment rather than equality is checked, since aggregation may merge baseline CIDRs
+// into wider ones.
+func missingCIDRs(direction string, peers []networkingv1.NetworkPolicyPeer, generated []string, excepts []netip.Prefix) []string {
+	allowed := parsePrefixes(generated)
+	var missing []string
+	for _, peer := range peers {
+		if peer.IPBlock == nil {
+			continue
+		}
+		if !allowedBy(peer.IPBlock.CIDR, allowed, excepts) {
+			missing = append(missing, direction+" "+peer.IPBlock.CIDR)
+		}
+	}
+	return missing
+}
+
+// allowedBy reports whether cidr lies within one of allowed without overlapping an except
+// that the containing prefix carves out, as rulePeers does for the generated IPBlocks.
+func allowedBy(cidr string, allowed, excepts []netip.Prefix) bool {
+	required, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return false
+	}
+	required = required.Masked()
+	for _, prefix := range allowed {
+		if prefixContains(prefix, required) && !slices.ContainsFunc(excepts, func(except netip.Prefix) bool {
+			return except.Bits() > prefix.Bits() && prefixContains(prefix, except) && except.Overlaps(required)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixContains reports whether inner lies within outer.
+func prefixContains(outer, inner netip.Prefix) bool {
+	return outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+// parsePrefixes parses cidrs into masked prefixes, skipping entries that are not CIDRs.
+func parsePrefixes(cidrs []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr)); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		}
+	}
+	return prefixes
+}
@@ -2,23 +2,34 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
 	"github.com/sugaf1204/botnetworkpolicy-operator/pkg/providers"
@@ -29,89 +40,642 @@ import (
 //+kubebuilder:rbac:groups=bot.networking.dev,resources=botnetworkpolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=bot.networking.dev,resources=botnetworkpolicies/finalizers,verbs=update
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
-//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=list
 
 type BotNetworkPolicyReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	Recorder   record.EventRecorder
 	HTTPClient *http.Client
+	// ProviderOptions are applied to the provider factory used during reconciliation.
+	ProviderOptions []providers.FactoryOption
+	// APIReader performs uncached reads, e.g. for pods. Defaults to Client when nil.
+	APIReader client.Reader
+	// WarnOnEmptySelector emits a warning event when the pod selector matches no pods.
+	WarnOnEmptySelector bool
+	// WarnOnOverlappingSelectors emits a warning event and sets the OverlappingSelectors
+	// condition when another BotNetworkPolicy in the namespace selects some of the same pods.
+	WarnOnOverlappingSelectors bool
+	// StartupJitter delays the first provider fetch of each object by a random duration
+	// up to this value, spreading load when many objects appear at once. Zero disables it.
+	StartupJitter time.Duration
+	// SyncJitterFraction randomizes every periodic resync by up to this fraction of the
+	// sync period in either direction, e.g. 0.1 turns a 10m period into 9m to 11m. Zero
+	// disables it.
+	SyncJitterFraction float64
+	// MaxProviders rejects resources declaring more providers than this across providers,
+	// ingressProviders and egressProviders. Zero means no limit.
+	MaxProviders int
+	// HostBitsLogLevel is the log verbosity at which CIDRs normalized by clearing host
+	// bits are reported. Zero logs at info level; higher values are debug levels.
+	HostBitsLogLevel int
+
+	// EnableDebugSampling honours the debug-sample-fractions annotation, which drops a
+	// deterministic share of each provider's CIDRs. For load testing only; never enable
+	// it in production.
+	EnableDebugSampling bool
+
+	// FieldManager is the field manager NetworkPolicies are server-side applied under.
+	// Defaults to DefaultFieldManager.
+	FieldManager string
+
+	// TracerProvider supplies the tracer for reconcile spans. Defaults to the global
+	// OpenTelemetry provider.
+	TracerProvider trace.TracerProvider
+	// FinalizerName is the finalizer used to clean up generated NetworkPolicies on deletion.
+	// Defaults to DefaultFinalizerName.
+	FinalizerName string
+	// AuditAnnotation is the annotation key set on generated NetworkPolicies of resources
+	// with auditMode enabled. Defaults to DefaultAuditAnnotation.
+	AuditAnnotation string
+	// SyncTracker, when set, records each resource whose NetworkPolicies were applied, or
+	// that failed validation or apply, so that a readiness check can wait for the first
+	// sync. Nil disables tracking.
+	SyncTracker *SyncTracker
+
+	startup     startupSpreader
+	policyLocks keyedMutex
+	results     providerResultCache
+	fetches     fetchTracker
+	// now overrides the clock used for maintenance windows, pins and fetch scheduling in
+	// tests.
+	now         func() time.Time
+	factoryOnce sync.Once
+	factory     *providers.Factory
 }
 
 //+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 func (r *BotNetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := r.startSpan(ctx, "Reconcile",
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("botnetworkpolicy.name", req.Name),
+	)
+	result, err := r.reconcile(ctx, req)
+	endSpan(span, err)
+	return result, err
+}
+
+func (r *BotNetworkPolicyReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
 	var resource botv1alpha1.BotNetworkPolicy
 	if err := r.Get(ctx, req.NamespacedName, &resource); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.startup.forget(req.NamespacedName)
+			r.SyncTracker.forget(req.NamespacedName)
+			r.results.forget(req.NamespacedName)
+			r.fetches.forget(req.NamespacedName)
+			policyCIDRCount.DeleteLabelValues(req.Namespace, req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if !resource.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, &resource, logger)
+	}
+	if err := r.ensureFinalizer(ctx, &resource); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if meta.FindStatusCondition(resource.Status.Conditions, botv1alpha1.ConditionReady) == nil {
+		if err := r.setReadyCondition(ctx, &resource, metav1.ConditionFalse, ReasonPending, "waiting for the first successful provider sync"); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	if err := resource.Validate(); err != nil {
 		logger.Error(err, "invalid specification")
-		r.Recorder.Event(&resource, corev1.EventTypeWarning, "InvalidSpec", err.Error())
+		r.Recorder.Event(&resource, corev1.EventTypeWarning, ReasonInvalidSpec, err.Error())
+		r.SyncTracker.markObserved(req.NamespacedName)
+		return ctrl.Result{}, r.setReadyCondition(ctx, &resource, metav1.ConditionFalse, ReasonInvalidSpec, err.Error())
+	}
+	if err := r.checkProviderLimit(&resource); err != nil {
+		logger.Error(err, "provider limit exceeded")
+		r.Recorder.Event(&resource, corev1.EventTypeWarning, ReasonTooManyProviders, err.Error())
+		r.SyncTracker.markObserved(req.NamespacedName)
+		return ctrl.Result{}, r.setReadyCondition(ctx, &resource, metav1.ConditionFalse, ReasonTooManyProviders, err.Error())
+	}
+
+	if r.setNoSourcesCondition(&resource) {
+		r.Recorder.Event(&resource, corev1.EventTypeWarning, ReasonNoSources, noSourcesMessage)
+	}
+
+	if pinned(&resource) {
+		logger.Info("provider results pinned, skipping fetch", "pinnedSince", resource.Status.PinnedSince)
+		r.fetches.forget(req.NamespacedName)
+		if err := r.holdPinned(ctx, &resource); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.markPreviouslySynced(&resource)
 		return ctrl.Result{}, nil
 	}
+	resource.Status.PinnedSince = nil
+
+	if delay := r.startup.delay(req.NamespacedName, r.StartupJitter); delay > 0 {
+		logger.Info("delaying first sync to spread provider load", "requeueAfter", delay)
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	// Within the sync period the CIDRs of the last fetch are reapplied without refetching,
+	// so that events such as a deleted or edited generated NetworkPolicy still repair it.
+	fetchHash := hashFetchInputs(&resource)
+	collected, wait := r.fetches.remaining(req.NamespacedName, fetchHash, syncPeriod(&resource), r.currentTime())
+	fetched := wait == 0
+	if fetched {
+		var warnings []string
+		var err error
+		collected, warnings, err = r.collectDirectionalCIDRs(ctx, &resource, logger)
+		if err != nil {
+			return r.collectFailed(ctx, &resource, err, logger)
+		}
+		for _, warning := range warnings {
+			r.Recorder.Event(&resource, corev1.EventTypeWarning, ReasonProviderWarning, warning)
+		}
+	} else {
+		logger.Info("providers fetched within the sync period, reapplying their CIDRs", "requeueAfter", wait)
+	}
 
-	cidrs, warnings, err := r.collectCIDRs(ctx, &resource, logger)
+	// Pod IPs change far more often than provider feeds, so they are resolved on every
+	// reconcile instead of being reused from the last fetch.
+	withPods, err := r.withPodPeers(ctx, &resource, collected)
 	if err != nil {
-		logger.Error(err, "failed to collect CIDRs")
-		return ctrl.Result{}, err
+		return r.collectFailed(ctx, &resource, err, logger)
+	}
+
+	cidrs, truncated := limitPolicyCIDRs(&resource, withPods)
+	if truncated != "" && fetched {
+		r.Recorder.Event(&resource, corev1.EventTypeWarning, ReasonMaxCIDRsExceeded, truncated)
+	}
+	setProvidersHealthyCondition(&resource, nil)
+	if failed, total := providerFailures(&resource); failed > 0 && fetched {
+		reason := ReasonProviderPartialFailure
+		if failed == total {
+			reason = ReasonProviderTotalFailure
+		}
+		r.Recorder.Eventf(&resource, corev1.EventTypeWarning, reason, "%d of %d providers failed to sync", failed, total)
+	}
+
+	syncAfter := wait
+	if fetched {
+		syncAfter = jitterSyncPeriod(syncPeriod(&resource), r.SyncJitterFraction)
+	}
+	if hasPodPeers(&resource) {
+		syncAfter = min(syncAfter, podPeerResyncPeriod)
 	}
 
-	for _, warning := range warnings {
-		r.Recorder.Event(&resource, corev1.EventTypeWarning, "ProviderWarning", warning)
+	deferFor, err := r.maintenanceDeferral(ctx, &resource, cidrs)
+	if err != nil {
+		logger.Error(err, "failed to evaluate maintenance window")
+		return ctrl.Result{}, err
+	}
+	if deferFor > 0 {
+		message := fmt.Sprintf("CIDR changes deferred until the next maintenance window opens in %s", deferFor.Round(time.Second))
+		logger.Info("deferring networkpolicy update to maintenance window", "windowOpensIn", deferFor)
+		r.Recorder.Event(&resource, corev1.EventTypeNormal, ReasonUpdateDeferred, message)
+		if err := r.setReadyCondition(ctx, &resource, metav1.ConditionTrue, ReasonUpdateDeferred, message); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.markPreviouslySynced(&resource)
+		return ctrl.Result{RequeueAfter: min(deferFor, syncAfter)}, nil
 	}
 
-	if err := r.ensureNetworkPolicy(ctx, &resource, cidrs, logger); err != nil {
+	changed, err := r.ensureNetworkPolicy(ctx, &resource, cidrs, logger)
+	if err != nil {
 		logger.Error(err, "failed to ensure network policy")
+		r.SyncTracker.markObserved(req.NamespacedName)
+		statusErr := r.setReadyCondition(ctx, &resource, metav1.ConditionFalse, ReasonPolicyApplyFailed, err.Error())
+		return ctrl.Result{}, errors.Join(err, statusErr)
+	}
+
+	violation, err := r.checkBaseline(ctx, &resource, cidrs)
+	if err != nil {
+		logger.Error(err, "failed to compare against baseline policy")
 		return ctrl.Result{}, err
 	}
+	if violation != "" {
+		r.Recorder.Event(&resource, corev1.EventTypeWarning, ReasonBaselineViolation, violation)
+	}
 
-	syncAfter := resource.Spec.SyncPeriod.Duration
-	if syncAfter == 0 {
-		syncAfter = providers.DefaultSyncPeriod
+	if r.WarnOnEmptySelector {
+		r.warnIfSelectorMatchesNoPods(ctx, &resource, logger)
+	}
+	if r.WarnOnOverlappingSelectors {
+		if overlap, err := r.checkOverlappingSelectors(ctx, &resource); err != nil {
+			logger.Error(err, "failed to check for overlapping pod selectors")
+		} else if overlap != "" {
+			r.Recorder.Event(&resource, corev1.EventTypeWarning, ReasonOverlappingSelectors, overlap)
+		}
+	} else {
+		meta.RemoveStatusCondition(&resource.Status.Conditions, botv1alpha1.ConditionOverlappingSelectors)
+	}
+
+	policyCIDRCount.WithLabelValues(resource.Namespace, resource.Name).Set(float64(sets.New(cidrs.Ingress...).Insert(cidrs.Egress...).Len()))
+
+	if changed {
+		resource.Status.StableReconciles = 0
+	} else if fetched {
+		resource.Status.StableReconciles++
+	}
+	if fetched {
+		now := metav1.Now()
+		resource.Status.LastSyncTime = &now
+	}
+	readyReason, readyMessage := ReasonSynced, "providers synchronised and NetworkPolicy applied"
+	if resource.Spec.DryRunEnabled() {
+		readyReason, readyMessage = ReasonDryRun, dryRunReadyMessage
+	}
+	if err := r.setReadyCondition(ctx, &resource, metav1.ConditionTrue, readyReason, readyMessage); err != nil {
+		return ctrl.Result{}, err
+	}
+	if fetched {
+		r.fetches.record(req.NamespacedName, fetchHash, collected, r.currentTime())
+	}
+	if !resource.Spec.DryRunEnabled() {
+		r.SyncTracker.markObserved(req.NamespacedName)
 	}
 
 	logger.Info("reconciliation complete", "requeueAfter", syncAfter)
 	return ctrl.Result{RequeueAfter: syncAfter}, nil
 }
 
-func (r *BotNetworkPolicyReconciler) ensureNetworkPolicy(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, cidrs []string, logger logr.Logger) error {
-	desired := buildNetworkPolicy(resource, cidrs)
+// collectFailed records that the CIDRs of resource could not be collected as a whole.
+func (r *BotNetworkPolicyReconciler) collectFailed(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, err error, logger logr.Logger) (ctrl.Result, error) {
+	logger.Error(err, "failed to collect CIDRs")
+	r.markPreviouslySynced(resource)
+	setProvidersHealthyCondition(resource, err)
+	statusErr := r.setReadyCondition(ctx, resource, metav1.ConditionFalse, ReasonProviderTotalFailure, err.Error())
+	return ctrl.Result{}, errors.Join(err, statusErr)
+}
+
+// syncPeriod returns how often the providers of resource are re-polled, before jitter.
+func syncPeriod(resource *botv1alpha1.BotNetworkPolicy) time.Duration {
+	if resource.Spec.SyncPeriod.Duration > 0 {
+		return resource.Spec.SyncPeriod.Duration
+	}
+	return providers.DefaultSyncPeriod
+}
+
+const noSourcesMessage = "no providers or custom CIDRs are configured; the NetworkPolicy denies all selected traffic"
+
+// providerFailures returns how many of the providers collected in the last sync recorded
+// an error, and how many were collected in total.
+func providerFailures(resource *botv1alpha1.BotNetworkPolicy) (failed, total int) {
+	for _, status := range resource.Status.ProviderStatuses {
+		if status.LastError != "" {
+			failed++
+		}
+	}
+	return failed, len(resource.Status.ProviderStatuses)
+}
+
+// setProvidersHealthyCondition records the ProvidersHealthy condition on the resource
+// without persisting it, from collectErr, when CIDR collection failed as a whole, or else
+// from the provider statuses of the latest sync.
+func setProvidersHealthyCondition(resource *botv1alpha1.BotNetworkPolicy, collectErr error) {
+	condition := metav1.Condition{
+		Type:               botv1alpha1.ConditionProvidersHealthy,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonProvidersSynced,
+		ObservedGeneration: resource.Generation,
+	}
+	failed, total := providerFailures(resource)
+	switch {
+	case collectErr != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonProviderTotalFailure
+		condition.Message = collectErr.Error()
+	case failed > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonProviderPartialFailure
+		if failed == total {
+			condition.Reason = ReasonProviderTotalFailure
+		}
+		errs := make([]string, 0, failed)
+		for _, status := range resource.Status.ProviderStatuses {
+			if status.LastError != "" {
+				label := status.DisplayName
+				if label == "" {
+					label = status.Name
+				}
+				errs = append(errs, label+": "+status.LastError)
+			}
+		}
+		condition.Message = fmt.Sprintf("%d of %d providers failed to sync: %s", failed, total, strings.Join(errs, "; "))
+	default:
+		condition.Message = fmt.Sprintf("all %d providers synced", total)
+	}
+	meta.SetStatusCondition(&resource.Status.Conditions, condition)
+}
+
+// setNoSourcesCondition records the NoSources condition on the resource without persisting
+// it, and reports whether the resource has no sources.
+func (r *BotNetworkPolicyReconciler) setNoSourcesCondition(resource *botv1alpha1.BotNetworkPolicy) bool {
+	condition := metav1.Condition{
+		Type:               botv1alpha1.ConditionNoSources,
+		Status:             metav1.ConditionFalse,
+		Reason:             "SourcesConfigured",
+		Message:            "at least one provider or custom CIDR is configured",
+		ObservedGeneration: resource.Generation,
+	}
+	noSources := !resource.Spec.HasSources()
+	if noSources {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NoSourcesConfigured"
+		condition.Message = noSourcesMessage
+	}
+	meta.SetStatusCondition(&resource.Status.Conditions, condition)
+	return noSources
+}
+
+// checkProviderLimit returns an error when the resource declares more providers than
+// MaxProviders allows.
+func (r *BotNetworkPolicyReconciler) checkProviderLimit(resource *botv1alpha1.BotNetworkPolicy) error {
+	if r.MaxProviders <= 0 {
+		return nil
+	}
+	count := len(resource.Spec.Providers) + len(resource.Spec.IngressProviders) + len(resource.Spec.EgressProviders)
+	if count > r.MaxProviders {
+		return fmt.Errorf("resource declares %d providers, exceeding the controller limit of %d", count, r.MaxProviders)
+	}
+	return nil
+}
+
+// warnIfSelectorMatchesNoPods emits a warning event when the policy's pod selector matches
+// no pods in the namespace, since such a policy has no effect.
+func (r *BotNetworkPolicyReconciler) warnIfSelectorMatchesNoPods(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, logger logr.Logger) {
+	selector, err := podSelectorFor(resource)
+	if err != nil {
+		logger.Error(err, "invalid pod selector")
+		return
+	}
+
+	var pods corev1.PodList
+	if err := r.reader().List(ctx, &pods, client.InNamespace(resource.Namespace), client.MatchingLabelsSelector{Selector: selector}, client.Limit(1)); err != nil {
+		logger.Error(err, "failed to list pods for selector check")
+		return
+	}
+	if len(pods.Items) == 0 {
+		r.Recorder.Eventf(resource, corev1.EventTypeWarning, ReasonNoMatchingPods, "pod selector %q matches no pods in namespace %s; the NetworkPolicy has no effect", selector.String(), resource.Namespace)
+	}
+}
+
+// providerFactory returns the factory shared across reconciles, so provider caches
+// survive between syncs. It is built on first use from HTTPClient and ProviderOptions.
+func (r *BotNetworkPolicyReconciler) providerFactory() *providers.Factory {
+	r.factoryOnce.Do(func() {
+		r.factory = providers.NewFactory(r.Client, r.HTTPClient, r.ProviderOptions...)
+	})
+	return r.factory
+}
+
+func (r *BotNetworkPolicyReconciler) reader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// setReadyCondition records the Ready condition and persists the status subresource.
+func (r *BotNetworkPolicyReconciler) setReadyCondition(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, status metav1.ConditionStatus, reason, message string) error {
+	meta.SetStatusCondition(&resource.Status.Conditions, metav1.Condition{
+		Type:               botv1alpha1.ConditionReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: resource.Generation,
+	})
+	return r.Status().Update(ctx, resource)
+}
+
+// ensureNetworkPolicy applies the desired NetworkPolicies, prunes stale ones and reports
+// whether any policy was created, updated or deleted.
+func (r *BotNetworkPolicyReconciler) ensureNetworkPolicy(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, cidrs directionalCIDRs, logger logr.Logger) (bool, error) {
+	unlock := r.policyLocks.lock(client.ObjectKeyFromObject(resource))
+	defer unlock()
+
+	desiredPolicies := buildNetworkPolicies(resource, cidrs)
+	for _, warning := range unusedExcepts(resource.Spec.ExceptCIDRs, cidrs) {
+		logger.Info("unused except", "warning", warning)
+		if r.Recorder != nil {
+			r.Recorder.Event(resource, corev1.EventTypeWarning, ReasonUnusedExcept, warning)
+		}
+	}
+	for _, warning := range limitIPBlockExcepts(desiredPolicies, maxIPBlockExcepts) {
+		logger.Info("except limit exceeded", "warning", warning)
+		if r.Recorder != nil {
+			r.Recorder.Event(resource, corev1.EventTypeWarning, ReasonExceptLimitExceeded, warning)
+		}
+	}
+	r.annotateAuditMode(resource, desiredPolicies)
+	current, err := r.ownedNetworkPolicies(ctx, resource)
+	if err != nil {
+		return false, err
+	}
+	if resource.Spec.DryRunEnabled() {
+		return false, r.reportDryRun(ctx, resource, current, desiredPolicies, logger)
+	}
+	resource.Status.PlannedCIDRCount = 0
+	desiredNames := sets.New[string]()
+	changed := false
+	for _, desired := range desiredPolicies {
+		reason, err := r.applyNetworkPolicy(ctx, resource, desired, logger)
+		if err != nil {
+			return false, err
+		}
+		if reason != ReasonNoChange {
+			changed = true
+			if r.Recorder != nil {
+				r.Recorder.Eventf(resource, corev1.EventTypeNormal, reason, "NetworkPolicy %s %s", desired.Name, policyChangeVerb(reason))
+			}
+		}
+		desiredNames.Insert(desired.Name)
+	}
+	pruned, err := r.pruneNetworkPolicies(ctx, resource, desiredNames, logger)
+	if err != nil {
+		return false, err
+	}
+	changed = changed || pruned > 0
+	if changed {
+		resource.Status.LastChange = policyChange(allowedCIDRs(current), allowedCIDRs(desiredPolicies), r.currentTime())
+	} else if r.Recorder != nil {
+		r.Recorder.Event(resource, corev1.EventTypeNormal, ReasonNoChange, "generated NetworkPolicies are up to date")
+	}
+	return changed, nil
+}
+
+// policyChangeVerb describes the change a CreatedPolicy or UpdatedPolicy reason reports.
+func policyChangeVerb(reason string) string {
+	if reason == ReasonCreatedPolicy {
+		return "created"
+	}
+	return "updated"
+}
 
+// DefaultFieldManager is the field manager generated NetworkPolicies are server-side
+// applied under.
+const DefaultFieldManager = "botnetworkpolicy-operator"
+
+func (r *BotNetworkPolicyReconciler) fieldManager() string {
+	if r.FieldManager != "" {
+		return r.FieldManager
+	}
+	return DefaultFieldManager
+}
+
+// applyNetworkPolicy server-side applies the desired NetworkPolicy and returns
+// ReasonCreatedPolicy, ReasonUpdatedPolicy or ReasonNoChange depending on what was written.
+// Ownership is forced to take over fields written by earlier update-based syncs or manual
+// edits; a same-named policy not controlled by the resource is still rejected. Whether the
+// policy changed is derived from its resourceVersion before and after the apply, which the
+// API server leaves alone when the apply is a no-op.
+func (r *BotNetworkPolicyReconciler) applyNetworkPolicy(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, desired *networkingv1.NetworkPolicy, logger logr.Logger) (string, error) {
 	var existing networkingv1.NetworkPolicy
 	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, &existing)
-	if err != nil {
-		if client.IgnoreNotFound(err) != nil {
-			return err
+	if client.IgnoreNotFound(err) != nil {
+		return "", err
+	}
+	found := err == nil
+	if found && !metav1.IsControlledBy(&existing, resource) {
+		return "", fmt.Errorf("networkpolicy %s/%s exists and is not controlled by BotNetworkPolicy", desired.Namespace, desired.Name)
+	}
+
+	logger.V(1).Info("applying networkpolicy", "name", desired.Name, "fieldManager", r.fieldManager())
+	if err := r.patchNetworkPolicy(ctx, resource, desired); err != nil {
+		return "", err
+	}
+	switch {
+	case !found:
+		return ReasonCreatedPolicy, nil
+	case desired.ResourceVersion != existing.ResourceVersion:
+		return ReasonUpdatedPolicy, nil
+	default:
+		return ReasonNoChange, nil
+	}
+}
+
+// patchNetworkPolicy server-side applies policy, controlled by resource, under the field
+// manager with forced ownership, and updates policy with the result.
+func (r *BotNetworkPolicyReconciler) patchNetworkPolicy(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, policy *networkingv1.NetworkPolicy, opts ...client.PatchOption) error {
+	policy.TypeMeta = metav1.TypeMeta{APIVersion: networkingv1.SchemeGroupVersion.String(), Kind: "NetworkPolicy"}
+	if err := controllerutil.SetControllerReference(resource, policy, r.Scheme); err != nil {
+		return err
+	}
+	opts = append([]client.PatchOption{client.FieldOwner(r.fieldManager()), client.ForceOwnership}, opts...)
+	return r.Patch(ctx, policy, client.Apply, opts...)
+}
+
+// ownedNetworkPolicies returns the generated NetworkPolicies currently controlled by resource.
+func (r *BotNetworkPolicyReconciler) ownedNetworkPolicies(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy) ([]*networkingv1.NetworkPolicy, error) {
+	var owned networkingv1.NetworkPolicyList
+	if err := r.List(ctx, &owned, client.InNamespace(resource.Namespace), client.MatchingLabels{ownerLabel: resource.Name}); err != nil {
+		return nil, err
+	}
+	policies := make([]*networkingv1.NetworkPolicy, 0, len(owned.Items))
+	for i := range owned.Items {
+		if metav1.IsControlledBy(&owned.Items[i], resource) {
+			policies = append(policies, &owned.Items[i])
 		}
-		if err := controllerutil.SetControllerReference(resource, desired, r.Scheme); err != nil {
-			return err
+	}
+	return policies, nil
+}
+
+// pruneNetworkPolicies deletes NetworkPolicies controlled by the resource that are no longer
+// desired, e.g. split policies left over after the CIDR set shrank, and returns how many
+// were deleted.
+func (r *BotNetworkPolicyReconciler) pruneNetworkPolicies(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, desiredNames sets.Set[string], logger logr.Logger) (int, error) {
+	var owned networkingv1.NetworkPolicyList
+	if err := r.List(ctx, &owned, client.InNamespace(resource.Namespace), client.MatchingLabels{ownerLabel: resource.Name}); err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for i := range owned.Items {
+		policy := &owned.Items[i]
+		if desiredNames.Has(policy.Name) || !metav1.IsControlledBy(policy, resource) {
+			continue
 		}
-		logger.Info("creating networkpolicy", "name", desired.Name)
-		return r.Create(ctx, desired)
+		logger.Info("deleting stale networkpolicy", "name", policy.Name)
+		if err := r.Delete(ctx, policy); client.IgnoreNotFound(err) != nil {
+			return deleted, err
+		}
+		deleted++
 	}
+	return deleted, nil
+}
+
+// directionalCIDRs holds the CIDR sets applied to ingress and egress rules.
+type directionalCIDRs struct {
+	Ingress []string
+	Egress  []string
+}
+
+// sharedCIDRs returns a directionalCIDRs that applies the same set to both directions.
+func sharedCIDRs(cidrs []string) directionalCIDRs {
+	return directionalCIDRs{Ingress: cidrs, Egress: cidrs}
+}
 
-	if metav1.IsControlledBy(&existing, resource) {
-		if networkPoliciesEqual(&existing, desired) {
-			return nil
+// ownerLabel is set on every generated NetworkPolicy to the name of its BotNetworkPolicy.
+const ownerLabel = "botnetworkpolicy.bot.networking.dev/owner"
+
+// buildNetworkPolicies returns the desired NetworkPolicies for the resource. Unless
+// MaxPeersPerPolicy splits the peers, this is a single policy.
+func buildNetworkPolicies(resource *botv1alpha1.BotNetworkPolicy, cidrs directionalCIDRs) []*networkingv1.NetworkPolicy {
+	limit := resource.Spec.MaxPeersPerPolicy
+	ingressChunks := splitCIDRs(cidrs.Ingress, limit)
+	egressChunks := splitCIDRs(cidrs.Egress, limit)
+	count := max(len(ingressChunks), len(egressChunks), 1)
+
+	policies := make([]*networkingv1.NetworkPolicy, 0, count)
+	for i := 0; i < count; i++ {
+		// The pod selector peer is carried by the first policy only.
+		policy := buildNetworkPolicyPart(resource, directionalCIDRs{
+			Ingress: chunkAt(ingressChunks, i),
+			Egress:  chunkAt(egressChunks, i),
+		}, i == 0)
+		if i > 0 {
+			policy.Name = fmt.Sprintf("%s-%d", policy.Name, i)
 		}
-		existing.Spec = desired.Spec
-		existing.Labels = desired.Labels
-		existing.Annotations = desired.Annotations
-		logger.Info("updating networkpolicy", "name", desired.Name)
-		return r.Update(ctx, &existing)
+		policies = append(policies, policy)
 	}
+	return policies
+}
 
-	return fmt.Errorf("networkpolicy %s/%s exists and is not controlled by BotNetworkPolicy", desired.Namespace, desired.Name)
+// splitCIDRs partitions cidrs into consecutive chunks of at most limit entries. A
+// non-positive limit returns the input as a single chunk.
+func splitCIDRs(cidrs []string, limit int) [][]string {
+	if len(cidrs) == 0 {
+		return nil
+	}
+	if limit <= 0 || len(cidrs) <= limit {
+		return [][]string{cidrs}
+	}
+	chunks := make([][]string, 0, (len(cidrs)+limit-1)/limit)
+	for start := 0; start < len(cidrs); start += limit {
+		end := min(start+limit, len(cidrs))
+		chunks = append(chunks, cidrs[start:end])
+	}
+	return chunks
 }
 
-func buildNetworkPolicy(resource *botv1alpha1.BotNetworkPolicy, cidrs []string) *networkingv1.NetworkPolicy {
+func chunkAt(chunks [][]string, i int) []string {
+	if i < len(chunks) {
+		return chunks[i]
+	}
+	return nil
+}
+
+func buildNetworkPolicy(resource *botv1alpha1.BotNetworkPolicy, cidrs directionalCIDRs) *networkingv1.NetworkPolicy {
+	return buildNetworkPolicyPart(resource, cidrs, true)
+}
+
+// buildNetworkPolicyPart builds one generated policy. includePodPeer controls whether the
+// spec's PeerPodSelector and NamespaceSelector peers are added to its rules.
+func buildNetworkPolicyPart(resource *botv1alpha1.BotNetworkPolicy, cidrs directionalCIDRs, includePodPeer bool) *networkingv1.NetworkPolicy {
 	labels := map[string]string{
-		"botnetworkpolicy.bot.networking.dev/owner": resource.Name,
+		ownerLabel: resource.Name,
 	}
 
 	podSelector := metav1.LabelSelector{}
@@ -124,16 +688,20 @@ func buildNetworkPolicy(resource *botv1alpha1.BotNetworkPolicy, cidrs []string)
 	ingressRules := []networkingv1.NetworkPolicyIngressRule{}
 	egressRules := []networkingv1.NetworkPolicyEgressRule{}
 
-	if len(cidrs) > 0 {
-		peers := make([]networkingv1.NetworkPolicyPeer, 0, len(cidrs))
-		for _, cidr := range cidrs {
-			peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
-		}
-		if resource.Spec.IngressEnabled() {
-			ingressRules = append(ingressRules, networkingv1.NetworkPolicyIngressRule{From: peers})
+	var podPeer, namespacePeer *metav1.LabelSelector
+	if includePodPeer {
+		podPeer = resource.Spec.PeerPodSelector
+		namespacePeer = resource.Spec.NamespaceSelector
+	}
+	excepts := parseExcepts(resource.Spec.ExceptCIDRs)
+	if resource.Spec.IngressEnabled() {
+		if peers := rulePeers(cidrs.Ingress, excepts, podPeer, namespacePeer); len(peers) > 0 {
+			ingressRules = append(ingressRules, networkingv1.NetworkPolicyIngressRule{Ports: rulePorts(resource.Spec.Ports), From: peers})
 		}
-		if resource.Spec.EgressEnabled() {
-			egressRules = append(egressRules, networkingv1.NetworkPolicyEgressRule{To: peers})
+	}
+	if resource.Spec.EgressEnabled() {
+		if peers := rulePeers(cidrs.Egress, excepts, podPeer, namespacePeer); len(peers) > 0 {
+			egressRules = append(egressRules, networkingv1.NetworkPolicyEgressRule{Ports: rulePorts(resource.Spec.Ports), To: peers})
 		}
 	}
 
@@ -152,104 +720,42 @@ func buildNetworkPolicy(resource *botv1alpha1.BotNetworkPolicy, cidrs []string)
 	}
 }
 
-func networkPoliciesEqual(existing *networkingv1.NetworkPolicy, desired *networkingv1.NetworkPolicy) bool {
-	if len(existing.Spec.PolicyTypes) != len(desired.Spec.PolicyTypes) {
-		return false
-	}
-	existingTypes := append([]networkingv1.PolicyType{}, existing.Spec.PolicyTypes...)
-	desiredTypes := append([]networkingv1.PolicyType{}, desired.Spec.PolicyTypes...)
-	sort.Slice(existingTypes, func(i, j int) bool { return existingTypes[i] < existingTypes[j] })
-	sort.Slice(desiredTypes, func(i, j int) bool { return desiredTypes[i] < desiredTypes[j] })
-	for i := range existingTypes {
-		if existingTypes[i] != desiredTypes[i] {
-			return false
-		}
-	}
-	if !networkPolicyIngressEqual(existing.Spec.Ingress, desired.Spec.Ingress) {
-		return false
-	}
-	if !networkPolicyEgressEqual(existing.Spec.Egress, desired.Spec.Egress) {
-		return false
-	}
-	if !selectorsEqual(existing.Spec.PodSelector, desired.Spec.PodSelector) {
-		return false
-	}
-	return true
-}
-
-func selectorsEqual(a, b metav1.LabelSelector) bool {
-	if len(a.MatchLabels) != len(b.MatchLabels) {
-		return false
-	}
-	for k, v := range a.MatchLabels {
-		if b.MatchLabels[k] != v {
-			return false
-		}
-	}
-	if len(a.MatchExpressions) != len(b.MatchExpressions) {
-		return false
-	}
-	for i := range a.MatchExpressions {
-		ae := a.MatchExpressions[i]
-		be := b.MatchExpressions[i]
-		if ae.Key != be.Key || ae.Operator != be.Operator || !equalStringSlices(ae.Values, be.Values) {
-			return false
-		}
-	}
-	return true
-}
-
-func equalStringSlices(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
+// rulePeers returns the IPBlock peers for cidrs, carrying the excepts each contains,
+// followed by a pod selector peer when podSelector is set and a namespace selector peer
+// when namespaceSelector is set. A peer may not combine an IPBlock with selectors, so the
+// selectors are always emitted as separate peers.
+func rulePeers(cidrs []string, excepts []*net.IPNet, podSelector, namespaceSelector *metav1.LabelSelector) []networkingv1.NetworkPolicyPeer {
+	peers := ipBlockPeers(cidrs)
+	for i := range peers {
+		peers[i].IPBlock.Except = exceptsWithin(peers[i].IPBlock.CIDR, excepts)
 	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
+	if podSelector != nil {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: podSelector.DeepCopy()})
 	}
-	return true
-}
-
-func networkPolicyIngressEqual(a, b []networkingv1.NetworkPolicyIngressRule) bool {
-	if len(a) != len(b) {
-		return false
+	if namespaceSelector != nil {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: namespaceSelector.DeepCopy()})
 	}
-	for i := range a {
-		if !networkPolicyPeersEqual(a[i].From, b[i].From) {
-			return false
-		}
-	}
-	return true
+	return peers
 }
 
-func networkPolicyEgressEqual(a, b []networkingv1.NetworkPolicyEgressRule) bool {
-	if len(a) != len(b) {
-		return false
+// rulePorts copies the spec's ports for a generated rule; nil allows all ports.
+func rulePorts(ports []networkingv1.NetworkPolicyPort) []networkingv1.NetworkPolicyPort {
+	if len(ports) == 0 {
+		return nil
 	}
-	for i := range a {
-		if !networkPolicyPeersEqual(a[i].To, b[i].To) {
-			return false
-		}
+	copied := make([]networkingv1.NetworkPolicyPort, len(ports))
+	for i := range ports {
+		ports[i].DeepCopyInto(&copied[i])
 	}
-	return true
+	return copied
 }
 
-func networkPolicyPeersEqual(a, b []networkingv1.NetworkPolicyPeer) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if (a[i].IPBlock == nil) != (b[i].IPBlock == nil) {
-			return false
-		}
-		if a[i].IPBlock != nil {
-			if a[i].IPBlock.CIDR != b[i].IPBlock.CIDR || !equalStringSlices(a[i].IPBlock.Except, b[i].IPBlock.Except) {
-				return false
-			}
-		}
+func ipBlockPeers(cidrs []string) []networkingv1.NetworkPolicyPeer {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
 	}
-	return true
+	return peers
 }
 
 func determinePolicyTypes(requested []networkingv1.PolicyType, ingress, egress *bool) []networkingv1.PolicyType {
@@ -269,47 +775,311 @@ func determinePolicyTypes(requested []networkingv1.PolicyType, ingress, egress *
 	return sets.List(enabled)
 }
 
-func (r *BotNetworkPolicyReconciler) collectCIDRs(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, logger logr.Logger) ([]string, []string, error) {
-	factory := providers.NewFactory(r.Client, r.HTTPClient)
+// collectDirectionalCIDRs resolves the ingress and egress CIDR sets. The shared provider
+// list is fetched once and reused by every enabled direction without its own override.
+func (r *BotNetworkPolicyReconciler) collectDirectionalCIDRs(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, logger logr.Logger) (directionalCIDRs, []string, error) {
+	spec := &resource.Spec
+	ingressOverride := len(spec.IngressProviders) > 0
+	egressOverride := len(spec.EgressProviders) > 0
+
+	var result directionalCIDRs
+	warnings := make([]string, 0)
+	resource.Status.ProviderStatuses = nil
+	resource.Status.ProviderCount = 0
+
+	if (spec.IngressEnabled() && !ingressOverride) || (spec.EgressEnabled() && !egressOverride) || (!ingressOverride && !egressOverride) {
+		cidrs, providerWarnings, err := r.collectCIDRs(ctx, resource, spec.Providers, logger)
+		if err != nil {
+			return result, nil, err
+		}
+		warnings = append(warnings, providerWarnings...)
+		result = sharedCIDRs(cidrs)
+	}
+
+	if ingressOverride && spec.IngressEnabled() {
+		cidrs, providerWarnings, err := r.collectCIDRs(ctx, resource, spec.IngressProviders, logger)
+		if err != nil {
+			return result, nil, err
+		}
+		warnings = append(warnings, providerWarnings...)
+		result.Ingress = cidrs
+	}
+
+	if egressOverride && spec.EgressEnabled() {
+		cidrs, providerWarnings, err := r.collectCIDRs(ctx, resource, spec.EgressProviders, logger)
+		if err != nil {
+			return result, nil, err
+		}
+		warnings = append(warnings, providerWarnings...)
+		result.Egress = cidrs
+	}
+
+	return result, warnings, nil
+}
+
+// collectCIDRs fetches every provider in specs and returns the merged, sorted CIDR set.
+// The outcome of each provider is appended to the resource's ProviderStatuses.
+func (r *BotNetworkPolicyReconciler) collectCIDRs(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, specs []botv1alpha1.ProviderSpec, logger logr.Logger) ([]string, []string, error) {
+	ctx, span := r.startSpan(ctx, "collectCIDRs", attrProviderCount.Int(len(specs)))
+	defer span.End()
+	factory := r.providerFactory()
 
 	providerCIDRs := sets.NewString()
 	warnings := make([]string, 0)
 
-	for _, providerSpec := range resource.Spec.Providers {
-		provider, err := factory.FromSpec(resource.Namespace, providerSpec)
+	var fractions map[string]float64
+	if value, ok := resource.Annotations[debugSampleAnnotation]; ok && r.EnableDebugSampling {
+		parsed, err := sampleFractions(value)
 		if err != nil {
-			warnings = append(warnings, fmt.Sprintf("provider %s skipped: %v", providerSpec.Name, err))
+			warnings = append(warnings, fmt.Sprintf("debug sampling ignored: %v", err))
+		}
+		fractions = parsed
+	}
+
+	resolved, resolveErrs := factory.ResolveAll(ctx, resource.Namespace, specs)
+	built, buildErrs := factory.BuildAll(resource.Namespace, resolved)
+	setupErrs := make([]error, len(resolved))
+	for i := range resolved {
+		setupErrs[i] = resolveErrs[i]
+		if setupErrs[i] == nil {
+			setupErrs[i] = buildErrs[i]
+		}
+	}
+	fetched := r.fetchProviders(ctx, resolved, built, setupErrs, factory.Concurrency())
+	for i, providerSpec := range resolved {
+		label := providerSpec.Label()
+		status := botv1alpha1.ProviderStatus{Name: providerSpec.Name, DisplayName: providerSpec.DisplayName}
+
+		provider := built[i]
+		if err := setupErrs[i]; err != nil {
+			warnings = append(warnings, fmt.Sprintf("provider %s skipped: %v", label, err))
+			status.LastError = err.Error()
+			resource.Status.ProviderStatuses = append(resource.Status.ProviderStatuses, status)
 			continue
 		}
+		if providerSpec.InsecureSkipTLSVerifyEnabled() && r.Recorder != nil {
+			r.Recorder.Event(resource, corev1.EventTypeWarning, ReasonInsecureTLS, fmt.Sprintf("provider %s fetches with TLS certificate verification disabled (insecureSkipTLSVerify); the endpoint's identity is not checked", label))
+		}
 
-		cidrs, err := provider.Fetch(ctx)
+		cidrs, err := fetched[i].cidrs, fetched[i].err
 		if err != nil {
-			warnings = append(warnings, fmt.Sprintf("provider %s fetch error: %v", providerSpec.Name, err))
+			warnings = append(warnings, r.fetchErrorWarning(resource, label, err, logger)...)
+			status.LastError = err.Error()
+			resource.Status.ProviderStatuses = append(resource.Status.ProviderStatuses, status)
 			continue
 		}
 
-		for _, cidr := range cidrs {
-			normalized := strings.TrimSpace(cidr)
-			if normalized == "" {
-				continue
+		if reporter, ok := provider.(providers.VersionReporter); ok {
+			status.FeedVersion = reporter.FeedVersion()
+		}
+		if reporter, ok := provider.(providers.TimestampReporter); ok {
+			if produced, ok := reporter.DataTimestamp(); ok {
+				status.DataTimestamp = &metav1.Time{Time: produced}
 			}
-			providerCIDRs.Insert(normalized)
 		}
+
+		if fraction, ok := fractionFor(fractions, label); ok {
+			total := len(cidrs)
+			cidrs = sampleCIDRs(cidrs, fraction)
+			warnings = append(warnings, fmt.Sprintf("provider %s sampled %d of %d CIDRs for testing (fraction %g)", label, len(cidrs), total, fraction))
+		}
+
+		if reporter, ok := provider.(providers.MetadataReporter); ok {
+			if metadata := reporter.LastMetadata(); len(metadata) > 0 {
+				logger.V(1).Info("captured CIDR metadata", "provider", label, "entries", metadata)
+			}
+		}
+
+		processed, err := r.processProviderCIDRs(resource, label, providerSpec, cidrs, logger)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("provider %s skipped: %v", label, err))
+			status.LastError = err.Error()
+			resource.Status.ProviderStatuses = append(resource.Status.ProviderStatuses, status)
+			continue
+		}
+		if len(processed.dropped) > 0 {
+			warnings = append(warnings, fmt.Sprintf("provider %s dropped %d CIDRs outside allowed supernets: %s", label, len(processed.dropped), summarizeCIDRs(processed.dropped)))
+		}
+		if len(processed.nonGlobal) > 0 {
+			warnings = append(warnings, fmt.Sprintf("provider %s dropped %d zoned non-global addresses: %s", label, len(processed.nonGlobal), summarizeCIDRs(processed.nonGlobal)))
+		}
+		family := ipFamilyFor(resource, providerSpec)
+		inFamily, otherFamily, malformed := filterCIDRs(processed.normalized, family)
+		if len(malformed) > 0 {
+			warnings = append(warnings, malformedCIDRsWarning(label, len(malformed), summarizeCIDRs(malformed)))
+		}
+		logOtherFamily(logger, label, family, otherFamily)
+		contributed, truncated := capCIDRs(inFamily, providerSpec.MaxCIDRs)
+		if truncated > 0 {
+			warnings = append(warnings, maxCIDRsWarning(label, providerSpec.MaxCIDRs, truncated))
+		}
+		for _, cidr := range contributed {
+			providerCIDRs.Insert(cidr)
+			status.CIDRCount++
+		}
+		providerTypeCIDRs.WithLabelValues(providerType(providerSpec)).Add(float64(len(contributed)))
+		resource.Status.ProviderStatuses = append(resource.Status.ProviderStatuses, status)
+		resource.Status.ProviderCount++
 	}
 
-	for _, cidr := range resource.Spec.CustomCIDRs {
-		providerCIDRs.Insert(strings.TrimSpace(cidr))
+	custom, rewritten, nonGlobal := normalizeHostBits(resource.Spec.CustomCIDRs)
+	r.logHostBits(logger, "customCidrs", rewritten)
+	if len(nonGlobal) > 0 {
+		warnings = append(warnings, fmt.Sprintf("customCidrs dropped %d zoned non-global addresses: %s", len(nonGlobal), summarizeCIDRs(nonGlobal)))
 	}
+	custom, otherFamily, malformed := filterCIDRs(custom, resource.Spec.IPFamily)
+	if len(malformed) > 0 {
+		warnings = append(warnings, malformedCIDRsWarning("customCidrs", len(malformed), summarizeCIDRs(malformed)))
+	}
+	logOtherFamily(logger, "customCidrs", resource.Spec.IPFamily, otherFamily)
+	providerCIDRs.Insert(custom...)
 
 	result := providerCIDRs.List()
 	sort.Strings(result)
+	if resource.Spec.AggregateCIDRsEnabled() {
+		collected := len(result)
+		result = aggregateCIDRs(result)
+		logger.V(1).Info("aggregated CIDRs", "before", collected, "after", len(result))
+	}
 	logger.Info("collected CIDRs", "count", len(result))
+	span.SetAttributes(attrCIDRCount.Int(len(result)))
 	return result, warnings, nil
 }
 
+// processProviderCIDRs filters a provider's fetched CIDRs to its allowed supernets and
+// clears host bits. When the fetched set hashes the same as on the previous sync of the
+// resource, the earlier result is reused and rewritten CIDRs are not logged again.
+func (r *BotNetworkPolicyReconciler) processProviderCIDRs(resource *botv1alpha1.BotNetworkPolicy, label string, providerSpec botv1alpha1.ProviderSpec, cidrs []string, logger logr.Logger) (providerResult, error) {
+	key := providerResultKey{object: client.ObjectKeyFromObject(resource), provider: label}
+	hash := hashCIDRs(cidrs, providerSpec.AllowedSupernets)
+	if cached, ok := r.results.get(key, hash); ok {
+		providerUnchangedTotal.WithLabelValues(label).Inc()
+		return cached, nil
+	}
+
+	var dropped []string
+	if len(providerSpec.AllowedSupernets) > 0 {
+		kept, filtered, err := providers.FilterSupernets(cidrs, providerSpec.AllowedSupernets)
+		if err != nil {
+			return providerResult{}, err
+		}
+		cidrs, dropped = kept, filtered
+	}
+	normalized, rewritten, nonGlobal := normalizeHostBits(cidrs)
+	r.logHostBits(logger, label, rewritten)
+
+	result := providerResult{hash: hash, normalized: normalized, dropped: dropped, nonGlobal: nonGlobal}
+	r.results.put(key, result)
+	return result, nil
+}
+
+// fetchErrorWarning returns the warning for a failed provider fetch. A well-formed but
+// empty feed and a fetch exceeding timeoutSeconds instead get their own EmptyFeed and
+// ProviderTimeout events, so alerting can tell them apart from a broken endpoint.
+func (r *BotNetworkPolicyReconciler) fetchErrorWarning(resource *botv1alpha1.BotNetworkPolicy, label string, err error, logger logr.Logger) []string {
+	if errors.Is(err, errProviderTimeout) {
+		logger.Info("provider fetch timed out", "provider", label, "error", err.Error())
+		if r.Recorder != nil {
+			r.Recorder.Event(resource, corev1.EventTypeWarning, ReasonProviderTimeout, fmt.Sprintf("provider %s skipped: %v", label, err))
+		}
+		return nil
+	}
+	if !errors.Is(err, providers.ErrEmptyFeed) {
+		return []string{fmt.Sprintf("provider %s fetch error: %v", label, err)}
+	}
+	logger.Info("provider feed is empty", "provider", label)
+	if r.Recorder != nil {
+		r.Recorder.Event(resource, corev1.EventTypeWarning, ReasonEmptyFeed, fmt.Sprintf("provider %s returned a valid feed with no CIDRs", label))
+	}
+	return nil
+}
+
+// fetchProvider fetches from a single provider, recording its duration and a trace span.
+func (r *BotNetworkPolicyReconciler) fetchProvider(ctx context.Context, label string, provider providers.Provider) ([]string, error) {
+	ctx, span := r.startSpan(ctx, "Fetch", attrProviderName.String(label))
+	start := time.Now()
+	cidrs, err := provider.Fetch(ctx)
+	providerFetchDuration.WithLabelValues(label).Observe(time.Since(start).Seconds())
+	if err != nil {
+		providerFetchErrors.WithLabelValues(label).Inc()
+	}
+	span.SetAttributes(attrCIDRCount.Int(len(cidrs)))
+	endSpan(span, err)
+	return cidrs, err
+}
+
+// logHostBits reports CIDRs whose host bits were cleared, at HostBitsLogLevel verbosity.
+func (r *BotNetworkPolicyReconciler) logHostBits(logger logr.Logger, source string, rewritten []string) {
+	if len(rewritten) == 0 {
+		return
+	}
+	logger.V(r.HostBitsLogLevel).Info("normalized CIDRs with host bits set", "source", source, "count", len(rewritten), "entries", rewritten)
+}
+
+// providerType returns the metric label for the type of spec, normalizing the case
+// variants the factory accepts.
+func providerType(spec botv1alpha1.ProviderSpec) string {
+	return strings.ToLower(spec.Name)
+}
+
+// capCIDRs returns at most limit distinct CIDRs, keeping the first in sorted order so the
+// same feed always yields the same subset, and how many were dropped. Zero or less keeps
+// every CIDR.
+func capCIDRs(cidrs []string, limit int) ([]string, int) {
+	if limit <= 0 || len(cidrs) <= limit {
+		return cidrs, 0
+	}
+	unique := sets.List(sets.New(cidrs...))
+	if len(unique) <= limit {
+		return unique, 0
+	}
+	return unique[:limit], len(unique) - limit
+}
+
+// malformedCIDRsWarning reports the entries of a source dropped for not being CIDRs.
+// source is a provider label or "customCidrs".
+func malformedCIDRsWarning(source string, count int, summary string) string {
+	if source != "customCidrs" {
+		source = "provider " + source
+	}
+	return fmt.Sprintf("%s dropped %d malformed CIDRs: %s", source, count, summary)
+}
+
+// logOtherFamily reports how many CIDRs of source were dropped for belonging to the
+// address family the policy does not use.
+func logOtherFamily(logger logr.Logger, source, family string, count int) {
+	if count == 0 {
+		return
+	}
+	logger.V(1).Info("dropped CIDRs outside the requested IP family", "source", source, "ipFamily", family, "count", count)
+}
+
+func maxCIDRsWarning(label string, limit, truncated int) string {
+	return fmt.Sprintf("provider %s exceeded maxCidrs %d; dropped %d CIDRs", label, limit, truncated)
+}
+
+// summarizeCIDRs joins the first few CIDRs for use in a warning message.
+func summarizeCIDRs(cidrs []string) string {
+	const limit = 5
+	if len(cidrs) <= limit {
+		return strings.Join(cidrs, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(cidrs[:limit], ", "), len(cidrs)-limit)
+}
+
 func (r *BotNetworkPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &botv1alpha1.BotNetworkPolicy{}, configMapRefIndex, indexConfigMapRefs); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &botv1alpha1.BotNetworkPolicy{}, secretRefIndex, indexSecretRefs); err != nil {
+		return err
+	}
+	// Status updates, which every sync writes, do not change the generation; reacting to
+	// them would reconcile each object again right after it synced.
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&botv1alpha1.BotNetworkPolicy{}).
+		For(&botv1alpha1.BotNetworkPolicy{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
 		Owns(&networkingv1.NetworkPolicy{}).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.policiesForConfigMap), builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Watches(&corev1.Secret{}, r.secretEventHandler(), builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Complete(r)
 }
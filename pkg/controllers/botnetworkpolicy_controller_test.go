@@ -1,14 +1,142 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+	"github.com/sugaf1204/botnetworkpolicy-operator/pkg/providers"
 )
 
+// newTestScheme returns a scheme with the core, networking and BotNetworkPolicy types.
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, networkingv1.AddToScheme, botv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("register types: %v", err)
+		}
+	}
+	return scheme
+}
+
+// newTestClientBuilder returns a fake client builder seeded with objs that serves the
+// BotNetworkPolicy status subresource and emulates server-side apply. Tests adding their
+// own interceptors set Patch to fakeServerSideApply as well.
+func newTestClientBuilder(t *testing.T, objs ...client.Object) *fake.ClientBuilder {
+	t.Helper()
+	return fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(objs...).
+		WithStatusSubresource(&botv1alpha1.BotNetworkPolicy{}).
+		WithInterceptorFuncs(interceptor.Funcs{Patch: fakeServerSideApply})
+}
+
+// fakeServerSideApply emulates a forced server-side apply of a NetworkPolicy, which the
+// fake client rejects. As for the only field manager, the applied labels, annotations,
+// owner references and spec replace the stored ones, and an apply that changes nothing
+// keeps the resourceVersion. Other patches pass through.
+func fakeServerSideApply(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	policy, ok := obj.(*networkingv1.NetworkPolicy)
+	if !ok || patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	var applied networkingv1.NetworkPolicy
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return err
+	}
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	dryRun := len(patchOpts.DryRun) > 0
+
+	var stored networkingv1.NetworkPolicy
+	err = c.Get(ctx, client.ObjectKeyFromObject(&applied), &stored)
+	switch {
+	case apierrors.IsNotFound(err):
+		stored = applied
+		if !dryRun {
+			if err := c.Create(ctx, &stored); err != nil {
+				return err
+			}
+		}
+	case err != nil:
+		return err
+	default:
+		merged := stored.DeepCopy()
+		merged.Labels, merged.Annotations = applied.Labels, applied.Annotations
+		merged.OwnerReferences, merged.Spec = applied.OwnerReferences, applied.Spec
+		if !dryRun && !equality.Semantic.DeepEqual(merged, &stored) {
+			if err := c.Update(ctx, merged); err != nil {
+				return err
+			}
+		}
+		stored = *merged
+	}
+	stored.DeepCopyInto(policy)
+	return nil
+}
+
+// newTestReconciler returns a reconciler backed by a fake client seeded with objs.
+func newTestReconciler(t *testing.T, objs ...client.Object) *BotNetworkPolicyReconciler {
+	t.Helper()
+	return reconcilerFor(newTestClientBuilder(t, objs...).Build())
+}
+
+// reconcilerFor returns a reconciler using kubeClient and its scheme. Its recorder is a
+// record.FakeRecorder with room for every event a test reconcile emits.
+func reconcilerFor(kubeClient client.Client) *BotNetworkPolicyReconciler {
+	return &BotNetworkPolicyReconciler{Client: kubeClient, Scheme: kubeClient.Scheme(), Recorder: record.NewFakeRecorder(100)}
+}
+
+// newResource returns a BotNetworkPolicy named sample in the default namespace that
+// allows 10.0.0.0/24 to pods labelled app=web.
+func newResource() *botv1alpha1.BotNetworkPolicy {
+	return &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			CustomCIDRs: []string{"10.0.0.0/24"},
+		},
+	}
+}
+
 func TestBuildNetworkPolicy(t *testing.T) {
 	ingress := true
 	resource := &botv1alpha1.BotNetworkPolicy{
@@ -23,7 +151,7 @@ func TestBuildNetworkPolicy(t *testing.T) {
 	}
 
 	cidrs := []string{"10.0.0.0/24"}
-	np := buildNetworkPolicy(resource, cidrs)
+	np := buildNetworkPolicy(resource, sharedCIDRs(cidrs))
 
 	if np.Name != "sample-allow-bots" {
 		t.Fatalf("unexpected name: %s", np.Name)
@@ -38,3 +166,2181 @@ func TestBuildNetworkPolicy(t *testing.T) {
 		t.Fatalf("unexpected policy types: %#v", np.Spec.PolicyTypes)
 	}
 }
+
+func TestBuildNetworkPolicy_Ports(t *testing.T) {
+	egress := true
+	tcp := corev1.ProtocolTCP
+	https := intstr.FromInt32(443)
+	resource := newResource()
+	resource.Spec.Egress = &egress
+	resource.Spec.Ports = []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &https}}
+
+	np := buildNetworkPolicy(resource, sharedCIDRs([]string{"10.0.0.0/24"}))
+	if len(np.Spec.Ingress) != 1 || len(np.Spec.Egress) != 1 {
+		t.Fatalf("rules = %#v / %#v, want one ingress and one egress rule", np.Spec.Ingress, np.Spec.Egress)
+	}
+	for direction, ports := range map[string][]networkingv1.NetworkPolicyPort{
+		"ingress": np.Spec.Ingress[0].Ports,
+		"egress":  np.Spec.Egress[0].Ports,
+	} {
+		if len(ports) != 1 || *ports[0].Protocol != corev1.ProtocolTCP || ports[0].Port.IntValue() != 443 {
+			t.Errorf("%s ports = %#v, want TCP 443", direction, ports)
+		}
+	}
+	if np.Spec.Ingress[0].Ports[0].Port == resource.Spec.Ports[0].Port {
+		t.Error("rule ports share memory with the spec")
+	}
+
+	resource.Spec.Ports = nil
+	np = buildNetworkPolicy(resource, sharedCIDRs([]string{"10.0.0.0/24"}))
+	if np.Spec.Ingress[0].Ports != nil || np.Spec.Egress[0].Ports != nil {
+		t.Errorf("ports without spec ports = %#v / %#v, want all ports", np.Spec.Ingress[0].Ports, np.Spec.Egress[0].Ports)
+	}
+}
+
+func TestCollectDirectionalCIDRs(t *testing.T) {
+	configMap := func(name, cidrs string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string]string{"cidrs": cidrs},
+		}
+	}
+	providerFor := func(name string) botv1alpha1.ProviderSpec {
+		return botv1alpha1.ProviderSpec{
+			Name:      "configMap",
+			ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: name, Key: "cidrs"},
+		}
+	}
+
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(
+			configMap("shared", "10.0.0.0/24"),
+			configMap("crawlers", "66.249.64.0/19"),
+			configMap("upstreams", "203.0.113.0/24"),
+		).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+
+	ingress, egress := true, true
+	resource := newResource()
+	resource.Spec.Ingress = &ingress
+	resource.Spec.Egress = &egress
+	resource.Spec.Providers = []botv1alpha1.ProviderSpec{providerFor("shared")}
+	resource.Spec.IngressProviders = []botv1alpha1.ProviderSpec{providerFor("crawlers")}
+	resource.Spec.EgressProviders = []botv1alpha1.ProviderSpec{providerFor("upstreams")}
+	resource.Spec.CustomCIDRs = nil
+
+	cidrs, warnings, err := reconciler.collectDirectionalCIDRs(context.Background(), resource, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectDirectionalCIDRs() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	np := buildNetworkPolicy(resource, cidrs)
+	if len(np.Spec.Ingress) != 1 || len(np.Spec.Ingress[0].From) != 1 || np.Spec.Ingress[0].From[0].IPBlock.CIDR != "66.249.64.0/19" {
+		t.Errorf("unexpected ingress peers: %#v", np.Spec.Ingress)
+	}
+	if len(np.Spec.Egress) != 1 || len(np.Spec.Egress[0].To) != 1 || np.Spec.Egress[0].To[0].IPBlock.CIDR != "203.0.113.0/24" {
+		t.Errorf("unexpected egress peers: %#v", np.Spec.Egress)
+	}
+
+	// Without an egress override, egress falls back to the shared providers.
+	resource.Spec.EgressProviders = nil
+	cidrs, _, err = reconciler.collectDirectionalCIDRs(context.Background(), resource, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectDirectionalCIDRs() error = %v", err)
+	}
+	if len(cidrs.Egress) != 1 || cidrs.Egress[0] != "10.0.0.0/24" {
+		t.Errorf("egress CIDRs = %v, want shared [10.0.0.0/24]", cidrs.Egress)
+	}
+	if len(cidrs.Ingress) != 1 || cidrs.Ingress[0] != "66.249.64.0/19" {
+		t.Errorf("ingress CIDRs = %v, want override [66.249.64.0/19]", cidrs.Ingress)
+	}
+}
+
+func TestReconcile_ReadyAfterFirstSuccessfulSync(t *testing.T) {
+	resource := newResource()
+	// A NetworkPolicy not owned by the resource blocks the first apply.
+	conflicting := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-allow-bots", Namespace: "default"},
+	}
+
+	reconciler := newTestReconciler(t, resource, conflicting)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err == nil {
+		t.Fatal("expected reconcile to fail while the conflicting NetworkPolicy exists")
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if !meta.IsStatusConditionFalse(current.Status.Conditions, botv1alpha1.ConditionReady) {
+		t.Fatalf("expected Ready=False before the first successful sync, got %#v", current.Status.Conditions)
+	}
+	if current.Status.LastSyncTime != nil {
+		t.Errorf("expected no lastSyncTime before the first successful sync")
+	}
+
+	if err := kubeClient.Delete(ctx, conflicting); err != nil {
+		t.Fatalf("delete conflicting policy: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(current.Status.Conditions, botv1alpha1.ConditionReady) {
+		t.Fatalf("expected Ready=True after the first successful sync, got %#v", current.Status.Conditions)
+	}
+	if current.Status.LastSyncTime == nil {
+		t.Errorf("expected lastSyncTime to be recorded")
+	}
+}
+
+func TestReconcile_ReturnsStatusErrorOnApplyFailure(t *testing.T) {
+	resource := newResource()
+	conflicting := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-allow-bots", Namespace: "default"},
+	}
+	kubeClient := newTestClientBuilder(t, resource, conflicting).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				ready := meta.FindStatusCondition(obj.(*botv1alpha1.BotNetworkPolicy).Status.Conditions, botv1alpha1.ConditionReady)
+				if ready != nil && ready.Reason == ReasonPolicyApplyFailed {
+					return errors.New("status write rejected")
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	_, err := reconciler.Reconcile(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "status write rejected") || !strings.Contains(err.Error(), "sample-allow-bots") {
+		t.Errorf("Reconcile() error = %v, want both the apply and the status write failure", err)
+	}
+}
+
+func TestSplitCIDRs_PartitionsExactly(t *testing.T) {
+	for count := 0; count <= 40; count++ {
+		input := make([]string, count)
+		for i := range input {
+			input[i] = fmt.Sprintf("10.0.%d.0/24", i)
+		}
+		for limit := -1; limit <= 12; limit++ {
+			chunks := splitCIDRs(input, limit)
+
+			seen := map[string]int{}
+			total := 0
+			for _, chunk := range chunks {
+				if len(chunk) == 0 {
+					t.Fatalf("count=%d limit=%d: empty chunk", count, limit)
+				}
+				if limit > 0 && len(chunk) > limit {
+					t.Fatalf("count=%d limit=%d: chunk of %d exceeds limit", count, limit, len(chunk))
+				}
+				for _, cidr := range chunk {
+					seen[cidr]++
+					total++
+				}
+			}
+			if total != count {
+				t.Fatalf("count=%d limit=%d: splits hold %d entries", count, limit, total)
+			}
+			for _, cidr := range input {
+				if seen[cidr] != 1 {
+					t.Fatalf("count=%d limit=%d: %s appears %d times", count, limit, cidr, seen[cidr])
+				}
+			}
+
+			wantChunks := 0
+			switch {
+			case count == 0:
+			case limit <= 0:
+				wantChunks = 1
+			default:
+				wantChunks = (count + limit - 1) / limit
+			}
+			if len(chunks) != wantChunks {
+				t.Fatalf("count=%d limit=%d: got %d chunks, want %d", count, limit, len(chunks), wantChunks)
+			}
+		}
+	}
+}
+
+func TestReconcile_SplitPoliciesPruneOnShrink(t *testing.T) {
+	resource := newResource()
+	resource.Spec.CustomCIDRs = []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24", "10.0.4.0/24"}
+	resource.Spec.MaxPeersPerPolicy = 2
+	reconciler := newTestReconciler(t, resource)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	collected := func() map[string]int {
+		var list networkingv1.NetworkPolicyList
+		if err := kubeClient.List(ctx, &list); err != nil {
+			t.Fatalf("list policies: %v", err)
+		}
+		cidrs := map[string]int{}
+		for _, np := range list.Items {
+			for _, rule := range np.Spec.Ingress {
+				for _, peer := range rule.From {
+					cidrs[peer.IPBlock.CIDR]++
+				}
+			}
+		}
+		return cidrs
+	}
+
+	var list networkingv1.NetworkPolicyList
+	_ = kubeClient.List(ctx, &list)
+	if len(list.Items) != 3 {
+		t.Fatalf("expected 3 split policies, got %d", len(list.Items))
+	}
+	if cidrs := collected(); len(cidrs) != 5 {
+		t.Fatalf("expected 5 distinct CIDRs across policies, got %v", cidrs)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	_ = kubeClient.Get(ctx, req.NamespacedName, &current)
+	current.Spec.CustomCIDRs = current.Spec.CustomCIDRs[:3]
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	_ = kubeClient.List(ctx, &list)
+	if len(list.Items) != 2 {
+		t.Fatalf("expected stale split policy to be pruned, got %d policies", len(list.Items))
+	}
+	cidrs := collected()
+	if len(cidrs) != 3 {
+		t.Fatalf("expected 3 CIDRs after shrink, got %v", cidrs)
+	}
+	for cidr, n := range cidrs {
+		if n != 1 {
+			t.Errorf("%s appears in %d policies", cidr, n)
+		}
+	}
+}
+
+func TestReconcile_WarnOnEmptySelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		podLabel string
+		wantWarn bool
+	}{
+		{name: "no matching pods", podLabel: "other", wantWarn: true},
+		{name: "matching pod", podLabel: "web", wantWarn: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := newResource()
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", Labels: map[string]string{"app": tt.podLabel}},
+			}
+			reconciler := newTestReconciler(t, resource, pod)
+			recorder := reconciler.Recorder.(*record.FakeRecorder)
+			reconciler.WarnOnEmptySelector = true
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+			if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			warned := false
+			for len(recorder.Events) > 0 {
+				if strings.Contains(<-recorder.Events, "NoMatchingPods") {
+					warned = true
+				}
+			}
+			if warned != tt.wantWarn {
+				t.Errorf("NoMatchingPods warning emitted = %v, want %v", warned, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestReconcile_ProviderDisplayName(t *testing.T) {
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Providers: []botv1alpha1.ProviderSpec{
+				{
+					Name:        "configMap",
+					DisplayName: "partner-a",
+					ConfigMap:   &botv1alpha1.ConfigMapProviderSpec{Name: "partner-a", Key: "cidrs"},
+				},
+				{
+					Name:        "configMap",
+					DisplayName: "partner-b",
+					ConfigMap:   &botv1alpha1.ConfigMapProviderSpec{Name: "missing", Key: "cidrs"},
+				},
+			},
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "partner-a", Namespace: "default"},
+		Data:       map[string]string{"cidrs": "10.0.0.0/24,10.0.1.0/24"},
+	}
+	reconciler := newTestReconciler(t, resource, configMap)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	statuses := current.Status.ProviderStatuses
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 provider statuses, got %#v", statuses)
+	}
+	if statuses[0].DisplayName != "partner-a" || statuses[0].CIDRCount != 2 || statuses[0].LastError != "" {
+		t.Errorf("unexpected status for partner-a: %#v", statuses[0])
+	}
+	if statuses[1].DisplayName != "partner-b" || statuses[1].LastError == "" {
+		t.Errorf("unexpected status for partner-b: %#v", statuses[1])
+	}
+	if current.Status.ProviderCount != 1 {
+		t.Errorf("providerCount = %d, want 1", current.Status.ProviderCount)
+	}
+
+	warned := false
+	for len(recorder.Events) > 0 {
+		if strings.Contains(<-recorder.Events, "provider partner-b fetch error") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Error("expected warning event naming partner-b")
+	}
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	labels := map[string]bool{}
+	for _, family := range families {
+		if family.GetName() != "botnp_provider_fetch_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "provider" {
+					labels[pair.GetValue()] = true
+				}
+			}
+		}
+	}
+	if !labels["partner-a"] || !labels["partner-b"] {
+		t.Errorf("expected provider metric labels for partner-a and partner-b, got %v", labels)
+	}
+}
+
+func TestReconcile_StartupJitterStaggersFirstSync(t *testing.T) {
+	const count = 10
+	objs := make([]client.Object, 0, count)
+	for i := 0; i < count; i++ {
+		resource := newResource()
+		resource.Name = fmt.Sprintf("sample-%d", i)
+		objs = append(objs, resource)
+	}
+	jitter := 30 * time.Second
+	reconciler := newTestReconciler(t, objs...)
+	reconciler.StartupJitter = jitter
+	kubeClient := reconciler.Client
+
+	delays := make(map[time.Duration]struct{})
+	for i := 0; i < count; i++ {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: fmt.Sprintf("sample-%d", i), Namespace: "default"}}
+		result, err := reconciler.Reconcile(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if result.RequeueAfter <= 0 || result.RequeueAfter > jitter {
+			t.Fatalf("first RequeueAfter = %v, want within (0, %v]", result.RequeueAfter, jitter)
+		}
+		delays[result.RequeueAfter] = struct{}{}
+	}
+	if len(delays) < 2 {
+		t.Errorf("first reconciles all requeued after the same delay %v, want staggered delays", delays)
+	}
+
+	var policies networkingv1.NetworkPolicyList
+	if err := kubeClient.List(context.Background(), &policies); err != nil {
+		t.Fatalf("list network policies: %v", err)
+	}
+	if len(policies.Items) != 0 {
+		t.Errorf("got %d NetworkPolicies before the delayed first sync, want 0", len(policies.Items))
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample-0", Namespace: "default"}}
+	result, err := reconciler.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("second Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != providers.DefaultSyncPeriod {
+		t.Errorf("second RequeueAfter = %v, want the sync period %v", result.RequeueAfter, providers.DefaultSyncPeriod)
+	}
+}
+
+func TestCollectCIDRs_AllowedSupernets(t *testing.T) {
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "partner", Namespace: "default"},
+			Data:       map[string]string{"cidrs": "13.32.0.0/15\n52.94.0.0/16\n13.248.0.0/14"},
+		}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+	}
+	specs := []botv1alpha1.ProviderSpec{{
+		Name:             "configMap",
+		ConfigMap:        &botv1alpha1.ConfigMapProviderSpec{Name: "partner", Key: "cidrs"},
+		AllowedSupernets: []string{"13.0.0.0/8"},
+	}}
+
+	cidrs, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	want := []string{"13.248.0.0/14", "13.32.0.0/15"}
+	if strings.Join(cidrs, ",") != strings.Join(want, ",") {
+		t.Errorf("collectCIDRs() = %v, want %v", cidrs, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "52.94.0.0/16") {
+		t.Errorf("warnings = %v, want one warning naming the dropped CIDR", warnings)
+	}
+	if got := resource.Status.ProviderStatuses[0].CIDRCount; got != 2 {
+		t.Errorf("provider CIDRCount = %d, want 2", got)
+	}
+}
+
+func TestCollectCIDRs_RecordsDataTimestamp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", "Fri, 01 Mar 2024 12:00:00 GMT")
+		w.Write([]byte(`{"createDate":"2024-02-15-08-30-00","prefixes":[{"ip_prefix":"3.5.140.0/22"}]}`))
+	}))
+	defer server.Close()
+
+	reconciler := &BotNetworkPolicyReconciler{HTTPClient: server.Client()}
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+	}
+	specs := []botv1alpha1.ProviderSpec{{Name: "aws", AWS: &botv1alpha1.AWSProviderSpec{URL: server.URL}}}
+
+	if _, _, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard()); err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	got := resource.Status.ProviderStatuses[0].DataTimestamp
+	want := time.Date(2024, 2, 15, 8, 30, 0, 0, time.UTC)
+	if got == nil || !got.Time.Equal(want) {
+		t.Errorf("provider DataTimestamp = %v, want %v", got, want)
+	}
+}
+
+func TestCollectCIDRs_RecordsFeedVersion(t *testing.T) {
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "partner", Namespace: "default"},
+			Data:       map[string]string{"cidrs": "# sha256: 9f86d081884c\n192.0.2.0/24"},
+		}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+	}
+	specs := []botv1alpha1.ProviderSpec{{
+		Name:      "configMap",
+		ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner", Key: "cidrs", VersionCommentPrefix: "# sha256:"},
+	}}
+
+	cidrs, _, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	if strings.Join(cidrs, ",") != "192.0.2.0/24" {
+		t.Errorf("collectCIDRs() = %v, want [192.0.2.0/24]", cidrs)
+	}
+	if got := resource.Status.ProviderStatuses[0].FeedVersion; got != "9f86d081884c" {
+		t.Errorf("provider FeedVersion = %q, want 9f86d081884c", got)
+	}
+}
+
+func TestReconcile_BaselineViolation(t *testing.T) {
+	resource := newResource()
+	resource.Spec.BaselinePolicyRef = &botv1alpha1.BaselinePolicyReference{Name: "mandatory"}
+	baseline := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "mandatory", Namespace: "default"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{
+					{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/24"}},
+					{IPBlock: &networkingv1.IPBlock{CIDR: "192.0.2.0/24"}},
+				},
+			}},
+		},
+	}
+
+	reconciler := newTestReconciler(t, resource, baseline)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	condition := meta.FindStatusCondition(current.Status.Conditions, botv1alpha1.ConditionBaselineViolation)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected BaselineViolation=True, got %#v", current.Status.Conditions)
+	}
+	if !strings.Contains(condition.Message, "192.0.2.0/24") || strings.Contains(condition.Message, "10.0.0.0/24") {
+		t.Errorf("BaselineViolation message = %q, want only the omitted CIDR", condition.Message)
+	}
+
+	// Once the omitted CIDR is added, the violation clears.
+	current.Spec.CustomCIDRs = append(current.Spec.CustomCIDRs, "192.0.2.0/24")
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if !meta.IsStatusConditionFalse(current.Status.Conditions, botv1alpha1.ConditionBaselineViolation) {
+		t.Errorf("expected BaselineViolation=False once satisfied, got %#v", current.Status.Conditions)
+	}
+}
+
+func TestReconcile_BaselineSatisfiedByAggregatedCIDRs(t *testing.T) {
+	aggregate := true
+	resource := newResource()
+	resource.Spec.AggregateCIDRs = &aggregate
+	resource.Spec.CustomCIDRs = []string{"10.0.0.0/25", "10.0.0.128/25"}
+	resource.Spec.BaselinePolicyRef = &botv1alpha1.BaselinePolicyReference{Name: "mandatory"}
+	baseline := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "mandatory", Namespace: "default"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{
+					{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.128/25"}},
+					{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.7/32"}},
+				},
+			}},
+		},
+	}
+
+	reconciler := newTestReconciler(t, resource, baseline)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var policy networkingv1.NetworkPolicy
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}, &policy); err != nil {
+		t.Fatalf("get networkpolicy: %v", err)
+	}
+	if from := policy.Spec.Ingress[0].From; len(from) != 1 || from[0].IPBlock.CIDR != "10.0.0.0/24" {
+		t.Fatalf("ingress peers = %#v, want the aggregated 10.0.0.0/24", from)
+	}
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if !meta.IsStatusConditionFalse(current.Status.Conditions, botv1alpha1.ConditionBaselineViolation) {
+		t.Errorf("expected BaselineViolation=False for baseline CIDRs within the aggregate, got %#v", current.Status.Conditions)
+	}
+}
+
+func TestReconcile_BaselineViolationFromExcept(t *testing.T) {
+	resource := newResource()
+	resource.Spec.ExceptCIDRs = []string{"10.0.0.128/25"}
+	resource.Spec.BaselinePolicyRef = &botv1alpha1.BaselinePolicyReference{Name: "mandatory"}
+	baseline := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "mandatory", Namespace: "default"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{
+					{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/25"}},
+					{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.200/32"}},
+				},
+			}},
+		},
+	}
+
+	reconciler := newTestReconciler(t, resource, baseline)
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := reconciler.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	condition := meta.FindStatusCondition(current.Status.Conditions, botv1alpha1.ConditionBaselineViolation)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected BaselineViolation=True, got %#v", current.Status.Conditions)
+	}
+	if !strings.Contains(condition.Message, "10.0.0.200/32") || strings.Contains(condition.Message, "10.0.0.0/25") {
+		t.Errorf("BaselineViolation message = %q, want only the excepted CIDR", condition.Message)
+	}
+}
+
+func TestReconcile_CustomFinalizerName(t *testing.T) {
+	const finalizer = "example.com/bot-policy-cleanup"
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec:       botv1alpha1.BotNetworkPolicySpec{CustomCIDRs: []string{"10.0.0.0/24"}},
+	}
+	reconciler := newTestReconciler(t, resource)
+	reconciler.FinalizerName = finalizer
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if len(current.Finalizers) != 1 || current.Finalizers[0] != finalizer {
+		t.Fatalf("finalizers = %v, want [%s]", current.Finalizers, finalizer)
+	}
+
+	if err := kubeClient.Delete(ctx, &current); err != nil {
+		t.Fatalf("delete resource: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() on deletion error = %v", err)
+	}
+
+	var policies networkingv1.NetworkPolicyList
+	if err := kubeClient.List(ctx, &policies); err != nil {
+		t.Fatalf("list network policies: %v", err)
+	}
+	if len(policies.Items) != 0 {
+		t.Errorf("got %d NetworkPolicies after deletion, want 0", len(policies.Items))
+	}
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); !apierrors.IsNotFound(err) {
+		t.Errorf("expected resource to be gone once the finalizer was removed, got err=%v finalizers=%v", err, current.Finalizers)
+	}
+}
+
+func TestReconcile_RenamedPolicyIsDeleted(t *testing.T) {
+	resource := newResource()
+	reconciler := newTestReconciler(t, resource)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	policyNames := func() []string {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		var policies networkingv1.NetworkPolicyList
+		if err := kubeClient.List(ctx, &policies); err != nil {
+			t.Fatalf("list network policies: %v", err)
+		}
+		names := make([]string, 0, len(policies.Items))
+		for _, policy := range policies.Items {
+			names = append(names, policy.Name)
+		}
+		return names
+	}
+	if got := policyNames(); len(got) != 1 || got[0] != "sample-allow-bots" {
+		t.Fatalf("policies = %v, want [sample-allow-bots]", got)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	current.Annotations = map[string]string{"bot.networking.dev/networkpolicy-name": "crawlers"}
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("rename policy: %v", err)
+	}
+	if got := policyNames(); len(got) != 1 || got[0] != "crawlers" {
+		t.Errorf("policies after rename = %v, want only [crawlers]", got)
+	}
+}
+
+func TestReconcile_MaxProviders(t *testing.T) {
+	configMapProvider := botv1alpha1.ProviderSpec{
+		Name:      "configMap",
+		ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "allowlist", Key: "cidrs"},
+	}
+	tests := []struct {
+		name      string
+		providers int
+		wantReady bool
+	}{
+		{name: "over the limit", providers: 3, wantReady: false},
+		{name: "at the limit", providers: 2, wantReady: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs := make([]botv1alpha1.ProviderSpec, tt.providers)
+			for i := range specs {
+				specs[i] = configMapProvider
+			}
+			resource := newResource()
+			resource.Spec.Providers = specs
+			resource.Spec.CustomCIDRs = nil
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "allowlist", Namespace: "default"},
+				Data:       map[string]string{"cidrs": "10.0.0.0/24"},
+			}
+			reconciler := newTestReconciler(t, resource, configMap)
+			reconciler.MaxProviders = 2
+			kubeClient := reconciler.Client
+
+			ctx := context.Background()
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+			if _, err := reconciler.Reconcile(ctx, req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			var current botv1alpha1.BotNetworkPolicy
+			if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+				t.Fatalf("get resource: %v", err)
+			}
+			ready := meta.FindStatusCondition(current.Status.Conditions, botv1alpha1.ConditionReady)
+			if ready == nil {
+				t.Fatal("expected a Ready condition")
+			}
+			if got := ready.Status == metav1.ConditionTrue; got != tt.wantReady {
+				t.Errorf("Ready = %s (%s), want ready=%v", ready.Status, ready.Reason, tt.wantReady)
+			}
+			if !tt.wantReady && ready.Reason != "TooManyProviders" {
+				t.Errorf("Ready reason = %q, want TooManyProviders", ready.Reason)
+			}
+		})
+	}
+}
+
+func TestBuildNetworkPolicies_PeerPodSelector(t *testing.T) {
+	ingress, egress := true, true
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			Ingress:           &ingress,
+			Egress:            &egress,
+			PeerPodSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "monitoring"}},
+			MaxPeersPerPolicy: 2,
+		},
+	}
+	cidrs := sharedCIDRs([]string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"})
+
+	policies := buildNetworkPolicies(resource, cidrs)
+	if len(policies) != 2 {
+		t.Fatalf("got %d policies, want 2", len(policies))
+	}
+
+	countKinds := func(peers []networkingv1.NetworkPolicyPeer) (ipBlocks, podSelectors int) {
+		for _, peer := range peers {
+			if peer.IPBlock != nil && peer.PodSelector != nil {
+				t.Errorf("peer combines an IPBlock with a pod selector: %#v", peer)
+			}
+			if peer.IPBlock != nil {
+				ipBlocks++
+			}
+			if peer.PodSelector != nil {
+				podSelectors++
+				if peer.PodSelector.MatchLabels["app"] != "monitoring" {
+					t.Errorf("unexpected pod selector peer: %#v", peer.PodSelector)
+				}
+			}
+		}
+		return ipBlocks, podSelectors
+	}
+
+	first := policies[0]
+	if ip, pods := countKinds(first.Spec.Ingress[0].From); ip != 2 || pods != 1 {
+		t.Errorf("first ingress rule has %d IPBlock and %d pod selector peers, want 2 and 1", ip, pods)
+	}
+	if ip, pods := countKinds(first.Spec.Egress[0].To); ip != 2 || pods != 1 {
+		t.Errorf("first egress rule has %d IPBlock and %d pod selector peers, want 2 and 1", ip, pods)
+	}
+	if ip, pods := countKinds(policies[1].Spec.Ingress[0].From); ip != 1 || pods != 0 {
+		t.Errorf("second ingress rule has %d IPBlock and %d pod selector peers, want 1 and 0", ip, pods)
+	}
+}
+
+func TestBuildNetworkPolicy_NamespaceSelectorPeer(t *testing.T) {
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "bots"}},
+		},
+	}
+
+	policy := buildNetworkPolicy(resource, sharedCIDRs([]string{"192.0.2.0/24"}))
+	peers := policy.Spec.Ingress[0].From
+	if len(peers) != 2 {
+		t.Fatalf("got %d ingress peers, want an IPBlock and a namespace selector peer", len(peers))
+	}
+	if peers[0].IPBlock == nil || peers[0].NamespaceSelector != nil {
+		t.Errorf("first peer = %#v, want an IPBlock only", peers[0])
+	}
+	if peers[1].IPBlock != nil || peers[1].PodSelector != nil || peers[1].NamespaceSelector.MatchLabels["team"] != "bots" {
+		t.Errorf("second peer = %#v, want the namespace selector only", peers[1])
+	}
+}
+
+func TestReconcile_NoSourcesCondition(t *testing.T) {
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec:       botv1alpha1.BotNetworkPolicySpec{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}
+	reconciler := newTestReconciler(t, resource)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(current.Status.Conditions, botv1alpha1.ConditionNoSources) {
+		t.Fatalf("expected NoSources=True, got %#v", current.Status.Conditions)
+	}
+	if !meta.IsStatusConditionTrue(current.Status.Conditions, botv1alpha1.ConditionReady) {
+		t.Errorf("expected NoSources to be non-fatal, got %#v", current.Status.Conditions)
+	}
+
+	current.Spec.CustomCIDRs = []string{"10.0.0.0/24"}
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if !meta.IsStatusConditionFalse(current.Status.Conditions, botv1alpha1.ConditionNoSources) {
+		t.Errorf("expected NoSources=False once a source is added, got %#v", current.Status.Conditions)
+	}
+}
+
+func TestCollectCIDRs_HostBitsLogLevel(t *testing.T) {
+	kubeClient := newTestClientBuilder(t, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "partner", Namespace: "default"},
+		Data:       map[string]string{"cidrs": "203.0.113.7/24\n198.51.100.0/24"},
+	}).Build()
+	specs := []botv1alpha1.ProviderSpec{{
+		Name:      "configMap",
+		ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner", Key: "cidrs"},
+	}}
+
+	collect := func(level, verbosity int) ([]string, []string) {
+		var lines []string
+		logger := funcr.New(func(prefix, args string) {
+			if strings.Contains(args, "host bits") {
+				lines = append(lines, args)
+			}
+		}, funcr.Options{Verbosity: verbosity})
+		reconciler := reconcilerFor(kubeClient)
+		reconciler.HostBitsLogLevel = level
+		resource := &botv1alpha1.BotNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+			Spec:       botv1alpha1.BotNetworkPolicySpec{CustomCIDRs: []string{"192.0.2.1/32"}},
+		}
+		cidrs, _, err := reconciler.collectCIDRs(context.Background(), resource, specs, logger)
+		if err != nil {
+			t.Fatalf("collectCIDRs() error = %v", err)
+		}
+		return cidrs, lines
+	}
+
+	cidrs, lines := collect(0, 0)
+	want := []string{"192.0.2.1/32", "198.51.100.0/24", "203.0.113.0/24"}
+	if strings.Join(cidrs, ",") != strings.Join(want, ",") {
+		t.Errorf("collectCIDRs() = %v, want %v", cidrs, want)
+	}
+	if len(lines) != 1 || !strings.Contains(lines[0], "203.0.113.7/24 -> 203.0.113.0/24") {
+		t.Errorf("logged %v, want one entry naming the normalized CIDR", lines)
+	}
+
+	if _, lines := collect(1, 0); len(lines) != 0 {
+		t.Errorf("logged %v at verbosity 0 with HostBitsLogLevel 1, want nothing", lines)
+	}
+	if _, lines := collect(1, 1); len(lines) != 1 {
+		t.Errorf("logged %v at verbosity 1 with HostBitsLogLevel 1, want one entry", lines)
+	}
+}
+
+func TestCollectCIDRs_IPv6Zones(t *testing.T) {
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "partner", Namespace: "default"},
+			Data:       map[string]string{"cidrs": "2001:db8:1::7%eth0/48\nfe80::1%eth0/64\n2001:db8:2::1%1\n198.51.100.0/24"},
+		}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	resource := &botv1alpha1.BotNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"}}
+	specs := []botv1alpha1.ProviderSpec{{
+		Name:      "configMap",
+		ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner", Key: "cidrs"},
+	}}
+
+	cidrs, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	want := []string{"198.51.100.0/24", "2001:db8:1::/48", "2001:db8:2::1/128"}
+	if strings.Join(cidrs, ",") != strings.Join(want, ",") {
+		t.Errorf("collectCIDRs() = %v, want %v", cidrs, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "dropped 1 zoned non-global addresses: fe80::1%eth0/64") {
+		t.Errorf("warnings = %v, want one naming the link-local address", warnings)
+	}
+}
+
+func TestCollectCIDRs_MaxCIDRs(t *testing.T) {
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "partner", Namespace: "default"},
+			Data:       map[string]string{"cidrs": "203.0.113.0/24\n192.0.2.0/24\n198.51.100.0/24\n192.0.2.0/24"},
+		}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec:       botv1alpha1.BotNetworkPolicySpec{CustomCIDRs: []string{"10.0.0.0/8"}},
+	}
+	specs := []botv1alpha1.ProviderSpec{{
+		Name:      "configMap",
+		ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner", Key: "cidrs"},
+		MaxCIDRs:  2,
+	}}
+
+	cidrs, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	want := []string{"10.0.0.0/8", "192.0.2.0/24", "198.51.100.0/24"}
+	if strings.Join(cidrs, ",") != strings.Join(want, ",") {
+		t.Errorf("collectCIDRs() = %v, want %v", cidrs, want)
+	}
+	if len(warnings) != 1 || warnings[0] != "provider configMap exceeded maxCidrs 2; dropped 1 CIDRs" {
+		t.Errorf("warnings = %v, want one truncation warning", warnings)
+	}
+}
+
+func TestCollectCIDRs_IPFamily(t *testing.T) {
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}, Data: map[string]string{"cidrs": "203.0.113.0/24\n2001:db8::/32\nnot-a-cidr"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default"}, Data: map[string]string{"cidrs": "198.51.100.0/24\n2001:db8:2::/48\n::ffff:192.0.2.0/120"}},
+		).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			IPFamily:    botv1alpha1.IPFamilyIPv4,
+			CustomCIDRs: []string{"10.0.0.0/8", "2001:db8:1::/48"},
+		},
+	}
+	specs := []botv1alpha1.ProviderSpec{
+		{Name: "configMap", DisplayName: "policy-family", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "a", Key: "cidrs"}},
+		{Name: "configMap", DisplayName: "own-family", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "b", Key: "cidrs"}, IPFamily: botv1alpha1.IPFamilyIPv6},
+	}
+
+	cidrs, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	want := []string{"10.0.0.0/8", "2001:db8:2::/48", "203.0.113.0/24", "::ffff:192.0.2.0/120"}
+	if strings.Join(cidrs, ",") != strings.Join(want, ",") {
+		t.Errorf("collectCIDRs() = %v, want %v", cidrs, want)
+	}
+	wantWarning := "provider policy-family dropped 1 malformed CIDRs: not-a-cidr"
+	if len(warnings) != 1 || warnings[0] != wantWarning {
+		t.Errorf("warnings = %v, want [%s]", warnings, wantWarning)
+	}
+}
+
+func TestReconcile_SkipsMalformedCIDRs(t *testing.T) {
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Providers: []botv1alpha1.ProviderSpec{
+				{Name: "configMap", DisplayName: "partner-a", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner-a", Key: "cidrs"}},
+				{Name: "configMap", DisplayName: "partner-b", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner-b", Key: "cidrs"}},
+			},
+			CustomCIDRs: []string{"192.0.2.0/24", "192.0.2.1"},
+		},
+	}
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(
+			resource,
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "partner-a", Namespace: "default"}, Data: map[string]string{"cidrs": "10.0.0.0/24,not-a-cidr"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "partner-b", Namespace: "default"}, Data: map[string]string{"cidrs": "300.0.0.0/8\n2001:db8::/32"}},
+		).
+		WithStatusSubresource(&botv1alpha1.BotNetworkPolicy{}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var policy networkingv1.NetworkPolicy
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}, &policy); err != nil {
+		t.Fatalf("get network policy: %v", err)
+	}
+	ingress, _ := policyCIDRs([]*networkingv1.NetworkPolicy{&policy})
+	want := []string{"10.0.0.0/24", "192.0.2.0/24", "2001:db8::/32"}
+	if got := sets.List(ingress); !slices.Equal(got, want) {
+		t.Errorf("ingress CIDRs = %v, want %v", got, want)
+	}
+
+	wantWarnings := []string{
+		"provider partner-a dropped 1 malformed CIDRs: not-a-cidr",
+		"provider partner-b dropped 1 malformed CIDRs: 300.0.0.0/8",
+		"customCidrs dropped 1 malformed CIDRs: 192.0.2.1",
+	}
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	for _, warning := range wantWarnings {
+		if !slices.Contains(events, "Warning "+ReasonProviderWarning+" "+warning) {
+			t.Errorf("missing %s event %q in %v", ReasonProviderWarning, warning, events)
+		}
+	}
+}
+
+func TestReconcile_MaxCIDRs(t *testing.T) {
+	egress := true
+	resource := newResource()
+	resource.Spec.Egress = &egress
+	resource.Spec.CustomCIDRs = []string{"203.0.113.0/24", "192.0.2.0/24", "198.51.100.0/24"}
+	resource.Spec.MaxCIDRs = 2
+	reconciler := newTestReconciler(t, resource)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var policy networkingv1.NetworkPolicy
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}, &policy); err != nil {
+		t.Fatalf("get network policy: %v", err)
+	}
+	ingress, egressCIDRs := policyCIDRs([]*networkingv1.NetworkPolicy{&policy})
+	want := []string{"192.0.2.0/24", "198.51.100.0/24"}
+	if got := sets.List(ingress); !slices.Equal(got, want) {
+		t.Errorf("ingress CIDRs = %v, want %v", got, want)
+	}
+	if got := sets.List(egressCIDRs); !slices.Equal(got, want) {
+		t.Errorf("egress CIDRs = %v, want %v", got, want)
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	wantEvent := "Warning " + ReasonMaxCIDRsExceeded + " collected CIDRs exceeded maxCidrs 2; dropped 1 ingress and 1 egress CIDRs"
+	if !slices.Contains(events, wantEvent) {
+		t.Errorf("missing event %q in %v", wantEvent, events)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(current.Status.Conditions, botv1alpha1.ConditionCIDRsTruncated) {
+		t.Errorf("expected CIDRsTruncated=True, got %#v", current.Status.Conditions)
+	}
+
+	current.Spec.MaxCIDRs = 3
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if !meta.IsStatusConditionFalse(current.Status.Conditions, botv1alpha1.ConditionCIDRsTruncated) {
+		t.Errorf("expected CIDRsTruncated=False within the limit, got %#v", current.Status.Conditions)
+	}
+}
+
+func TestReconcile_DryRun(t *testing.T) {
+	dryRun := true
+	resource := newResource()
+	resource.Spec.CustomCIDRs = []string{"192.0.2.0/24", "198.51.100.0/24"}
+	resource.Spec.DryRun = &dryRun
+	reconciler := newTestReconciler(t, resource)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var policies networkingv1.NetworkPolicyList
+	if err := kubeClient.List(ctx, &policies); err != nil {
+		t.Fatalf("list network policies: %v", err)
+	}
+	if len(policies.Items) != 0 {
+		t.Fatalf("dry run created %d NetworkPolicies", len(policies.Items))
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	wantEvent := "Normal " + ReasonDryRun + " dry run: would create NetworkPolicy " + resource.NetworkPolicyName() + "; 2 CIDRs added, 0 removed"
+	if !slices.Contains(events, wantEvent) {
+		t.Errorf("missing event %q in %v", wantEvent, events)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if current.Status.PlannedCIDRCount != 2 {
+		t.Errorf("plannedCidrCount = %d, want 2", current.Status.PlannedCIDRCount)
+	}
+	ready := meta.FindStatusCondition(current.Status.Conditions, botv1alpha1.ConditionReady)
+	if ready == nil || ready.Reason != ReasonDryRun {
+		t.Errorf("Ready condition = %#v, want reason %s", ready, ReasonDryRun)
+	}
+
+	// Leaving dry run applies the policy; turning it back on leaves it untouched.
+	current.Spec.DryRun = nil
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if current.Status.PlannedCIDRCount != 0 {
+		t.Errorf("plannedCidrCount = %d after leaving dry run, want 0", current.Status.PlannedCIDRCount)
+	}
+	current.Spec.DryRun = &dryRun
+	current.Spec.CustomCIDRs = []string{"192.0.2.0/24"}
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	events = events[:0]
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	wantEvent = "Normal " + ReasonDryRun + " dry run: would update NetworkPolicy " + resource.NetworkPolicyName() + "; 0 CIDRs added, 1 removed"
+	if !slices.Contains(events, wantEvent) {
+		t.Errorf("missing event %q in %v", wantEvent, events)
+	}
+	var policy networkingv1.NetworkPolicy
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}, &policy); err != nil {
+		t.Fatalf("get network policy: %v", err)
+	}
+	ingress, _ := policyCIDRs([]*networkingv1.NetworkPolicy{&policy})
+	if got, want := sets.List(ingress), []string{"192.0.2.0/24", "198.51.100.0/24"}; !slices.Equal(got, want) {
+		t.Errorf("dry run changed ingress CIDRs to %v, want %v", got, want)
+	}
+}
+
+func TestCollectCIDRs_ProviderTypeMetric(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["192.0.2.0/24","198.51.100.0/24"]`))
+	}))
+	defer server.Close()
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}, Data: map[string]string{"cidrs": "203.0.113.0/24"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default"}, Data: map[string]string{"cidrs": "10.0.0.0/8\n10.1.0.0/16\n10.2.0.0/16"}},
+		).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	reconciler.HTTPClient = server.Client()
+	resource := &botv1alpha1.BotNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"}}
+	specs := []botv1alpha1.ProviderSpec{
+		{Name: "configMap", DisplayName: "first", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "a", Key: "cidrs"}},
+		{Name: "ConfigMap", DisplayName: "second", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "b", Key: "cidrs"}, MaxCIDRs: 2},
+		{Name: "jsonEndpoint", JSONEndpoint: &botv1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "."}},
+	}
+
+	configMaps := providerTypeCIDRs.WithLabelValues("configmap")
+	endpoints := providerTypeCIDRs.WithLabelValues("jsonendpoint")
+	beforeConfigMaps, beforeEndpoints := testutil.ToFloat64(configMaps), testutil.ToFloat64(endpoints)
+	if _, _, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard()); err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	if got := testutil.ToFloat64(configMaps) - beforeConfigMaps; got != 3 {
+		t.Errorf("configmap contribution = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(endpoints) - beforeEndpoints; got != 2 {
+		t.Errorf("jsonendpoint contribution = %v, want 2", got)
+	}
+}
+
+func TestReconcile_TracingSpans(t *testing.T) {
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Providers: []botv1alpha1.ProviderSpec{{
+				Name:      "configMap",
+				ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner", Key: "cidrs"},
+			}},
+		},
+	}
+	reconciler := newTestReconciler(t, resource, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "partner", Namespace: "default"},
+		Data:       map[string]string{"cidrs": "192.0.2.0/24\n198.51.100.0/24"},
+	})
+
+	exporter := tracetest.NewInMemoryExporter()
+	reconciler.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	reconcileSpan, ok := spans["Reconcile"]
+	if !ok {
+		t.Fatalf("no Reconcile span in %v", exporter.GetSpans())
+	}
+	collectSpan, ok := spans["collectCIDRs"]
+	if !ok {
+		t.Fatal("no collectCIDRs span")
+	}
+	fetchSpan, ok := spans["Fetch"]
+	if !ok {
+		t.Fatal("no Fetch span")
+	}
+	if collectSpan.Parent.SpanID() != reconcileSpan.SpanContext.SpanID() {
+		t.Errorf("collectCIDRs span is not a child of Reconcile")
+	}
+	if fetchSpan.Parent.SpanID() != collectSpan.SpanContext.SpanID() {
+		t.Errorf("Fetch span is not a child of collectCIDRs")
+	}
+
+	attrs := func(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+		result := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes {
+			result[kv.Key] = kv.Value
+		}
+		return result
+	}
+	fetchAttrs := attrs(fetchSpan)
+	if got := fetchAttrs[attrProviderName].AsString(); got != "configMap" {
+		t.Errorf("Fetch provider.name = %q, want configMap", got)
+	}
+	if got := fetchAttrs[attrCIDRCount].AsInt64(); got != 2 {
+		t.Errorf("Fetch cidr.count = %d, want 2", got)
+	}
+	collectAttrs := attrs(collectSpan)
+	if got := collectAttrs[attrProviderCount].AsInt64(); got != 1 {
+		t.Errorf("collectCIDRs provider.count = %d, want 1", got)
+	}
+	if got := collectAttrs[attrCIDRCount].AsInt64(); got != 2 {
+		t.Errorf("collectCIDRs cidr.count = %d, want 2", got)
+	}
+}
+
+func TestReconcile_ServerSideApply(t *testing.T) {
+	resource := newResource()
+
+	var patchType types.PatchType
+	var patchOpts client.PatchOptions
+	var applied networkingv1.NetworkPolicy
+	kubeClient := newTestClientBuilder(t, resource).
+		WithInterceptorFuncs(interceptor.Funcs{
+			// The fake client does not implement server-side apply; capture the patch instead.
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if _, ok := obj.(*networkingv1.NetworkPolicy); !ok {
+					return c.Patch(ctx, obj, patch, opts...)
+				}
+				patchType = patch.Type()
+				patchOpts.ApplyOptions(opts)
+				data, err := patch.Data(obj)
+				if err != nil {
+					return err
+				}
+				return json.Unmarshal(data, &applied)
+			},
+		}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	reconciler.FieldManager = "bot-operator"
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if patchType != types.ApplyPatchType {
+		t.Fatalf("patch type = %q, want %q", patchType, types.ApplyPatchType)
+	}
+	if patchOpts.FieldManager != "bot-operator" {
+		t.Errorf("field manager = %q, want bot-operator", patchOpts.FieldManager)
+	}
+	if patchOpts.Force == nil || !*patchOpts.Force {
+		t.Error("expected the apply patch to force ownership")
+	}
+	if applied.Kind != "NetworkPolicy" || applied.APIVersion != "networking.k8s.io/v1" {
+		t.Errorf("applied type = %s %s, want networking.k8s.io/v1 NetworkPolicy", applied.APIVersion, applied.Kind)
+	}
+	if applied.Name != "sample-allow-bots" || applied.Labels[ownerLabel] != "sample" {
+		t.Errorf("applied metadata = %s %v", applied.Name, applied.Labels)
+	}
+	if len(applied.OwnerReferences) != 1 || applied.OwnerReferences[0].Name != "sample" {
+		t.Errorf("applied owner references = %v, want the BotNetworkPolicy", applied.OwnerReferences)
+	}
+	if applied.Spec.PodSelector.MatchLabels["app"] != "web" {
+		t.Errorf("applied pod selector = %v", applied.Spec.PodSelector)
+	}
+	if len(applied.Spec.Ingress) != 1 || len(applied.Spec.Ingress[0].From) != 1 || applied.Spec.Ingress[0].From[0].IPBlock.CIDR != "10.0.0.0/24" {
+		t.Errorf("applied ingress = %v, want a single 10.0.0.0/24 peer", applied.Spec.Ingress)
+	}
+}
+
+func TestReconcile_ConcurrentSameKeyCreatesOnce(t *testing.T) {
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sample",
+			Namespace:  "default",
+			Finalizers: []string{DefaultFinalizerName},
+		},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			CustomCIDRs: []string{"10.0.0.0/24"},
+		},
+		// A prior Ready condition skips the Pending status write, so every goroutine
+		// reaches the NetworkPolicy write.
+		Status: botv1alpha1.BotNetworkPolicyStatus{Conditions: []metav1.Condition{{
+			Type:               botv1alpha1.ConditionReady,
+			Status:             metav1.ConditionFalse,
+			Reason:             "Pending",
+			LastTransitionTime: metav1.Now(),
+		}}},
+	}
+
+	kubeClient := newTestClientBuilder(t, resource).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				err := c.Get(ctx, key, obj, opts...)
+				if _, ok := obj.(*networkingv1.NetworkPolicy); ok {
+					// Widen the window between the existence check and the apply.
+					time.Sleep(10 * time.Millisecond)
+				}
+				return err
+			},
+			Patch: fakeServerSideApply,
+		}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Status writes may conflict between the goroutines; policy creation must not.
+	for err := range errs {
+		if apierrors.IsAlreadyExists(err) {
+			t.Errorf("Reconcile() error = %v, want no duplicate create", err)
+		}
+	}
+	creates := 0
+	for len(recorder.Events) > 0 {
+		if strings.Contains(<-recorder.Events, ReasonCreatedPolicy) {
+			creates++
+		}
+	}
+	if creates != 1 {
+		t.Errorf("NetworkPolicy creates reported = %d, want 1", creates)
+	}
+}
+
+func TestCollectCIDRs_EmptyFeedEvent(t *testing.T) {
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "default"},
+				Data:       map[string]string{"cidrs": "\n"},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "default"},
+				Data:       map[string]string{"other": "10.0.0.0/24"},
+			},
+		).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec:       botv1alpha1.BotNetworkPolicySpec{CustomCIDRs: []string{"192.0.2.0/24"}},
+	}
+	specs := []botv1alpha1.ProviderSpec{
+		{Name: "configMap", DisplayName: "empty", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "empty", Key: "cidrs"}},
+		{Name: "configMap", DisplayName: "broken", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "broken", Key: "cidrs"}},
+	}
+
+	_, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	if len(events) != 1 || !strings.Contains(events[0], "EmptyFeed") || !strings.Contains(events[0], "empty") {
+		t.Errorf("events = %v, want one EmptyFeed event for the empty provider", events)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "broken") {
+		t.Errorf("warnings = %v, want only the broken provider reported as a fetch error", warnings)
+	}
+	if got := resource.Status.ProviderStatuses[0].LastError; got == "" {
+		t.Error("empty provider status has no LastError")
+	}
+}
+
+func TestReconcile_SyncJitterSpreadsPolling(t *testing.T) {
+	const count = 20
+	period := 10 * time.Minute
+	objs := make([]client.Object, 0, count)
+	for i := 0; i < count; i++ {
+		resource := newResource()
+		resource.Name = fmt.Sprintf("sample-%d", i)
+		resource.Spec.SyncPeriod = metav1.Duration{Duration: period}
+		objs = append(objs, resource)
+	}
+	reconciler := newTestReconciler(t, objs...)
+	reconciler.SyncJitterFraction = 0.2
+
+	lower, upper := 8*time.Minute, 12*time.Minute
+	delays := make(map[time.Duration]struct{})
+	for i := 0; i < count; i++ {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: fmt.Sprintf("sample-%d", i), Namespace: "default"}}
+		result, err := reconciler.Reconcile(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if result.RequeueAfter < lower || result.RequeueAfter > upper {
+			t.Fatalf("RequeueAfter = %v, want within [%v, %v]", result.RequeueAfter, lower, upper)
+		}
+		delays[result.RequeueAfter] = struct{}{}
+	}
+	if len(delays) < 2 {
+		t.Errorf("every resync was scheduled after %v, want jittered delays", delays)
+	}
+
+	if got := jitterSyncPeriod(period, 0); got != period {
+		t.Errorf("jitterSyncPeriod() with fraction 0 = %v, want %v", got, period)
+	}
+	for i := 0; i < 1000; i++ {
+		if got := jitterSyncPeriod(period, 5); got <= 0 || got >= 2*period {
+			t.Fatalf("jitterSyncPeriod() with an oversized fraction = %v, want within (0, %v)", got, 2*period)
+		}
+	}
+}
+
+func TestReconcile_AuditModeAnnotation(t *testing.T) {
+	const annotation = "policy.example.com/log-only"
+	audit := true
+	resource := newResource()
+	resource.Spec.AuditMode = &audit
+	reconciler := newTestReconciler(t, resource)
+	reconciler.AuditAnnotation = annotation
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	policyKey := types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	var policy networkingv1.NetworkPolicy
+	if err := kubeClient.Get(ctx, policyKey, &policy); err != nil {
+		t.Fatalf("get network policy: %v", err)
+	}
+	if got := policy.Annotations[annotation]; got != "true" {
+		t.Fatalf("annotation %s = %q, want \"true\"", annotation, got)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	current.Spec.AuditMode = nil
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := kubeClient.Get(ctx, policyKey, &policy); err != nil {
+		t.Fatalf("get network policy: %v", err)
+	}
+	if _, ok := policy.Annotations[annotation]; ok {
+		t.Fatalf("expected annotation %s to be removed once auditMode is disabled", annotation)
+	}
+}
+
+func TestReconcile_ResultReasons(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	configMapProvider := func(name string) botv1alpha1.ProviderSpec {
+		return botv1alpha1.ProviderSpec{Name: "configMap", DisplayName: name, ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: name, Key: "cidrs"}}
+	}
+	allowlist := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "allowlist", Namespace: "default"},
+		Data:       map[string]string{"cidrs": "198.51.100.0/24"},
+	}
+	reasons := func(recorder *record.FakeRecorder) []string {
+		var got []string
+		for len(recorder.Events) > 0 {
+			got = append(got, strings.Fields(<-recorder.Events)[1])
+		}
+		return got
+	}
+
+	tests := []struct {
+		name       string
+		spec       botv1alpha1.BotNetworkPolicySpec
+		mutate     func(*botv1alpha1.BotNetworkPolicySpec)
+		wantEvent  string
+		wantReady  string
+		wantErrors bool
+	}{
+		{
+			name:      "created",
+			spec:      botv1alpha1.BotNetworkPolicySpec{PodSelector: selector, CustomCIDRs: []string{"10.0.0.0/24"}},
+			wantEvent: ReasonCreatedPolicy,
+			wantReady: ReasonSynced,
+		},
+		{
+			name:      "no change",
+			spec:      botv1alpha1.BotNetworkPolicySpec{PodSelector: selector, CustomCIDRs: []string{"10.0.0.0/24"}},
+			mutate:    func(*botv1alpha1.BotNetworkPolicySpec) {},
+			wantEvent: ReasonNoChange,
+			wantReady: ReasonSynced,
+		},
+		{
+			name: "updated",
+			spec: botv1alpha1.BotNetworkPolicySpec{PodSelector: selector, CustomCIDRs: []string{"10.0.0.0/24"}},
+			mutate: func(spec *botv1alpha1.BotNetworkPolicySpec) {
+				spec.CustomCIDRs = append(spec.CustomCIDRs, "10.0.1.0/24")
+			},
+			wantEvent: ReasonUpdatedPolicy,
+			wantReady: ReasonSynced,
+		},
+		{
+			name:      "invalid spec",
+			spec:      botv1alpha1.BotNetworkPolicySpec{CustomCIDRs: []string{"10.0.0.0/24"}},
+			wantEvent: ReasonInvalidSpec,
+			wantReady: ReasonInvalidSpec,
+		},
+		{
+			name: "partial provider failure",
+			spec: botv1alpha1.BotNetworkPolicySpec{
+				PodSelector: selector,
+				Providers:   []botv1alpha1.ProviderSpec{configMapProvider("allowlist"), configMapProvider("missing")},
+			},
+			wantEvent: ReasonProviderPartialFailure,
+			wantReady: ReasonSynced,
+		},
+		{
+			name: "total provider failure",
+			spec: botv1alpha1.BotNetworkPolicySpec{
+				PodSelector: selector,
+				Providers:   []botv1alpha1.ProviderSpec{configMapProvider("missing")},
+			},
+			wantEvent: ReasonProviderTotalFailure,
+			wantReady: ReasonSynced,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := &botv1alpha1.BotNetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+			reconciler := newTestReconciler(t, resource, allowlist.DeepCopy())
+			recorder := reconciler.Recorder.(*record.FakeRecorder)
+			reconciler.now = steppingClock(2 * providers.DefaultSyncPeriod)
+			kubeClient := reconciler.Client
+
+			ctx := context.Background()
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+			if _, err := reconciler.Reconcile(ctx, req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if tt.mutate != nil {
+				var current botv1alpha1.BotNetworkPolicy
+				if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+					t.Fatalf("get resource: %v", err)
+				}
+				tt.mutate(&current.Spec)
+				if err := kubeClient.Update(ctx, &current); err != nil {
+					t.Fatalf("update resource: %v", err)
+				}
+				reasons(recorder)
+				if _, err := reconciler.Reconcile(ctx, req); err != nil {
+					t.Fatalf("second Reconcile() error = %v", err)
+				}
+			}
+
+			if got := reasons(recorder); !slices.Contains(got, tt.wantEvent) {
+				t.Errorf("event reasons = %v, want %s", got, tt.wantEvent)
+			}
+			var current botv1alpha1.BotNetworkPolicy
+			if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+				t.Fatalf("get resource: %v", err)
+			}
+			ready := meta.FindStatusCondition(current.Status.Conditions, botv1alpha1.ConditionReady)
+			if ready == nil || ready.Reason != tt.wantReady {
+				t.Errorf("Ready condition = %+v, want reason %s", ready, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestReconcile_PinAnnotationHoldsSnapshot(t *testing.T) {
+	var fetches atomic.Int32
+	feed := `["198.51.100.0/24"]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Providers: []botv1alpha1.ProviderSpec{{
+				Name:         "jsonEndpoint",
+				JSONEndpoint: &botv1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "."},
+			}},
+		},
+	}
+	pinnedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	reconciler := newTestReconciler(t, resource)
+	reconciler.HTTPClient = server.Client()
+	reconciler.now = func() time.Time { return pinnedAt }
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	policyKey := types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}
+	appliedCIDRs := func() []string {
+		t.Helper()
+		var policy networkingv1.NetworkPolicy
+		if err := kubeClient.Get(ctx, policyKey, &policy); err != nil {
+			t.Fatalf("get network policy: %v", err)
+		}
+		var cidrs []string
+		for _, peer := range policy.Spec.Ingress[0].From {
+			cidrs = append(cidrs, peer.IPBlock.CIDR)
+		}
+		return cidrs
+	}
+	setPin := func(pin bool) {
+		t.Helper()
+		var current botv1alpha1.BotNetworkPolicy
+		if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+			t.Fatalf("get resource: %v", err)
+		}
+		if pin {
+			current.Annotations = map[string]string{PinAnnotation: "true"}
+		} else {
+			delete(current.Annotations, PinAnnotation)
+		}
+		if err := kubeClient.Update(ctx, &current); err != nil {
+			t.Fatalf("update resource: %v", err)
+		}
+	}
+
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if fetches.Load() != 1 {
+		t.Fatalf("fetches = %d after the first sync, want 1", fetches.Load())
+	}
+
+	setPin(true)
+	feed = `["198.51.100.0/24","203.0.113.0/24"]`
+	for i := 0; i < 2; i++ {
+		result, err := reconciler.Reconcile(ctx, req)
+		if err != nil {
+			t.Fatalf("Reconcile() while pinned error = %v", err)
+		}
+		if result.RequeueAfter != 0 {
+			t.Errorf("RequeueAfter = %s while pinned, want no periodic resync", result.RequeueAfter)
+		}
+	}
+	if fetches.Load() != 1 {
+		t.Fatalf("fetches = %d while pinned, want no further fetches", fetches.Load())
+	}
+	if got := appliedCIDRs(); !slices.Equal(got, []string{"198.51.100.0/24"}) {
+		t.Errorf("applied CIDRs while pinned = %v, want the pinned snapshot", got)
+	}
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if current.Status.PinnedSince == nil || !current.Status.PinnedSince.Time.Equal(pinnedAt) {
+		t.Errorf("pinnedSince = %v, want %s", current.Status.PinnedSince, pinnedAt)
+	}
+	if ready := meta.FindStatusCondition(current.Status.Conditions, botv1alpha1.ConditionReady); ready == nil || ready.Reason != ReasonPinned || ready.Status != metav1.ConditionTrue {
+		t.Errorf("Ready condition = %+v, want True with reason %s", ready, ReasonPinned)
+	}
+
+	setPin(false)
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() after unpinning error = %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Fatalf("fetches = %d after unpinning, want 2", fetches.Load())
+	}
+	if got := appliedCIDRs(); !slices.Equal(got, []string{"198.51.100.0/24", "203.0.113.0/24"}) {
+		t.Errorf("applied CIDRs after unpinning = %v, want the refreshed feed", got)
+	}
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if current.Status.PinnedSince != nil {
+		t.Errorf("pinnedSince = %v after unpinning, want it cleared", current.Status.PinnedSince)
+	}
+}
+
+func TestReconcile_StableReconciles(t *testing.T) {
+	resource := newResource()
+	reconciler := newTestReconciler(t, resource)
+	reconciler.now = steppingClock(2 * providers.DefaultSyncPeriod)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	reconcile := func() int {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		var current botv1alpha1.BotNetworkPolicy
+		if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+			t.Fatalf("get resource: %v", err)
+		}
+		return current.Status.StableReconciles
+	}
+
+	for i, want := range []int{0, 1, 2} {
+		if got := reconcile(); got != want {
+			t.Fatalf("reconcile %d: stableReconciles = %d, want %d", i+1, got, want)
+		}
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	current.Spec.CustomCIDRs = append(current.Spec.CustomCIDRs, "10.0.1.0/24")
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	if got := reconcile(); got != 0 {
+		t.Fatalf("stableReconciles = %d after a policy change, want 0", got)
+	}
+	if got := reconcile(); got != 1 {
+		t.Fatalf("stableReconciles = %d after the next no-op, want 1", got)
+	}
+}
+
+func TestReconcile_LastChange(t *testing.T) {
+	resource := newResource()
+	resource.Spec.CustomCIDRs = []string{"10.0.0.0/24", "10.0.1.0/24"}
+	reconciler := newTestReconciler(t, resource)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	reconcile := func() *botv1alpha1.PolicyChange {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		var current botv1alpha1.BotNetworkPolicy
+		if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+			t.Fatalf("get resource: %v", err)
+		}
+		if current.Status.LastChange == nil {
+			t.Fatal("status.lastChange not set")
+		}
+		return current.Status.LastChange
+	}
+
+	if change := reconcile(); !slices.Equal(change.Added, []string{"10.0.0.0/24", "10.0.1.0/24"}) || len(change.Removed) != 0 {
+		t.Errorf("lastChange after creation = %+v, want both CIDRs added", change)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	current.Spec.CustomCIDRs = []string{"10.0.1.0/24", "10.0.2.0/24"}
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	change := reconcile()
+	if !slices.Equal(change.Added, []string{"10.0.2.0/24"}) || change.AddedCount != 1 {
+		t.Errorf("lastChange added = %v (%d), want [10.0.2.0/24]", change.Added, change.AddedCount)
+	}
+	if !slices.Equal(change.Removed, []string{"10.0.0.0/24"}) || change.RemovedCount != 1 {
+		t.Errorf("lastChange removed = %v (%d), want [10.0.0.0/24]", change.Removed, change.RemovedCount)
+	}
+
+	after := sets.New[string]()
+	for i := 0; i < maxLastChangeEntries+5; i++ {
+		after.Insert(fmt.Sprintf("192.0.2.%d/32", i))
+	}
+	if truncated := policyChange(sets.New[string](), after, time.Now()); len(truncated.Added) != maxLastChangeEntries || truncated.AddedCount != maxLastChangeEntries+5 {
+		t.Errorf("policyChange() kept %d of %d added CIDRs, want %d", len(truncated.Added), truncated.AddedCount, maxLastChangeEntries)
+	}
+}
+
+func TestReconcile_ProvidersHealthyCondition(t *testing.T) {
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Providers: []botv1alpha1.ProviderSpec{
+				{Name: "configMap", DisplayName: "partner-a", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner-a", Key: "cidrs"}},
+				{Name: "configMap", DisplayName: "partner-b", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "partner-b", Key: "cidrs"}},
+			},
+		},
+	}
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(resource, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "partner-a", Namespace: "default"},
+			Data:       map[string]string{"cidrs": "10.0.0.0/24"},
+		}).
+		WithStatusSubresource(&botv1alpha1.BotNetworkPolicy{}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	reconciler.now = steppingClock(2 * providers.DefaultSyncPeriod)
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	conditions := func() (ready, healthy *metav1.Condition) {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		var current botv1alpha1.BotNetworkPolicy
+		if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+			t.Fatalf("get resource: %v", err)
+		}
+		ready = meta.FindStatusCondition(current.Status.Conditions, botv1alpha1.ConditionReady)
+		healthy = meta.FindStatusCondition(current.Status.Conditions, botv1alpha1.ConditionProvidersHealthy)
+		if ready == nil || healthy == nil {
+			t.Fatalf("conditions = %+v, want Ready and ProvidersHealthy", current.Status.Conditions)
+		}
+		return ready, healthy
+	}
+
+	ready, healthy := conditions()
+	if ready.Status != metav1.ConditionTrue || ready.Reason != ReasonSynced {
+		t.Errorf("Ready = %s/%s, want True/%s since the policy was applied", ready.Status, ready.Reason, ReasonSynced)
+	}
+	if healthy.Status != metav1.ConditionFalse || healthy.Reason != ReasonProviderPartialFailure {
+		t.Errorf("ProvidersHealthy = %s/%s, want False/%s", healthy.Status, healthy.Reason, ReasonProviderPartialFailure)
+	}
+	if !strings.HasPrefix(healthy.Message, "1 of 2 providers failed to sync: partner-b: ") {
+		t.Errorf("ProvidersHealthy message = %q, want it to name partner-b", healthy.Message)
+	}
+
+	if err := kubeClient.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "partner-b", Namespace: "default"},
+		Data:       map[string]string{"cidrs": "10.0.1.0/24"},
+	}); err != nil {
+		t.Fatalf("create config map: %v", err)
+	}
+	ready, healthy = conditions()
+	if ready.Status != metav1.ConditionTrue {
+		t.Errorf("Ready = %s, want True", ready.Status)
+	}
+	if healthy.Status != metav1.ConditionTrue || healthy.Reason != ReasonProvidersSynced || healthy.Message != "all 2 providers synced" {
+		t.Errorf("ProvidersHealthy = %s/%s %q, want True/%s after recovery", healthy.Status, healthy.Reason, healthy.Message, ReasonProvidersSynced)
+	}
+}
+
+func TestReconcile_Metrics(t *testing.T) {
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Providers: []botv1alpha1.ProviderSpec{
+				{Name: "configMap", DisplayName: "metrics-ok", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "metrics-ok", Key: "cidrs"}},
+				{Name: "configMap", DisplayName: "metrics-missing", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "missing", Key: "cidrs"}},
+			},
+			CustomCIDRs: []string{"10.0.0.0/24"},
+		},
+	}
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(resource, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics-ok", Namespace: "default"},
+			Data:       map[string]string{"cidrs": "10.0.0.0/24,10.0.1.0/24"},
+		}).
+		WithStatusSubresource(&botv1alpha1.BotNetworkPolicy{}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "metrics", Namespace: "default"}}
+	okErrors, missingErrors := providerFetchErrors.WithLabelValues("metrics-ok"), providerFetchErrors.WithLabelValues("metrics-missing")
+	beforeOK, beforeMissing := testutil.ToFloat64(okErrors), testutil.ToFloat64(missingErrors)
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := testutil.ToFloat64(missingErrors) - beforeMissing; got != 1 {
+		t.Errorf("fetch errors of the failing provider = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(okErrors) - beforeOK; got != 0 {
+		t.Errorf("fetch errors of the healthy provider = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(policyCIDRCount.WithLabelValues("default", "metrics")); got != 2 {
+		t.Errorf("botnp_cidrs_total = %v, want 2", got)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if err := kubeClient.Delete(ctx, &current); err != nil {
+		t.Fatalf("delete resource: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() after delete error = %v", err)
+		}
+	}
+	if policyCIDRCount.DeleteLabelValues("default", "metrics") {
+		t.Error("botnp_cidrs_total still reported for the deleted resource")
+	}
+}
+
+// steppingClock returns a clock that advances by step on every read, so each reconcile
+// happens after the sync period of the previous one has elapsed.
+func steppingClock(step time.Duration) func() time.Time {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestReconcile_SkipsRefetchWithinSyncPeriod(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte(`["198.51.100.0/24"]`))
+	}))
+	defer server.Close()
+
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			SyncPeriod:  metav1.Duration{Duration: 10 * time.Minute},
+			Providers: []botv1alpha1.ProviderSpec{{
+				Name:         "jsonEndpoint",
+				JSONEndpoint: &botv1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "."},
+			}},
+		},
+	}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	reconciler := newTestReconciler(t, resource)
+	reconciler.HTTPClient = server.Client()
+	reconciler.now = func() time.Time { return now }
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	reconcile := func() ctrl.Result {
+		t.Helper()
+		result, err := reconciler.Reconcile(ctx, req)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		return result
+	}
+
+	reconcile()
+	now = now.Add(4 * time.Minute)
+	if result := reconcile(); result.RequeueAfter != 6*time.Minute {
+		t.Errorf("RequeueAfter = %s on a resync within the sync period, want the 6m remaining", result.RequeueAfter)
+	}
+	if fetches.Load() != 1 {
+		t.Fatalf("fetches = %d after a resync within the sync period, want 1", fetches.Load())
+	}
+
+	// A generated NetworkPolicy deleted within the sync period is recreated from the CIDRs
+	// of the last fetch.
+	policyKey := types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}
+	if err := kubeClient.Delete(ctx, &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: policyKey.Name, Namespace: policyKey.Namespace}}); err != nil {
+		t.Fatalf("delete network policy: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if result := reconcile(); result.RequeueAfter != 5*time.Minute {
+		t.Errorf("RequeueAfter = %s after repairing the policy, want the 5m remaining", result.RequeueAfter)
+	}
+	if fetches.Load() != 1 {
+		t.Fatalf("fetches = %d after repairing the policy within the sync period, want 1", fetches.Load())
+	}
+	var policy networkingv1.NetworkPolicy
+	if err := kubeClient.Get(ctx, policyKey, &policy); err != nil {
+		t.Fatalf("deleted network policy was not recreated: %v", err)
+	}
+	if got := policy.Spec.Ingress[0].From[0].IPBlock.CIDR; got != "198.51.100.0/24" {
+		t.Errorf("recreated policy allows %s, want 198.51.100.0/24", got)
+	}
+
+	var current botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	current.Annotations = map[string]string{ForceSyncAnnotation: "1"}
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update resource: %v", err)
+	}
+	reconcile()
+	if fetches.Load() != 2 {
+		t.Fatalf("fetches = %d after setting %s, want 2", fetches.Load(), ForceSyncAnnotation)
+	}
+
+	now = now.Add(10 * time.Minute)
+	reconcile()
+	if fetches.Load() != 3 {
+		t.Errorf("fetches = %d once the sync period elapsed, want 3", fetches.Load())
+	}
+}
+
+func TestCollectCIDRs_InsecureSkipTLSVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cidrs":["203.0.113.0/24"]}`))
+	}))
+	defer server.Close()
+
+	shared := &http.Client{}
+	reconciler := newTestReconciler(t)
+	reconciler.HTTPClient = shared
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	resource := &botv1alpha1.BotNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"}}
+	insecure := true
+	specs := []botv1alpha1.ProviderSpec{
+		{Name: "jsonEndpoint", DisplayName: "internal", JSONEndpoint: &botv1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "cidrs", InsecureSkipTLSVerify: &insecure}},
+		{Name: "jsonEndpoint", DisplayName: "verified", JSONEndpoint: &botv1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "cidrs"}},
+	}
+
+	cidrs, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	if !slices.Equal(cidrs, []string{"203.0.113.0/24"}) {
+		t.Errorf("cidrs = %v, want the insecure provider's CIDR", cidrs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "provider verified fetch error") {
+		t.Errorf("warnings = %v, want a certificate error from the verified provider only", warnings)
+	}
+	if shared.Transport != nil {
+		t.Error("the shared HTTP client was modified")
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Warning "+ReasonInsecureTLS+" provider internal fetches with TLS certificate verification disabled") {
+		t.Errorf("events = %v, want a single InsecureTLS warning for the internal provider", events)
+	}
+}
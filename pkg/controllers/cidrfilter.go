@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// ipFamilyFor returns the address family the CIDRs of spec are restricted to: its own
+// ipFamily, else the policy-wide one. Empty means both families.
+func ipFamilyFor(resource *botv1alpha1.BotNetworkPolicy, spec botv1alpha1.ProviderSpec) string {
+	if spec.IPFamily != "" {
+		return spec.IPFamily
+	}
+	return resource.Spec.IPFamily
+}
+
+// filterCIDRs drops the entries of cidrs that net.ParseCIDR rejects, returning them as
+// malformed, so that a single bad value cannot make the API server reject the whole
+// NetworkPolicy. When family is IPFamilyIPv4 or IPFamilyIPv6 it also drops, and counts as
+// excluded, the CIDRs of the other family; an empty family keeps both.
+func filterCIDRs(cidrs []string, family string) (kept []string, excluded int, malformed []string) {
+	kept = make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			malformed = append(malformed, cidr)
+			continue
+		}
+		if family != "" {
+			// The network keeps the length of the notation, so IPv4-mapped IPv6 stays IPv6.
+			isIPv4 := len(network.IP) == net.IPv4len
+			if isIPv4 != (family == botv1alpha1.IPFamilyIPv4) {
+				excluded++
+				continue
+			}
+		}
+		kept = append(kept, cidr)
+	}
+	return kept, excluded, malformed
+}
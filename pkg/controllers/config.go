@@ -0,0 +1,37 @@
+package controllers
+
+// EffectiveConfig returns the reconciler and provider factory settings as logr key/value
+// pairs, so misconfiguration can be diagnosed from the startup log alone. Secret material
+// such as credentials embedded in endpoint URLs is redacted.
+func (r *BotNetworkPolicyReconciler) EffectiveConfig() []any {
+	factory := r.providerFactory().Config()
+	return []any{
+		"googleEndpoint", factory.GoogleEndpoint,
+		"googleCloudEndpoint", factory.CloudEndpoint,
+		"awsEndpoint", factory.AWSEndpoint,
+		"awsExcludedRegions", factory.AWSExcludedRegions,
+		"githubEndpoint", factory.GitHubEndpoint,
+		"cloudflareEndpoint", factory.CloudflareEndpoint,
+		"azureEndpoint", factory.AzureEndpoint,
+		"httpTimeout", factory.HTTPTimeout,
+		"minTLSVersion", factory.MinTLSVersion,
+		"retryMaxAttempts", factory.RetryMaxAttempts,
+		"retryBaseDelay", factory.RetryBaseDelay,
+		"retryMaxDelay", factory.RetryMaxDelay,
+		"retryMaxElapsed", factory.RetryMaxElapsed,
+		"providerCacheTTL", factory.CacheTTL,
+		"providerConcurrency", factory.Concurrency,
+		"responseBodyLogBytes", factory.ResponseBodyLogBytes,
+		"warnOnEmptySelector", r.WarnOnEmptySelector,
+		"warnOnOverlappingSelectors", r.WarnOnOverlappingSelectors,
+		"startupJitter", r.StartupJitter,
+		"syncJitterFraction", r.SyncJitterFraction,
+		"finalizerName", r.finalizerName(),
+		"auditAnnotation", r.auditAnnotation(),
+		"maxProviders", r.MaxProviders,
+		"hostBitsLogLevel", r.HostBitsLogLevel,
+		"fieldManager", r.fieldManager(),
+		"enableDebugSampling", r.EnableDebugSampling,
+		"readyzWaitForSync", r.SyncTracker != nil,
+	}
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// configMapRefIndex indexes BotNetworkPolicies by the ConfigMaps their providers read, as
+// "namespace/name". ConfigMaps read from every namespace matching a selector are indexed
+// as "*/name".
+const configMapRefIndex = "spec.providers.configMapRefs"
+
+// configMapRefs returns the configMapRefIndex values of resource: the ConfigMaps of its
+// configMap providers, in their own namespace when one is set, and the ConfigMaps shared
+// through configRef or holding caConfigMapRef bundles, which always live in the namespace
+// of resource.
+func configMapRefs(resource *botv1alpha1.BotNetworkPolicy) []string {
+	seen := map[string]bool{}
+	var refs []string
+	add := func(namespace, name string) {
+		key := namespace + "/" + name
+		if !seen[key] {
+			seen[key] = true
+			refs = append(refs, key)
+		}
+	}
+	for _, list := range [][]botv1alpha1.ProviderSpec{resource.Spec.Providers, resource.Spec.IngressProviders, resource.Spec.EgressProviders} {
+		for _, spec := range list {
+			if spec.ConfigRef != nil {
+				add(resource.Namespace, spec.ConfigRef.Name)
+			}
+			if _, ref := spec.CABundleRefs(); ref != nil {
+				add(resource.Namespace, ref.Name)
+			}
+			if cm := spec.ConfigMap; cm != nil {
+				switch {
+				case cm.NamespaceSelector != nil:
+					add("*", cm.Name)
+				case cm.Namespace != "":
+					add(cm.Namespace, cm.Name)
+				default:
+					add(resource.Namespace, cm.Name)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+func indexConfigMapRefs(obj client.Object) []string {
+	resource, ok := obj.(*botv1alpha1.BotNetworkPolicy)
+	if !ok {
+		return nil
+	}
+	return configMapRefs(resource)
+}
+
+// policiesForConfigMap maps a ConfigMap to the BotNetworkPolicies in any namespace that
+// read it, and forgets their last fetch so the reconcile refetches instead of waiting
+// for the sync period.
+func (r *BotNetworkPolicyReconciler) policiesForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	seen := map[types.NamespacedName]bool{}
+	for _, ref := range []string{obj.GetNamespace() + "/" + obj.GetName(), "*/" + obj.GetName()} {
+		var list botv1alpha1.BotNetworkPolicyList
+		if err := r.List(ctx, &list, client.MatchingFields{configMapRefIndex: ref}); err != nil {
+			log.FromContext(ctx).Error(err, "failed to list BotNetworkPolicies referencing ConfigMap", "configMap", client.ObjectKeyFromObject(obj))
+			continue
+		}
+		for i := range list.Items {
+			key := client.ObjectKeyFromObject(&list.Items[i])
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			r.fetches.forget(key)
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+	}
+	return requests
+}
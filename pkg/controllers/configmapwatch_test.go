@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestPoliciesForConfigMap(t *testing.T) {
+	policy := func(namespace, name string, providers ...botv1alpha1.ProviderSpec) *botv1alpha1.BotNetworkPolicy {
+		return &botv1alpha1.BotNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       botv1alpha1.BotNetworkPolicySpec{Providers: providers},
+		}
+	}
+	configMap := func(spec botv1alpha1.ConfigMapProviderSpec) botv1alpha1.ProviderSpec {
+		spec.Key = "cidrs"
+		return botv1alpha1.ProviderSpec{Name: "configMap", ConfigMap: &spec}
+	}
+	kubeClient := newTestClientBuilder(t).
+		WithIndex(&botv1alpha1.BotNetworkPolicy{}, configMapRefIndex, indexConfigMapRefs).
+		WithObjects(
+			policy("team-a", "local", configMap(botv1alpha1.ConfigMapProviderSpec{Name: "bots"})),
+			policy("team-b", "shared", configMap(botv1alpha1.ConfigMapProviderSpec{Name: "bots", Namespace: "team-a"})),
+			policy("team-c", "selected", configMap(botv1alpha1.ConfigMapProviderSpec{
+				Name:              "bots",
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"bots": "true"}},
+			})),
+			policy("team-a", "configref", botv1alpha1.ProviderSpec{ConfigRef: &botv1alpha1.ProviderConfigRef{Name: "shared-provider"}}),
+			policy("team-b", "unrelated", configMap(botv1alpha1.ConfigMapProviderSpec{Name: "bots"})),
+		).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+
+	ctx := context.Background()
+	now := time.Now()
+	shared := types.NamespacedName{Namespace: "team-b", Name: "shared"}
+	reconciler.fetches.record(shared, 1, directionalCIDRs{}, now)
+
+	mapped := func(namespace, name string) []string {
+		t.Helper()
+		var keys []string
+		for _, req := range reconciler.policiesForConfigMap(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}) {
+			keys = append(keys, req.String())
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	if got, want := mapped("team-a", "bots"), []string{"team-a/local", "team-b/shared", "team-c/selected"}; !slices.Equal(got, want) {
+		t.Errorf("requests for team-a/bots = %v, want %v", got, want)
+	}
+	if got, want := mapped("team-a", "shared-provider"), []string{"team-a/configref"}; !slices.Equal(got, want) {
+		t.Errorf("requests for team-a/shared-provider = %v, want %v", got, want)
+	}
+	if got := mapped("team-c", "other"); len(got) != 0 {
+		t.Errorf("requests for an unreferenced ConfigMap = %v, want none", got)
+	}
+	if _, wait := reconciler.fetches.remaining(shared, 1, time.Hour, now); wait != 0 {
+		t.Errorf("remaining() after a ConfigMap change = %v, want an immediate refetch", wait)
+	}
+}
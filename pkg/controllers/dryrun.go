@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+const dryRunReadyMessage = "dry run: providers synchronised, NetworkPolicies computed but not applied"
+
+// reportDryRun logs and records an event describing how applying desired would change
+// current, the NetworkPolicies owned by resource, and sets status.plannedCidrCount. Nothing
+// is written to the cluster.
+func (r *BotNetworkPolicyReconciler) reportDryRun(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, current, desired []*networkingv1.NetworkPolicy, logger logr.Logger) error {
+	planned := allowedCIDRs(desired)
+	resource.Status.PlannedCIDRCount = planned.Len()
+
+	actions, err := r.planNetworkPolicies(ctx, resource, current, desired)
+	if err != nil {
+		return err
+	}
+	change := policyChange(allowedCIDRs(current), planned, r.currentTime())
+	message := "dry run: generated NetworkPolicies are up to date"
+	if len(actions) > 0 {
+		message = fmt.Sprintf("dry run: would %s; %d CIDRs added, %d removed", strings.Join(actions, "; "), change.AddedCount, change.RemovedCount)
+	}
+	logger.Info("dry run", "actions", actions, "added", change.Added, "removed", change.Removed, "plannedCidrCount", planned.Len())
+	if r.Recorder != nil {
+		r.Recorder.Event(resource, corev1.EventTypeNormal, ReasonDryRun, message)
+	}
+	return nil
+}
+
+// planNetworkPolicies returns the create, update and delete actions that applying desired
+// over current would take, in the order ensureNetworkPolicy takes them. Existing policies
+// are applied with a server-side dry run, so the API server decides what would change.
+func (r *BotNetworkPolicyReconciler) planNetworkPolicies(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, current, desired []*networkingv1.NetworkPolicy) ([]string, error) {
+	existing := make(map[string]*networkingv1.NetworkPolicy, len(current))
+	for _, policy := range current {
+		existing[policy.Name] = policy
+	}
+	desiredNames := sets.New[string]()
+	var actions []string
+	for _, policy := range desired {
+		desiredNames.Insert(policy.Name)
+		old, ok := existing[policy.Name]
+		if !ok {
+			actions = append(actions, "create NetworkPolicy "+policy.Name)
+			continue
+		}
+		applied := policy.DeepCopy()
+		if err := r.patchNetworkPolicy(ctx, resource, applied, client.DryRunAll); err != nil {
+			return nil, err
+		}
+		if networkPolicyChanged(old, applied) {
+			actions = append(actions, "update NetworkPolicy "+policy.Name)
+		}
+	}
+	for _, policy := range current {
+		if !desiredNames.Has(policy.Name) {
+			actions = append(actions, "delete NetworkPolicy "+policy.Name)
+		}
+	}
+	return actions, nil
+}
+
+// networkPolicyChanged reports whether applied, the result of a dry-run apply, differs from
+// the stored policy in anything the operator writes.
+func networkPolicyChanged(stored, applied *networkingv1.NetworkPolicy) bool {
+	return !equality.Semantic.DeepEqual(stored.Spec, applied.Spec) ||
+		!equality.Semantic.DeepEqual(stored.Labels, applied.Labels) ||
+		!equality.Semantic.DeepEqual(stored.Annotations, applied.Annotations)
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"fmt"
+	"net"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// maxIPBlockExcepts bounds the except list of a single IPBlock peer. A malformed feed
+// could otherwise bloat one IPBlock past what the API server and CNI handle well.
+const maxIPBlockExcepts = 100
+
+// limitIPBlockExcepts removes IPBlock peers whose except list holds more than limit
+// entries and returns a warning for each one. Truncating the list would widen the block,
+// so the whole peer is dropped instead; a rule left without peers is removed as well,
+// since an empty peer list would allow all traffic. A limit of zero or less disables the
+// check.
+func limitIPBlockExcepts(policies []*networkingv1.NetworkPolicy, limit int) []string {
+	if limit <= 0 {
+		return nil
+	}
+	var warnings []string
+	for _, policy := range policies {
+		ingress := policy.Spec.Ingress[:0]
+		for _, rule := range policy.Spec.Ingress {
+			var dropped []string
+			rule.From, dropped = limitPeerExcepts(rule.From, limit)
+			warnings = append(warnings, exceptWarnings(policy.Name, "ingress", dropped, limit)...)
+			if len(rule.From) > 0 || len(dropped) == 0 {
+				ingress = append(ingress, rule)
+			}
+		}
+		policy.Spec.Ingress = ingress
+
+		egress := policy.Spec.Egress[:0]
+		for _, rule := range policy.Spec.Egress {
+			var dropped []string
+			rule.To, dropped = limitPeerExcepts(rule.To, limit)
+			warnings = append(warnings, exceptWarnings(policy.Name, "egress", dropped, limit)...)
+			if len(rule.To) > 0 || len(dropped) == 0 {
+				egress = append(egress, rule)
+			}
+		}
+		policy.Spec.Egress = egress
+	}
+	return warnings
+}
+
+// limitPeerExcepts returns the peers whose except list is within limit and a
+// "cidr (count)" description of each dropped peer.
+func limitPeerExcepts(peers []networkingv1.NetworkPolicyPeer, limit int) ([]networkingv1.NetworkPolicyPeer, []string) {
+	var dropped []string
+	kept := peers[:0]
+	for _, peer := range peers {
+		if peer.IPBlock != nil && len(peer.IPBlock.Except) > limit {
+			dropped = append(dropped, fmt.Sprintf("%s (%d)", peer.IPBlock.CIDR, len(peer.IPBlock.Except)))
+			continue
+		}
+		kept = append(kept, peer)
+	}
+	return kept, dropped
+}
+
+func exceptWarnings(policyName, direction string, dropped []string, limit int) []string {
+	warnings := make([]string, 0, len(dropped))
+	for _, peer := range dropped {
+		warnings = append(warnings, fmt.Sprintf("NetworkPolicy %s: %s IPBlock %s exceeds the limit of %d except entries and was omitted", policyName, direction, peer, limit))
+	}
+	return warnings
+}
+
+// parseExcepts parses the exceptCidrs of a spec, skipping entries Validate rejects.
+func parseExcepts(cidrs []string) []*net.IPNet {
+	excepts := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if _, except, err := net.ParseCIDR(cidr); err == nil {
+			excepts = append(excepts, except)
+		}
+	}
+	return excepts
+}
+
+// exceptsWithin returns the excepts that are strict subsets of cidr, in canonical form.
+// An IPBlock may only except ranges narrower than its own CIDR.
+func exceptsWithin(cidr string, excepts []*net.IPNet) []string {
+	_, block, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil
+	}
+	var within []string
+	for _, except := range excepts {
+		if strictlyContains(block, except) {
+			within = append(within, except.String())
+		}
+	}
+	return within
+}
+
+func strictlyContains(block, inner *net.IPNet) bool {
+	blockOnes, blockBits := block.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return blockBits == innerBits && innerOnes > blockOnes && block.Contains(inner.IP)
+}
+
+// unusedExcepts returns a warning for every except that no allowed CIDR of either
+// direction strictly contains, and which therefore restricts nothing.
+func unusedExcepts(exceptCIDRs []string, cidrs directionalCIDRs) []string {
+	if len(exceptCIDRs) == 0 {
+		return nil
+	}
+	var blocks []*net.IPNet
+	for _, cidr := range append(append([]string{}, cidrs.Ingress...), cidrs.Egress...) {
+		if _, block, err := net.ParseCIDR(cidr); err == nil {
+			blocks = append(blocks, block)
+		}
+	}
+	var warnings []string
+	for _, except := range parseExcepts(exceptCIDRs) {
+		used := false
+		for _, block := range blocks {
+			if strictlyContains(block, except) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			warnings = append(warnings, fmt.Sprintf("except %s is not inside any allowed CIDR and was not applied", except))
+		}
+	}
+	return warnings
+}
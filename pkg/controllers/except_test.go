@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestLimitIPBlockExcepts_DropsOversizedPeers(t *testing.T) {
+	oversized := make([]string, maxIPBlockExcepts+1)
+	for i := range oversized {
+		oversized[i] = fmt.Sprintf("10.0.%d.0/24", i)
+	}
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "bot-sample", Namespace: "default"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{
+					{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: oversized}},
+					{IPBlock: &networkingv1.IPBlock{CIDR: "192.0.2.0/24", Except: []string{"192.0.2.128/25"}}},
+				},
+			}},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{
+					{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: oversized}},
+				},
+			}},
+		},
+	}
+
+	warnings := limitIPBlockExcepts([]*networkingv1.NetworkPolicy{policy}, maxIPBlockExcepts)
+	if len(warnings) != 2 {
+		t.Fatalf("expected one warning per oversized peer, got %v", warnings)
+	}
+	for _, warning := range warnings {
+		if !strings.Contains(warning, fmt.Sprintf("10.0.0.0/8 (%d)", maxIPBlockExcepts+1)) || !strings.Contains(warning, "bot-sample") {
+			t.Fatalf("warning does not name the policy and peer: %q", warning)
+		}
+	}
+	if len(policy.Spec.Ingress) != 1 || len(policy.Spec.Ingress[0].From) != 1 || policy.Spec.Ingress[0].From[0].IPBlock.CIDR != "192.0.2.0/24" {
+		t.Fatalf("expected only the peer within the limit to remain, got %+v", policy.Spec.Ingress)
+	}
+	if len(policy.Spec.Egress) != 0 {
+		t.Fatalf("expected the egress rule without peers to be removed, got %+v", policy.Spec.Egress)
+	}
+}
+
+func TestLimitIPBlockExcepts_WithinLimit(t *testing.T) {
+	policy := &networkingv1.NetworkPolicy{
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: ipBlockPeers([]string{"198.51.100.0/24", "203.0.113.0/24"}),
+			}},
+		},
+	}
+	if warnings := limitIPBlockExcepts([]*networkingv1.NetworkPolicy{policy}, maxIPBlockExcepts); len(warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	if len(policy.Spec.Ingress) != 1 || len(policy.Spec.Ingress[0].From) != 2 {
+		t.Fatalf("policy within the limit was modified: %+v", policy.Spec.Ingress)
+	}
+}
+
+func TestBuildNetworkPolicy_ExceptCIDRs(t *testing.T) {
+	egress := true
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Egress:      &egress,
+			ExceptCIDRs: []string{"10.1.2.3/24", "10.0.0.0/8", "192.0.2.0/24", "2001:db8:1::/48"},
+		},
+	}
+	cidrs := directionalCIDRs{
+		Ingress: []string{"10.0.0.0/8", "198.51.100.0/24"},
+		Egress:  []string{"2001:db8::/32"},
+	}
+
+	np := buildNetworkPolicy(resource, cidrs)
+	ingress := np.Spec.Ingress[0].From
+	if got := ingress[0].IPBlock.Except; len(got) != 1 || got[0] != "10.1.2.0/24" {
+		t.Errorf("10.0.0.0/8 except = %v, want [10.1.2.0/24]", got)
+	}
+	if got := ingress[1].IPBlock.Except; got != nil {
+		t.Errorf("198.51.100.0/24 except = %v, want none", got)
+	}
+	if got := np.Spec.Egress[0].To[0].IPBlock.Except; len(got) != 1 || got[0] != "2001:db8:1::/48" {
+		t.Errorf("2001:db8::/32 except = %v, want [2001:db8:1::/48]", got)
+	}
+
+	warnings := unusedExcepts(resource.Spec.ExceptCIDRs, cidrs)
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "10.0.0.0/8") || !strings.Contains(warnings[1], "192.0.2.0/24") {
+		t.Errorf("unusedExcepts() = %v, want warnings for 10.0.0.0/8 and 192.0.2.0/24", warnings)
+	}
+}
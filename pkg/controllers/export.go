@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// ExportNetworkPolicies renders the desired NetworkPolicy for every BotNetworkPolicy in
+// the namespace and writes each one as YAML into dir, together with a kustomization.yaml
+// listing them. No cluster state is modified. It returns the paths of the written files.
+func (r *BotNetworkPolicyReconciler) ExportNetworkPolicies(ctx context.Context, namespace, dir string) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	var list botv1alpha1.BotNetworkPolicyList
+	if err := r.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("listing botnetworkpolicies: %w", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	written := make([]string, 0, len(list.Items)+1)
+	resources := make([]string, 0, len(list.Items))
+	for i := range list.Items {
+		resource := &list.Items[i]
+		if err := resource.Validate(); err != nil {
+			return written, fmt.Errorf("%s/%s: %w", resource.Namespace, resource.Name, err)
+		}
+
+		cidrs, warnings, err := r.collectDirectionalCIDRs(ctx, resource, logger)
+		if err != nil {
+			return written, fmt.Errorf("%s/%s: %w", resource.Namespace, resource.Name, err)
+		}
+		for _, warning := range warnings {
+			logger.Info("provider warning", "botnetworkpolicy", resource.Name, "warning", warning)
+		}
+		cidrs, truncated := limitPolicyCIDRs(resource, cidrs)
+		if truncated != "" {
+			logger.Info("maxCidrs exceeded", "botnetworkpolicy", resource.Name, "warning", truncated)
+		}
+
+		desiredPolicies := buildNetworkPolicies(resource, cidrs)
+		for _, warning := range unusedExcepts(resource.Spec.ExceptCIDRs, cidrs) {
+			logger.Info("unused except", "botnetworkpolicy", resource.Name, "warning", warning)
+		}
+		for _, warning := range limitIPBlockExcepts(desiredPolicies, maxIPBlockExcepts) {
+			logger.Info("except limit exceeded", "botnetworkpolicy", resource.Name, "warning", warning)
+		}
+		r.annotateAuditMode(resource, desiredPolicies)
+		for _, desired := range desiredPolicies {
+			desired.TypeMeta.APIVersion = networkingv1.SchemeGroupVersion.String()
+			desired.TypeMeta.Kind = "NetworkPolicy"
+
+			data, err := yaml.Marshal(desired)
+			if err != nil {
+				return written, err
+			}
+			fileName := desired.Name + ".yaml"
+			path := filepath.Join(dir, fileName)
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				return written, err
+			}
+			written = append(written, path)
+			resources = append(resources, fileName)
+		}
+	}
+
+	kustomization := map[string]any{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"namespace":  namespace,
+		"resources":  resources,
+	}
+	data, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return written, err
+	}
+	path := filepath.Join(dir, "kustomization.yaml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return written, err
+	}
+	return append(written, path), nil
+}
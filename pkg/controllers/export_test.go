@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestExportNetworkPolicies(t *testing.T) {
+	allPods := true
+
+	objects := []*botv1alpha1.BotNetworkPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: botv1alpha1.BotNetworkPolicySpec{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				CustomCIDRs: []string{"10.0.0.0/24"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: botv1alpha1.BotNetworkPolicySpec{
+				TargetAllPods: &allPods,
+				CustomCIDRs:   []string{"192.0.2.0/24", "198.51.100.0/24"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"},
+			Spec: botv1alpha1.BotNetworkPolicySpec{
+				CustomCIDRs: []string{"203.0.113.0/24"},
+			},
+		},
+	}
+
+	objs := make([]client.Object, 0, len(objects))
+	for _, obj := range objects {
+		objs = append(objs, obj)
+	}
+	reconciler := newTestReconciler(t, objs...)
+
+	dir := t.TempDir()
+	written, err := reconciler.ExportNetworkPolicies(context.Background(), "default", dir)
+	if err != nil {
+		t.Fatalf("ExportNetworkPolicies() error = %v", err)
+	}
+	if len(written) != 3 {
+		t.Fatalf("ExportNetworkPolicies() wrote %d files, want 3: %v", len(written), written)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "api-allow-bots.yaml"))
+	if err != nil {
+		t.Fatalf("reading exported policy: %v", err)
+	}
+	var np networkingv1.NetworkPolicy
+	if err := yaml.Unmarshal(data, &np); err != nil {
+		t.Fatalf("unmarshal exported policy: %v", err)
+	}
+	if np.Kind != "NetworkPolicy" || np.APIVersion != "networking.k8s.io/v1" {
+		t.Errorf("unexpected type meta: %s %s", np.APIVersion, np.Kind)
+	}
+	if len(np.Spec.Ingress) != 1 || len(np.Spec.Ingress[0].From) != 2 {
+		t.Fatalf("unexpected ingress rules: %#v", np.Spec.Ingress)
+	}
+	if np.Spec.Ingress[0].From[0].IPBlock.CIDR != "192.0.2.0/24" {
+		t.Errorf("unexpected first CIDR: %s", np.Spec.Ingress[0].From[0].IPBlock.CIDR)
+	}
+
+	kustomization, err := os.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	if err != nil {
+		t.Fatalf("reading kustomization: %v", err)
+	}
+	content := string(kustomization)
+	for _, want := range []string{"api-allow-bots.yaml", "web-allow-bots.yaml", "namespace: default"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("kustomization.yaml missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "other-allow-bots.yaml") {
+		t.Errorf("kustomization.yaml includes policy from another namespace:\n%s", content)
+	}
+}
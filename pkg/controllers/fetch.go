@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+	"github.com/sugaf1204/botnetworkpolicy-operator/pkg/providers"
+)
+
+// providerFetch is the outcome of fetching one provider.
+type providerFetch struct {
+	cidrs []string
+	err   error
+}
+
+// fetchProviders fetches every provider without a setup error, at most concurrency at a
+// time, and returns the outcomes indexed like specs so that callers process them, and
+// accumulate their warnings, in spec order regardless of which fetch finished first.
+func (r *BotNetworkPolicyReconciler) fetchProviders(ctx context.Context, specs []botv1alpha1.ProviderSpec, built []providers.Provider, setupErrs []error, concurrency int) []providerFetch {
+	results := make([]providerFetch, len(specs))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(concurrency, 1))
+	for i, spec := range specs {
+		if setupErrs[i] != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i] = r.fetchOne(ctx, spec, built[i])
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// fetchOne fetches a single provider within its timeoutSeconds.
+func (r *BotNetworkPolicyReconciler) fetchOne(ctx context.Context, spec botv1alpha1.ProviderSpec, provider providers.Provider) providerFetch {
+	fetchCtx, cancel := providerContext(ctx, spec)
+	defer cancel()
+
+	cidrs, err := r.fetchProvider(fetchCtx, spec.Label(), provider)
+	return providerFetch{cidrs: cidrs, err: providerTimeoutError(ctx, fetchCtx, spec, err)}
+}
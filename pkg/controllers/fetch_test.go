@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+	"github.com/sugaf1204/botnetworkpolicy-operator/pkg/providers"
+)
+
+func TestCollectCIDRs_ConcurrentFetches(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`["` + strings.TrimPrefix(r.URL.Path, "/") + `/24"]`))
+	}))
+	defer server.Close()
+	reconciler := newTestReconciler(t)
+	reconciler.HTTPClient = server.Client()
+	reconciler.ProviderOptions = []providers.FactoryOption{providers.WithConcurrency(2)}
+	resource := &botv1alpha1.BotNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"}}
+	endpoint := func(name, path string) botv1alpha1.ProviderSpec {
+		return botv1alpha1.ProviderSpec{Name: "jsonEndpoint", DisplayName: name, JSONEndpoint: &botv1alpha1.JSONEndpointProviderSpec{URL: server.URL + path, FieldPath: "."}}
+	}
+	specs := []botv1alpha1.ProviderSpec{
+		endpoint("first", "/broken"),
+		endpoint("second", "/203.0.113.0"),
+		endpoint("third", "/192.0.2.0"),
+		endpoint("fourth", "/broken"),
+		endpoint("fifth", "/198.51.100.0"),
+	}
+
+	cidrs, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrent fetches = %d, want 2", got)
+	}
+	if want := []string{"192.0.2.0/24", "198.51.100.0/24", "203.0.113.0/24"}; !slices.Equal(cidrs, want) {
+		t.Errorf("cidrs = %v, want %v", cidrs, want)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "provider first ") || !strings.Contains(warnings[1], "provider fourth ") {
+		t.Errorf("warnings = %v, want the first and fourth providers' fetch errors in spec order", warnings)
+	}
+	for i, status := range resource.Status.ProviderStatuses {
+		if status.DisplayName != specs[i].DisplayName {
+			t.Errorf("providerStatuses[%d] = %s, want %s", i, status.DisplayName, specs[i].DisplayName)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// DefaultFinalizerName is the finalizer added to every BotNetworkPolicy so that its
+// generated NetworkPolicies are removed before the resource disappears.
+const DefaultFinalizerName = "bot.networking.dev/finalizer"
+
+func (r *BotNetworkPolicyReconciler) finalizerName() string {
+	if r.FinalizerName != "" {
+		return r.FinalizerName
+	}
+	return DefaultFinalizerName
+}
+
+// ensureFinalizer adds the finalizer when missing and persists the change.
+func (r *BotNetworkPolicyReconciler) ensureFinalizer(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy) error {
+	if !controllerutil.AddFinalizer(resource, r.finalizerName()) {
+		return nil
+	}
+	return r.Update(ctx, resource)
+}
+
+// finalize deletes the generated NetworkPolicies of a resource being deleted and then
+// releases it by removing the finalizer.
+func (r *BotNetworkPolicyReconciler) finalize(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, logger logr.Logger) error {
+	if !controllerutil.ContainsFinalizer(resource, r.finalizerName()) {
+		return nil
+	}
+	if _, err := r.pruneNetworkPolicies(ctx, resource, sets.New[string](), logger); err != nil {
+		return err
+	}
+	controllerutil.RemoveFinalizer(resource, r.finalizerName())
+	return r.Update(ctx, resource)
+}
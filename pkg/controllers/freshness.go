@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// ForceSyncAnnotation forces a provider refetch when its value changes, e.g. to the
+// current time, even if the last successful fetch is within the sync period. Any other
+// annotation or spec change has the same effect; this key exists so users need not
+// invent one.
+const ForceSyncAnnotation = "bot.networking.dev/force-sync"
+
+// fetchTracker remembers, per BotNetworkPolicy, when its providers were last fetched
+// successfully, from which spec and which CIDRs they returned, so that cache resyncs and
+// unrelated events within the sync period reapply those CIDRs instead of refetching every
+// provider.
+type fetchTracker struct {
+	mu      sync.Mutex
+	entries map[types.NamespacedName]fetchRecord
+}
+
+type fetchRecord struct {
+	hash  uint64
+	at    time.Time
+	cidrs directionalCIDRs
+}
+
+// remaining returns how long until key is due for a refetch, together with the CIDRs of
+// its last fetch. The duration is zero when key was never fetched, its spec or
+// annotations changed since, or period has elapsed.
+func (t *fetchTracker) remaining(key types.NamespacedName, hash uint64, period time.Duration, now time.Time) (directionalCIDRs, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[key]
+	if !ok || entry.hash != hash {
+		return directionalCIDRs{}, 0
+	}
+	return entry.cidrs, max(period-now.Sub(entry.at), 0)
+}
+
+// record notes a successful fetch of key from the spec with hash at now, which collected
+// cidrs.
+func (t *fetchTracker) record(key types.NamespacedName, hash uint64, cidrs directionalCIDRs, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.entries == nil {
+		t.entries = make(map[types.NamespacedName]fetchRecord)
+	}
+	t.entries[key] = fetchRecord{hash: hash, at: now, cidrs: cidrs}
+}
+
+// forget drops key, e.g. after it was deleted.
+func (t *fetchTracker) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, key)
+}
+
+// hashFetchInputs returns an FNV-1a hash of the spec and annotations of resource, which
+// together determine what a fetch produces.
+func hashFetchInputs(resource *botv1alpha1.BotNetworkPolicy) uint64 {
+	data, _ := json.Marshal(struct {
+		Spec        botv1alpha1.BotNetworkPolicySpec `json:"spec"`
+		Annotations map[string]string                `json:"annotations"`
+	}{resource.Spec, resource.Annotations})
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
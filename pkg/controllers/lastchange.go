@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// maxLastChangeEntries bounds the Added and Removed lists of status.lastChange, so a feed
+// that changes wholesale does not bloat the status.
+const maxLastChangeEntries = 20
+
+// allowedCIDRs returns the ipBlock CIDRs allowed in either direction by policies.
+func allowedCIDRs(policies []*networkingv1.NetworkPolicy) sets.Set[string] {
+	ingress, egress := policyCIDRs(policies)
+	return ingress.Union(egress)
+}
+
+// policyChange describes the CIDRs added and removed when before became after.
+func policyChange(before, after sets.Set[string], now time.Time) *botv1alpha1.PolicyChange {
+	added := sets.List(after.Difference(before))
+	removed := sets.List(before.Difference(after))
+	return &botv1alpha1.PolicyChange{
+		Time:         metav1.NewTime(now),
+		Added:        added[:min(len(added), maxLastChangeEntries)],
+		AddedCount:   len(added),
+		Removed:      removed[:min(len(removed), maxLastChangeEntries)],
+		RemovedCount: len(removed),
+	}
+}
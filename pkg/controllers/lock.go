@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// keyedMutex serializes work per object. controller-runtime already avoids reconciling the
+// same key concurrently, but direct Reconcile calls and future callers are not bound by
+// that, and two writers racing on the generated NetworkPolicy would create it twice.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[types.NamespacedName]*refCountedMutex
+}
+
+type refCountedMutex struct {
+	sync.Mutex
+	refs int
+}
+
+// lock blocks until key is free and returns the function that releases it. Entries are
+// dropped once no caller holds or waits for them, so deleted objects do not accumulate.
+func (k *keyedMutex) lock(key types.NamespacedName) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[types.NamespacedName]*refCountedMutex)
+	}
+	m, ok := k.locks[key]
+	if !ok {
+		m = &refCountedMutex{}
+		k.locks[key] = m
+	}
+	m.refs++
+	k.mu.Unlock()
+
+	m.Lock()
+	return func() {
+		m.Unlock()
+		k.mu.Lock()
+		m.refs--
+		if m.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// maintenanceDeferral returns how long to wait before applying cidrs when the resource's
+// maintenance window is closed and the change removes CIDRs from the generated
+// NetworkPolicies, or also adds them when DeferAdditions is set. Zero means apply now.
+func (r *BotNetworkPolicyReconciler) maintenanceDeferral(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, cidrs directionalCIDRs) (time.Duration, error) {
+	window := resource.Spec.MaintenanceWindow
+	if window == nil {
+		return 0, nil
+	}
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return 0, err
+	}
+	now := r.currentTime()
+	open, next := windowState(schedule, window.Duration.Duration, now)
+	if open {
+		return 0, nil
+	}
+
+	current, err := r.ownedNetworkPolicies(ctx, resource)
+	if err != nil {
+		return 0, err
+	}
+	if len(current) == 0 {
+		return 0, nil
+	}
+
+	currentIngress, currentEgress := policyCIDRs(current)
+	desiredIngress, desiredEgress := policyCIDRs(buildNetworkPolicies(resource, cidrs))
+	removes := !desiredIngress.IsSuperset(currentIngress) || !desiredEgress.IsSuperset(currentEgress)
+	adds := !currentIngress.IsSuperset(desiredIngress) || !currentEgress.IsSuperset(desiredEgress)
+	if removes || (adds && window.DeferAdditions) {
+		return next.Sub(now), nil
+	}
+	return 0, nil
+}
+
+// windowState reports whether a window of the given duration opened by schedule is open
+// at now, and otherwise when the next one opens.
+func windowState(schedule cron.Schedule, duration time.Duration, now time.Time) (bool, time.Time) {
+	if last := schedule.Next(now.Add(-duration)); !last.After(now) {
+		return true, last
+	}
+	return false, schedule.Next(now)
+}
+
+// policyCIDRs returns the normalized ipBlock CIDRs of the policies' ingress and egress rules.
+func policyCIDRs(policies []*networkingv1.NetworkPolicy) (sets.Set[string], sets.Set[string]) {
+	ingress, egress := sets.New[string](), sets.New[string]()
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Ingress {
+			for _, peer := range rule.From {
+				if peer.IPBlock != nil {
+					ingress.Insert(normalizeCIDR(peer.IPBlock.CIDR))
+				}
+			}
+		}
+		for _, rule := range policy.Spec.Egress {
+			for _, peer := range rule.To {
+				if peer.IPBlock != nil {
+					egress.Insert(normalizeCIDR(peer.IPBlock.CIDR))
+				}
+			}
+		}
+	}
+	return ingress, egress
+}
+
+func (r *BotNetworkPolicyReconciler) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
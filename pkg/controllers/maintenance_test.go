@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestReconcile_MaintenanceWindowDefersRemovals(t *testing.T) {
+	resource := newResource()
+	resource.Spec.CustomCIDRs = []string{"10.0.0.0/24", "10.1.0.0/24"}
+	resource.Spec.MaintenanceWindow = &botv1alpha1.MaintenanceWindowSpec{
+		Schedule: "CRON_TZ=UTC 0 2 * * *",
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	reconciler := newTestReconciler(t, resource)
+	reconciler.now = func() time.Time { return now }
+	kubeClient := reconciler.Client
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "sample", Namespace: "default"}}
+	key := types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}
+
+	peerCIDRs := func() []string {
+		t.Helper()
+		var np networkingv1.NetworkPolicy
+		if err := kubeClient.Get(context.Background(), key, &np); err != nil {
+			t.Fatalf("get networkpolicy: %v", err)
+		}
+		var cidrs []string
+		for _, peer := range np.Spec.Ingress[0].From {
+			cidrs = append(cidrs, peer.IPBlock.CIDR)
+		}
+		return cidrs
+	}
+	setCIDRs := func(cidrs ...string) {
+		t.Helper()
+		var current botv1alpha1.BotNetworkPolicy
+		if err := kubeClient.Get(context.Background(), req.NamespacedName, &current); err != nil {
+			t.Fatalf("get resource: %v", err)
+		}
+		current.Spec.CustomCIDRs = cidrs
+		if err := kubeClient.Update(context.Background(), &current); err != nil {
+			t.Fatalf("update resource: %v", err)
+		}
+	}
+
+	// The first sync creates the policy outside the window: there is nothing to remove.
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := peerCIDRs(); len(got) != 2 {
+		t.Fatalf("initial peers = %v, want both CIDRs", got)
+	}
+
+	// Additions apply immediately outside the window.
+	setCIDRs("10.0.0.0/24", "10.1.0.0/24", "10.2.0.0/24")
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := peerCIDRs(); len(got) != 3 {
+		t.Fatalf("peers after addition = %v, want three CIDRs", got)
+	}
+
+	// Removals are deferred until the window opens at 02:00 UTC.
+	setCIDRs("10.0.0.0/24")
+	result, err := reconciler.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := peerCIDRs(); len(got) != 3 {
+		t.Fatalf("peers outside window = %v, want removal deferred", got)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > 16*time.Hour {
+		t.Errorf("RequeueAfter = %v, want at most the 16h until the window opens", result.RequeueAfter)
+	}
+	var deferred botv1alpha1.BotNetworkPolicy
+	if err := kubeClient.Get(context.Background(), req.NamespacedName, &deferred); err != nil {
+		t.Fatalf("get resource: %v", err)
+	}
+	if ready := meta.FindStatusCondition(deferred.Status.Conditions, botv1alpha1.ConditionReady); ready == nil || ready.Reason != "UpdateDeferred" {
+		t.Errorf("Ready condition = %+v, want reason UpdateDeferred", ready)
+	}
+
+	now = time.Date(2024, 5, 2, 2, 30, 0, 0, time.UTC)
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := peerCIDRs(); len(got) != 1 || got[0] != "10.0.0.0/24" {
+		t.Fatalf("peers inside window = %v, want [10.0.0.0/24]", got)
+	}
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// limitPolicyCIDRs keeps the first spec.maxCidrs CIDRs of each direction in sorted order
+// and records the CIDRsTruncated condition on the resource without persisting it. It
+// returns a non-empty message when CIDRs were dropped.
+func limitPolicyCIDRs(resource *botv1alpha1.BotNetworkPolicy, cidrs directionalCIDRs) (directionalCIDRs, string) {
+	limit := resource.Spec.MaxCIDRs
+	if limit <= 0 {
+		meta.RemoveStatusCondition(&resource.Status.Conditions, botv1alpha1.ConditionCIDRsTruncated)
+		return cidrs, ""
+	}
+
+	var ingressDropped, egressDropped int
+	cidrs.Ingress, ingressDropped = capCIDRs(cidrs.Ingress, limit)
+	cidrs.Egress, egressDropped = capCIDRs(cidrs.Egress, limit)
+
+	condition := metav1.Condition{
+		Type:               botv1alpha1.ConditionCIDRsTruncated,
+		Status:             metav1.ConditionFalse,
+		Reason:             "WithinMaxCIDRs",
+		Message:            fmt.Sprintf("every direction holds at most maxCidrs %d CIDRs", limit),
+		ObservedGeneration: resource.Generation,
+	}
+	var message string
+	if ingressDropped > 0 || egressDropped > 0 {
+		message = fmt.Sprintf("collected CIDRs exceeded maxCidrs %d; dropped %d ingress and %d egress CIDRs", limit, ingressDropped, egressDropped)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonMaxCIDRsExceeded
+		condition.Message = message
+	}
+	meta.SetStatusCondition(&resource.Status.Conditions, condition)
+	return cidrs, message
+}
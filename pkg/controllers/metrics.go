@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	providerFetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "botnp_provider_fetch_duration_seconds",
+		Help:    "Duration of provider fetches in seconds, labeled by provider display name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	providerFetchErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "botnp_provider_fetch_errors_total",
+		Help: "Failed provider fetches, including empty feeds, labeled by provider display name.",
+	}, []string{"provider"})
+
+	providerUnchangedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "botnp_provider_unchanged_total",
+		Help: "Provider fetches whose CIDR set was unchanged since the previous sync, so processing was skipped, labeled by provider display name.",
+	}, []string{"provider"})
+
+	// providerTypeCIDRs is labeled by provider type rather than display name to keep its
+	// cardinality bounded by the number of supported providers.
+	providerTypeCIDRs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "botnetworkpolicy_provider_cidrs",
+		Help: "CIDRs contributed by providers across all syncs, labeled by provider type.",
+	}, []string{"provider_type"})
+
+	// policyCIDRCount is deleted along with its BotNetworkPolicy, so that removed resources do
+	// not keep reporting their last value.
+	policyCIDRCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "botnp_cidrs_total",
+		Help: "Distinct CIDRs applied by the generated NetworkPolicies of a BotNetworkPolicy in its latest sync.",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(providerFetchDuration, providerFetchErrors, providerUnchangedTotal, providerTypeCIDRs, policyCIDRCount)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// normalizeCIDR masks host bits so that equivalent notations compare equal. Values that
+// are not CIDRs are returned trimmed but otherwise unchanged.
+func normalizeCIDR(cidr string) string {
+	cidr = strings.TrimSpace(cidr)
+	if prefix, err := netip.ParsePrefix(cidr); err == nil {
+		return prefix.Masked().String()
+	}
+	return cidr
+}
+
+// stripZone removes an IPv6 zone identifier, as in "fe80::1%eth0/64", from cidr. ok is
+// false when the zoned address is not a global unicast address, since its zone is what
+// made it meaningful and the entry should be dropped instead. Values without a zone are
+// returned unchanged.
+func stripZone(cidr string) (stripped string, ok bool) {
+	addr, rest, found := strings.Cut(cidr, "%")
+	if !found {
+		return cidr, true
+	}
+	if _, bits, hasBits := strings.Cut(rest, "/"); hasBits {
+		stripped = addr + "/" + bits
+	} else {
+		stripped = addr
+	}
+	prefix, err := netip.ParsePrefix(stripped)
+	if err != nil {
+		parsed, addrErr := netip.ParseAddr(stripped)
+		if addrErr != nil {
+			return cidr, false
+		}
+		prefix = netip.PrefixFrom(parsed, parsed.BitLen())
+		stripped = prefix.String()
+	}
+	if !prefix.Addr().Is6() || !prefix.Addr().IsGlobalUnicast() {
+		return cidr, false
+	}
+	return stripped, true
+}
+
+// normalizeHostBits applies normalizeCIDR to every entry, dropping blanks. It also returns
+// each entry that had host bits set or an IPv6 zone stripped as "original -> normalized",
+// so feeds can be cleaned up, and the zoned entries dropped for not being global.
+func normalizeHostBits(cidrs []string) (normalized, rewritten, nonGlobal []string) {
+	normalized = make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		trimmed := strings.TrimSpace(cidr)
+		if trimmed == "" {
+			continue
+		}
+		unzoned, ok := stripZone(trimmed)
+		if !ok {
+			nonGlobal = append(nonGlobal, trimmed)
+			continue
+		}
+		result := normalizeCIDR(unzoned)
+		if result != trimmed {
+			rewritten = append(rewritten, trimmed+" -> "+result)
+		}
+		normalized = append(normalized, result)
+	}
+	return normalized, rewritten, nonGlobal
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// podSelectorFor returns the resource's pod selector; an unset selector matches every
+// pod in the namespace, as in the generated NetworkPolicy.
+func podSelectorFor(resource *botv1alpha1.BotNetworkPolicy) (labels.Selector, error) {
+	podSelector := metav1.LabelSelector{}
+	if resource.Spec.PodSelector != nil {
+		podSelector = *resource.Spec.PodSelector
+	}
+	return metav1.LabelSelectorAsSelector(&podSelector)
+}
+
+// checkOverlappingSelectors records the OverlappingSelectors condition on the resource
+// without persisting it. Another BotNetworkPolicy in the namespace overlaps when at
+// least one existing pod matches both pod selectors. It returns a warning message when
+// overlaps were found.
+func (r *BotNetworkPolicyReconciler) checkOverlappingSelectors(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy) (string, error) {
+	selector, err := podSelectorFor(resource)
+	if err != nil {
+		return "", fmt.Errorf("invalid pod selector: %w", err)
+	}
+
+	var others botv1alpha1.BotNetworkPolicyList
+	if err := r.List(ctx, &others, client.InNamespace(resource.Namespace)); err != nil {
+		return "", err
+	}
+	var pods corev1.PodList
+	if err := r.reader().List(ctx, &pods, client.InNamespace(resource.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", err
+	}
+
+	var overlapping []string
+	for i := range others.Items {
+		other := &others.Items[i]
+		if other.Name == resource.Name || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		otherSelector, err := podSelectorFor(other)
+		if err != nil {
+			continue
+		}
+		for _, pod := range pods.Items {
+			if otherSelector.Matches(labels.Set(pod.Labels)) {
+				overlapping = append(overlapping, other.Name)
+				break
+			}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               botv1alpha1.ConditionOverlappingSelectors,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoOverlap",
+		Message:            "no other BotNetworkPolicy selects the same pods",
+		ObservedGeneration: resource.Generation,
+	}
+	var message string
+	if len(overlapping) > 0 {
+		sort.Strings(overlapping)
+		message = fmt.Sprintf("pod selector overlaps with BotNetworkPolicy %s; the selected pods receive the union of their rules", strings.Join(overlapping, ", "))
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "SelectorsOverlap"
+		condition.Message = message
+	}
+	meta.SetStatusCondition(&resource.Status.Conditions, condition)
+	return message, nil
+}
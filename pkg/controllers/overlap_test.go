@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestReconcile_WarnOnOverlappingSelectors(t *testing.T) {
+	policy := func(name string, matchLabels map[string]string) *botv1alpha1.BotNetworkPolicy {
+		return &botv1alpha1.BotNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: botv1alpha1.BotNetworkPolicySpec{
+				PodSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+				CustomCIDRs: []string{"10.0.0.0/24"},
+			},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default", Labels: map[string]string{"app": "web", "tier": "frontend"}},
+	}
+
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(
+			policy("crawlers", map[string]string{"app": "web"}),
+			policy("frontends", map[string]string{"tier": "frontend"}),
+			policy("workers", map[string]string{"app": "worker"}),
+			pod,
+		).
+		WithStatusSubresource(&botv1alpha1.BotNetworkPolicy{}).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	reconciler.WarnOnOverlappingSelectors = true
+
+	for _, tt := range []struct {
+		name        string
+		wantOverlap bool
+	}{
+		{name: "crawlers", wantOverlap: true},
+		{name: "workers", wantOverlap: false},
+	} {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: tt.name, Namespace: "default"}}
+		if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("Reconcile(%s) error = %v", tt.name, err)
+		}
+
+		var warning string
+		for len(recorder.Events) > 0 {
+			if event := <-recorder.Events; strings.Contains(event, "OverlappingSelectors") {
+				warning = event
+			}
+		}
+		if got := warning != ""; got != tt.wantOverlap {
+			t.Errorf("%s: OverlappingSelectors warning emitted = %v, want %v", tt.name, got, tt.wantOverlap)
+		}
+		if tt.wantOverlap && !strings.Contains(warning, "frontends") {
+			t.Errorf("%s: warning %q does not name the overlapping policy", tt.name, warning)
+		}
+
+		var updated botv1alpha1.BotNetworkPolicy
+		if err := kubeClient.Get(context.Background(), req.NamespacedName, &updated); err != nil {
+			t.Fatalf("Get(%s) error = %v", tt.name, err)
+		}
+		condition := meta.FindStatusCondition(updated.Status.Conditions, botv1alpha1.ConditionOverlappingSelectors)
+		if condition == nil {
+			t.Fatalf("%s: OverlappingSelectors condition not set", tt.name)
+		}
+		if got := condition.Status == metav1.ConditionTrue; got != tt.wantOverlap {
+			t.Errorf("%s: OverlappingSelectors condition = %s, want overlap %v", tt.name, condition.Status, tt.wantOverlap)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// PinAnnotation set to "true" pins a BotNetworkPolicy to its last applied snapshot: the
+// controller stops fetching providers and leaves the generated NetworkPolicies untouched
+// until the annotation is removed.
+const PinAnnotation = "bot.networking.dev/pin"
+
+func pinned(resource *botv1alpha1.BotNetworkPolicy) bool {
+	return resource.Annotations[PinAnnotation] == "true"
+}
+
+// holdPinned records when the resource was pinned and reports the pin on the Ready
+// condition. Ready stays True only when a snapshot was applied before the pin.
+func (r *BotNetworkPolicyReconciler) holdPinned(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy) error {
+	if resource.Status.PinnedSince == nil {
+		since := metav1.NewTime(r.currentTime())
+		resource.Status.PinnedSince = &since
+	}
+	status := metav1.ConditionTrue
+	if resource.Status.LastSyncTime == nil {
+		status = metav1.ConditionFalse
+	}
+	return r.setReadyCondition(ctx, resource, status, ReasonPinned, "provider results are pinned by the "+PinAnnotation+" annotation; the applied CIDRs are kept until it is removed")
+}
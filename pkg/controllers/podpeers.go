@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// podPeerResyncPeriod bounds how long the IPs of egress pod peers may go stale, since pod
+// changes do not trigger a reconcile.
+const podPeerResyncPeriod = time.Minute
+
+// hasPodPeers reports whether resource allows egress to the pods of selected namespaces.
+func hasPodPeers(resource *botv1alpha1.BotNetworkPolicy) bool {
+	return resource.Spec.EgressEnabled() && resource.Spec.EgressPodNamespaceSelector != nil
+}
+
+// withPodPeers returns cidrs with a host CIDR added to the egress CIDRs for every IP of
+// the egress pod peers of resource.
+func (r *BotNetworkPolicyReconciler) withPodPeers(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy, cidrs directionalCIDRs) (directionalCIDRs, error) {
+	if !hasPodPeers(resource) {
+		return cidrs, nil
+	}
+	podCIDRs, err := r.resolvePodCIDRs(ctx, resource)
+	if err != nil {
+		return cidrs, err
+	}
+	cidrs.Egress = sets.List(sets.New(cidrs.Egress...).Insert(podCIDRs...))
+	return cidrs, nil
+}
+
+// resolvePodCIDRs returns a host CIDR for every IP of the running pods in namespaces
+// matching the resource's EgressPodNamespaceSelector, sorted and deduplicated.
+func (r *BotNetworkPolicyReconciler) resolvePodCIDRs(ctx context.Context, resource *botv1alpha1.BotNetworkPolicy) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(resource.Spec.EgressPodNamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid egress pod namespace selector: %w", err)
+	}
+
+	var namespaces corev1.NamespaceList
+	if err := r.reader().List(ctx, &namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("list namespaces for egress pod peers: %w", err)
+	}
+
+	cidrs := sets.New[string]()
+	for _, namespace := range namespaces.Items {
+		var pods corev1.PodList
+		if err := r.reader().List(ctx, &pods, client.InNamespace(namespace.Name)); err != nil {
+			return nil, fmt.Errorf("list pods in namespace %s for egress pod peers: %w", namespace.Name, err)
+		}
+		for i := range pods.Items {
+			for _, ip := range podIPs(&pods.Items[i]) {
+				cidrs.Insert(netip.PrefixFrom(ip, ip.BitLen()).String())
+			}
+		}
+	}
+	return sets.List(cidrs), nil
+}
+
+// podIPs returns the parsed IPs of a pod that can still send or receive traffic. Pods
+// that are terminating, finished or not yet scheduled with an address yield none.
+func podIPs(pod *corev1.Pod) []netip.Addr {
+	if !pod.DeletionTimestamp.IsZero() || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return nil
+	}
+	raw := make([]string, 0, len(pod.Status.PodIPs)+1)
+	for _, podIP := range pod.Status.PodIPs {
+		raw = append(raw, podIP.IP)
+	}
+	if len(raw) == 0 && pod.Status.PodIP != "" {
+		raw = append(raw, pod.Status.PodIP)
+	}
+	ips := make([]netip.Addr, 0, len(raw))
+	for _, value := range raw {
+		ip, err := netip.ParseAddr(value)
+		if err != nil {
+			continue
+		}
+		ips = append(ips, ip.Unmap())
+	}
+	return ips
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestWithPodPeers_EgressPodNamespaceSelector(t *testing.T) {
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	pod := func(namespace, name string, phase corev1.PodPhase, ips ...string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		for _, ip := range ips {
+			p.Status.PodIPs = append(p.Status.PodIPs, corev1.PodIP{IP: ip})
+		}
+		return p
+	}
+
+	kubeClient := newTestClientBuilder(t).
+		WithObjects(
+			namespace("backends", map[string]string{"tier": "backend"}),
+			namespace("frontends", map[string]string{"tier": "frontend"}),
+			pod("backends", "api", corev1.PodRunning, "10.244.1.5", "fd00::5"),
+			pod("backends", "db", corev1.PodRunning, "10.244.1.7"),
+			pod("backends", "migrate", corev1.PodSucceeded, "10.244.1.9"),
+			pod("backends", "pending", corev1.PodPending),
+			pod("frontends", "web", corev1.PodRunning, "10.244.2.3"),
+		).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+
+	egress := true
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: botv1alpha1.BotNetworkPolicySpec{
+			Egress:                     &egress,
+			CustomCIDRs:                []string{"203.0.113.0/24"},
+			EgressPodNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}},
+		},
+	}
+
+	collected, _, err := reconciler.collectDirectionalCIDRs(context.Background(), resource, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectDirectionalCIDRs() error = %v", err)
+	}
+	cidrs, err := reconciler.withPodPeers(context.Background(), resource, collected)
+	if err != nil {
+		t.Fatalf("withPodPeers() error = %v", err)
+	}
+	want := []string{"10.244.1.5/32", "10.244.1.7/32", "203.0.113.0/24", "fd00::5/128"}
+	if !reflect.DeepEqual(cidrs.Egress, want) {
+		t.Fatalf("egress CIDRs = %v, want %v", cidrs.Egress, want)
+	}
+	if !reflect.DeepEqual(cidrs.Ingress, []string{"203.0.113.0/24"}) {
+		t.Errorf("ingress CIDRs = %v, want pod IPs excluded", cidrs.Ingress)
+	}
+
+	np := buildNetworkPolicy(resource, cidrs)
+	if len(np.Spec.Egress) != 1 || len(np.Spec.Egress[0].To) != len(want) {
+		t.Fatalf("unexpected egress rules: %#v", np.Spec.Egress)
+	}
+	for i, peer := range np.Spec.Egress[0].To {
+		if peer.IPBlock == nil || peer.IPBlock.CIDR != want[i] {
+			t.Errorf("egress peer %d = %#v, want IPBlock %s", i, peer, want[i])
+		}
+	}
+}
+
+func TestReconcile_PodPeersRefreshedWithinSyncPeriod(t *testing.T) {
+	egress := true
+	resource := newResource()
+	resource.Spec.Egress = &egress
+	resource.Spec.EgressPodNamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}}
+	backends := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "backends", Labels: map[string]string{"tier": "backend"}}}
+	api := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "backends"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIPs: []corev1.PodIP{{IP: "10.244.1.5"}}},
+	}
+	reconciler := newTestReconciler(t, resource, backends, api)
+	kubeClient := reconciler.Client
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(resource)}
+	egressPeers := func() []string {
+		t.Helper()
+		result, err := reconciler.Reconcile(ctx, req)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if result.RequeueAfter <= 0 || result.RequeueAfter > podPeerResyncPeriod {
+			t.Errorf("RequeueAfter = %s, want at most %s", result.RequeueAfter, podPeerResyncPeriod)
+		}
+		var policy networkingv1.NetworkPolicy
+		if err := kubeClient.Get(ctx, types.NamespacedName{Name: resource.NetworkPolicyName(), Namespace: "default"}, &policy); err != nil {
+			t.Fatalf("get networkpolicy: %v", err)
+		}
+		var peers []string
+		for _, peer := range policy.Spec.Egress[0].To {
+			peers = append(peers, peer.IPBlock.CIDR)
+		}
+		return peers
+	}
+
+	if got, want := egressPeers(), []string{"10.0.0.0/24", "10.244.1.5/32"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("egress peers = %v, want %v", got, want)
+	}
+
+	// The pod is rescheduled with a new IP before the providers are due again.
+	api.Status.PodIPs = []corev1.PodIP{{IP: "10.244.3.9"}}
+	if err := kubeClient.Status().Update(ctx, api); err != nil {
+		t.Fatalf("update pod: %v", err)
+	}
+	if got, want := egressPeers(), []string{"10.0.0.0/24", "10.244.3.9/32"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("egress peers after the pod moved = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+	"github.com/sugaf1204/botnetworkpolicy-operator/pkg/providers"
+)
+
+// probeTarget is a provider to probe, or the error that prevented building it.
+type probeTarget struct {
+	label    string
+	provider providers.Provider
+	err      error
+}
+
+type probeResult struct {
+	label string
+	// fetched is false when the provider could not be built and was never fetched.
+	fetched bool
+	latency time.Duration
+	cidrs   int
+	err     error
+}
+
+// ProbeProviders fetches every provider of resource once through factory and writes a
+// table of the outcome, latency and CIDR count of each to out. Nothing is written to the
+// cluster. It returns how many providers failed.
+func ProbeProviders(ctx context.Context, factory *providers.Factory, resource *botv1alpha1.BotNetworkPolicy, out io.Writer) (int, error) {
+	namespace := resource.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	var targets []probeTarget
+	for _, list := range []struct {
+		prefix string
+		specs  []botv1alpha1.ProviderSpec
+	}{
+		{"", resource.Spec.Providers},
+		{"ingress/", resource.Spec.IngressProviders},
+		{"egress/", resource.Spec.EgressProviders},
+	} {
+		resolved, resolveErrs := factory.ResolveAll(ctx, namespace, list.specs)
+		built, errs := factory.BuildAll(namespace, resolved)
+		for i, spec := range resolved {
+			err := resolveErrs[i]
+			if err == nil {
+				err = errs[i]
+			}
+			targets = append(targets, probeTarget{label: list.prefix + spec.Label(), provider: built[i], err: err})
+		}
+	}
+
+	results := runProbes(ctx, targets, time.Now)
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	return failed, writeProbeTable(out, results)
+}
+
+// runProbes fetches each buildable target once, in order, timing it with now.
+func runProbes(ctx context.Context, targets []probeTarget, now func() time.Time) []probeResult {
+	results := make([]probeResult, 0, len(targets))
+	for _, target := range targets {
+		result := probeResult{label: target.label, err: target.err}
+		if target.err == nil {
+			result.fetched = true
+			start := now()
+			cidrs, err := target.provider.Fetch(ctx)
+			result.latency = now().Sub(start)
+			result.cidrs, result.err = len(cidrs), err
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func writeProbeTable(out io.Writer, results []probeResult) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tRESULT\tLATENCY\tCIDRS\tERROR")
+	for _, result := range results {
+		status, latency, cidrs, message := "PASS", "-", fmt.Sprint(result.cidrs), "-"
+		if result.fetched {
+			latency = result.latency.Round(time.Millisecond).String()
+		}
+		if result.err != nil {
+			status, cidrs, message = "FAIL", "-", result.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.label, status, latency, cidrs, message)
+	}
+	return w.Flush()
+}
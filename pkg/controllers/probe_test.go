@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubProvider struct {
+	cidrs []string
+	err   error
+}
+
+func (p stubProvider) Fetch(context.Context) ([]string, error) {
+	return p.cidrs, p.err
+}
+
+func TestProbeTable(t *testing.T) {
+	targets := []probeTarget{
+		{label: "google", provider: stubProvider{cidrs: []string{"192.0.2.0/24", "198.51.100.0/24"}}},
+		{label: "ingress/partner", provider: stubProvider{err: errors.New("unexpected status: 503 Service Unavailable")}},
+		{label: "egress/broken", err: errors.New("jsonEndpoint.url is required")},
+	}
+	clock := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	now := func() time.Time {
+		clock = clock.Add(120 * time.Millisecond)
+		return clock
+	}
+
+	var out bytes.Buffer
+	if err := writeProbeTable(&out, runProbes(context.Background(), targets, now)); err != nil {
+		t.Fatalf("writeProbeTable() error = %v", err)
+	}
+
+	want := "" +
+		"PROVIDER         RESULT  LATENCY  CIDRS  ERROR\n" +
+		"google           PASS    120ms    2      -\n" +
+		"ingress/partner  FAIL    120ms    -      unexpected status: 503 Service Unavailable\n" +
+		"egress/broken    FAIL    -        -      jsonEndpoint.url is required\n"
+	if out.String() != want {
+		t.Errorf("probe table =\n%s\nwant\n%s", out.String(), want)
+	}
+}
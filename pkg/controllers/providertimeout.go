@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// errProviderTimeout marks a fetch cut short by the provider's timeoutSeconds.
+var errProviderTimeout = errors.New("provider timed out")
+
+// providerContext bounds ctx by the provider's timeoutSeconds, if set.
+func providerContext(ctx context.Context, spec botv1alpha1.ProviderSpec) (context.Context, context.CancelFunc) {
+	if spec.TimeoutSeconds <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(spec.TimeoutSeconds)*time.Second)
+}
+
+// providerTimeoutError wraps err in errProviderTimeout when fetchCtx, derived from ctx by
+// providerContext, expired while ctx itself is still live. Providers do not all wrap the
+// context error, so the deadline is read from the context rather than from err.
+func providerTimeoutError(ctx, fetchCtx context.Context, spec botv1alpha1.ProviderSpec, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w after %ds: %v", errProviderTimeout, spec.TimeoutSeconds, err)
+}
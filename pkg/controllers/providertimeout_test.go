@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestCollectCIDRs_ProviderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+	reconciler := newTestReconciler(t, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "bots", Namespace: "default"}, Data: map[string]string{"cidrs": "203.0.113.0/24"}})
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	reconciler.HTTPClient = server.Client()
+	resource := &botv1alpha1.BotNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"}}
+	specs := []botv1alpha1.ProviderSpec{
+		{Name: "jsonEndpoint", JSONEndpoint: &botv1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "."}, TimeoutSeconds: 1},
+		{Name: "configMap", ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "bots", Key: "cidrs"}},
+	}
+
+	start := time.Now()
+	cidrs, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("collectCIDRs() took %v despite a 1s provider timeout", elapsed)
+	}
+	if !slices.Equal(cidrs, []string{"203.0.113.0/24"}) {
+		t.Errorf("cidrs = %v, want the configMap provider's CIDR", cidrs)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings %v; the timeout should only be reported as a ProviderTimeout event", warnings)
+	}
+	select {
+	case got := <-recorder.Events:
+		if !strings.HasPrefix(got, "Warning "+ReasonProviderTimeout+" provider jsonEndpoint skipped") {
+			t.Errorf("event = %q, want a ProviderTimeout warning", got)
+		}
+	default:
+		t.Error("expected a ProviderTimeout event")
+	}
+	if status := resource.Status.ProviderStatuses[0]; !strings.Contains(status.LastError, "timed out") {
+		t.Errorf("jsonEndpoint lastError = %q, want a timeout", status.LastError)
+	}
+}
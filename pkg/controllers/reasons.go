@@ -0,0 +1,67 @@
+package controllers
+
+// Reasons attached to the events and Ready condition reported for a BotNetworkPolicy, so
+// that automation can match them exactly instead of parsing messages.
+const (
+	// ReasonPending is the Ready reason until the first sync completes.
+	ReasonPending = "Pending"
+	// ReasonSynced is the Ready reason after providers were synced and policies applied.
+	ReasonSynced = "Synced"
+	// ReasonInvalidSpec reports a spec rejected by validation.
+	ReasonInvalidSpec = "InvalidSpec"
+	// ReasonTooManyProviders reports a spec declaring more providers than --max-providers.
+	ReasonTooManyProviders = "TooManyProviders"
+	// ReasonNoSources reports a spec without providers or custom CIDRs.
+	ReasonNoSources = "NoSources"
+
+	// ReasonCreatedPolicy reports that a generated NetworkPolicy was created.
+	ReasonCreatedPolicy = "CreatedPolicy"
+	// ReasonUpdatedPolicy reports that a generated NetworkPolicy was updated.
+	ReasonUpdatedPolicy = "UpdatedPolicy"
+	// ReasonNoChange reports a sync that neither created nor updated a NetworkPolicy.
+	ReasonNoChange = "NoChange"
+	// ReasonPolicyApplyFailed reports that a generated NetworkPolicy could not be written.
+	ReasonPolicyApplyFailed = "NetworkPolicyApplyFailed"
+	// ReasonUpdateDeferred reports CIDR changes held back until a maintenance window.
+	ReasonUpdateDeferred = "UpdateDeferred"
+	// ReasonPinned is the Ready reason while the pin annotation holds the applied snapshot.
+	ReasonPinned = "Pinned"
+	// ReasonDryRun is the Ready reason, and the reason of the event describing the planned
+	// changes, while spec.dryRun keeps NetworkPolicies from being written.
+	ReasonDryRun = "DryRun"
+
+	// ReasonProvidersSynced is the ProvidersHealthy reason when every provider synced.
+	ReasonProvidersSynced = "ProvidersSynced"
+	// ReasonProviderPartialFailure reports a sync in which some, but not all, providers
+	// failed; the policy was built from the remaining ones.
+	ReasonProviderPartialFailure = "ProviderPartialFailure"
+	// ReasonProviderTotalFailure reports a sync in which every provider failed, or CIDR
+	// collection failed as a whole.
+	ReasonProviderTotalFailure = "ProviderTotalFailure"
+	// ReasonProviderWarning carries an individual provider warning, e.g. a fetch error or
+	// CIDRs dropped outside the allowed supernets.
+	ReasonProviderWarning = "ProviderWarning"
+	// ReasonEmptyFeed reports a provider that returned a valid feed without CIDRs.
+	ReasonEmptyFeed = "EmptyFeed"
+	// ReasonProviderTimeout reports a provider skipped because its fetch exceeded
+	// timeoutSeconds.
+	ReasonProviderTimeout = "ProviderTimeout"
+	// ReasonInsecureTLS reports a provider fetching with TLS certificate verification
+	// disabled through insecureSkipTLSVerify, on every sync so that audits notice it.
+	ReasonInsecureTLS = "InsecureTLS"
+
+	// ReasonBaselineViolation reports generated rules missing baseline CIDRs.
+	ReasonBaselineViolation = "BaselineViolation"
+	// ReasonOverlappingSelectors reports another BotNetworkPolicy selecting the same pods.
+	ReasonOverlappingSelectors = "OverlappingSelectors"
+	// ReasonNoMatchingPods reports a pod selector that matches no pods.
+	ReasonNoMatchingPods = "NoMatchingPods"
+	// ReasonExceptLimitExceeded reports an IPBlock omitted for too many except entries.
+	ReasonExceptLimitExceeded = "ExceptLimitExceeded"
+	// ReasonUnusedExcept reports an exceptCidrs entry contained in no allowed CIDR.
+	ReasonUnusedExcept = "UnusedExcept"
+	// ReasonSecretDeleted reports the deletion of a Secret read by a provider.
+	ReasonSecretDeleted = "SecretDeleted"
+	// ReasonMaxCIDRsExceeded reports CIDRs left out of the generated rules by spec.maxCidrs.
+	ReasonMaxCIDRsExceeded = "MaxCIDRsExceeded"
+)
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// providerResultCache remembers the processed CIDRs of each provider per BotNetworkPolicy,
+// keyed by a hash of the fetched set, so an unchanged feed skips supernet filtering and
+// host bit normalization on the next sync.
+type providerResultCache struct {
+	mu      sync.Mutex
+	entries map[providerResultKey]providerResult
+}
+
+type providerResultKey struct {
+	object   types.NamespacedName
+	provider string
+}
+
+type providerResult struct {
+	hash       uint64
+	normalized []string
+	dropped    []string
+	// nonGlobal holds the zoned, non-global addresses dropped during normalization.
+	nonGlobal []string
+}
+
+// get returns the cached result for key when it was computed from input with hash.
+func (c *providerResultCache) get(key providerResultKey, hash uint64) (providerResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || entry.hash != hash {
+		return providerResult{}, false
+	}
+	return entry, true
+}
+
+func (c *providerResultCache) put(key providerResultKey, result providerResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[providerResultKey]providerResult)
+	}
+	c.entries[key] = result
+}
+
+// forget drops every entry of object, e.g. after it was deleted.
+func (c *providerResultCache) forget(object types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.object == object {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// hashCIDRs returns an FNV-1a hash of the fetched CIDRs and the supernets they are
+// filtered against, in order.
+func hashCIDRs(cidrs, supernets []string) uint64 {
+	h := fnv.New64a()
+	for _, cidr := range cidrs {
+		_, _ = h.Write([]byte(cidr))
+		_, _ = h.Write([]byte{0})
+	}
+	_, _ = h.Write([]byte{1})
+	for _, supernet := range supernets {
+		_, _ = h.Write([]byte(supernet))
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
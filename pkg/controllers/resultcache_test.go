@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestCollectCIDRs_SkipsUnchangedProviderResult(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "hashed", Namespace: "default"},
+		Data:       map[string]string{"cidrs": "203.0.113.7/24\n198.51.100.0/24"},
+	}
+	reconciler := newTestReconciler(t, configMap)
+	kubeClient := reconciler.Client
+	specs := []botv1alpha1.ProviderSpec{{
+		Name:        "configMap",
+		DisplayName: "hashed-feed",
+		ConfigMap:   &botv1alpha1.ConfigMapProviderSpec{Name: "hashed", Key: "cidrs"},
+	}}
+	resource := &botv1alpha1.BotNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"}}
+	unchanged := providerUnchangedTotal.WithLabelValues(specs[0].Label())
+
+	collect := func() (string, int) {
+		t.Helper()
+		rewrites := 0
+		logger := funcr.New(func(prefix, args string) {
+			if strings.Contains(args, "host bits") {
+				rewrites++
+			}
+		}, funcr.Options{})
+		cidrs, _, err := reconciler.collectCIDRs(context.Background(), resource, specs, logger)
+		if err != nil {
+			t.Fatalf("collectCIDRs() error = %v", err)
+		}
+		return strings.Join(cidrs, ","), rewrites
+	}
+
+	before := testutil.ToFloat64(unchanged)
+	first, rewrites := collect()
+	if first != "198.51.100.0/24,203.0.113.0/24" || rewrites != 1 {
+		t.Fatalf("first sync = %q with %d host bit logs, want normalized CIDRs and one log", first, rewrites)
+	}
+	if got := testutil.ToFloat64(unchanged) - before; got != 0 {
+		t.Fatalf("first sync counted %v unchanged results, want 0", got)
+	}
+
+	second, rewrites := collect()
+	if second != first {
+		t.Errorf("second sync = %q, want cached %q", second, first)
+	}
+	if rewrites != 0 {
+		t.Errorf("second sync logged %d host bit rewrites, want processing skipped", rewrites)
+	}
+	if got := testutil.ToFloat64(unchanged) - before; got != 1 {
+		t.Errorf("second sync counted %v unchanged results, want 1", got)
+	}
+
+	configMap.Data["cidrs"] = "192.0.2.0/24"
+	if err := kubeClient.Update(context.Background(), configMap); err != nil {
+		t.Fatalf("update configmap: %v", err)
+	}
+	if third, _ := collect(); third != "192.0.2.0/24" {
+		t.Errorf("sync after feed change = %q, want 192.0.2.0/24", third)
+	}
+	if got := testutil.ToFloat64(unchanged) - before; got != 1 {
+		t.Errorf("changed feed counted as unchanged: %v", got)
+	}
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// debugSampleAnnotation lists per-provider sampling fractions, e.g. "aws=0.01,google=0.5"
+// or "*=0.1". TESTING ONLY: it is ignored unless EnableDebugSampling is set, and exists so
+// developers can load-test with large but bounded CIDR sets.
+const debugSampleAnnotation = "bot.networking.dev/debug-sample-fractions"
+
+// sampleFractions parses the debug sampling annotation into fractions keyed by lowercase
+// provider label. Entries that fail to parse or fall outside (0, 1] are rejected.
+func sampleFractions(value string) (map[string]float64, error) {
+	fractions := map[string]float64{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		label, raw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("debug sample entry %q is not label=fraction", entry)
+		}
+		fraction, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || fraction <= 0 || fraction > 1 {
+			return nil, fmt.Errorf("debug sample entry %q needs a fraction in (0, 1]", entry)
+		}
+		fractions[strings.ToLower(strings.TrimSpace(label))] = fraction
+	}
+	return fractions, nil
+}
+
+// fractionFor returns the sampling fraction for a provider label, falling back to "*".
+func fractionFor(fractions map[string]float64, label string) (float64, bool) {
+	if fraction, ok := fractions[strings.ToLower(label)]; ok {
+		return fraction, true
+	}
+	fraction, ok := fractions["*"]
+	return fraction, ok
+}
+
+// sampleCIDRs keeps roughly fraction of cidrs, chosen by hashing each CIDR so the same
+// entries survive every sync and a larger fraction keeps a superset of a smaller one.
+func sampleCIDRs(cidrs []string, fraction float64) []string {
+	if fraction >= 1 {
+		return cidrs
+	}
+	threshold := uint64(fraction * (1 << 32))
+	sampled := make([]string, 0, int(float64(len(cidrs))*fraction)+1)
+	for _, cidr := range cidrs {
+		h := fnv.New32a()
+		h.Write([]byte(cidr))
+		if uint64(h.Sum32()) < threshold {
+			sampled = append(sampled, cidr)
+		}
+	}
+	return sampled
+}
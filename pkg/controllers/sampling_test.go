@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestSampleCIDRs(t *testing.T) {
+	cidrs := make([]string, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		cidrs = append(cidrs, fmt.Sprintf("10.%d.%d.0/24", i/256, i%256))
+	}
+
+	first := sampleCIDRs(cidrs, 0.1)
+	second := sampleCIDRs(cidrs, 0.1)
+	if strings.Join(first, ",") != strings.Join(second, ",") {
+		t.Fatal("sampleCIDRs() is not deterministic")
+	}
+	if got := len(first); got < 900 || got > 1100 {
+		t.Errorf("sampleCIDRs(0.1) kept %d of 10000, want about 1000", got)
+	}
+
+	larger := sets.New(sampleCIDRs(cidrs, 0.5)...)
+	if !larger.HasAll(first...) {
+		t.Error("sampleCIDRs(0.5) is not a superset of sampleCIDRs(0.1)")
+	}
+	if got := len(sampleCIDRs(cidrs, 1)); got != len(cidrs) {
+		t.Errorf("sampleCIDRs(1) kept %d, want all %d", got, len(cidrs))
+	}
+}
+
+func TestSampleFractions(t *testing.T) {
+	fractions, err := sampleFractions("AWS=0.25, *=0.5")
+	if err != nil {
+		t.Fatalf("sampleFractions() error = %v", err)
+	}
+	if got, _ := fractionFor(fractions, "aws"); got != 0.25 {
+		t.Errorf("fraction for aws = %g, want 0.25", got)
+	}
+	if got, _ := fractionFor(fractions, "google"); got != 0.5 {
+		t.Errorf("fraction for google = %g, want the 0.5 wildcard", got)
+	}
+	for _, invalid := range []string{"aws", "aws=0", "aws=1.5", "aws=x"} {
+		if _, err := sampleFractions(invalid); err == nil {
+			t.Errorf("sampleFractions(%q) error = nil, want error", invalid)
+		}
+	}
+}
+
+func TestCollectCIDRs_DebugSamplingRequiresFlag(t *testing.T) {
+	lines := make([]string, 0, 200)
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf("10.0.%d.0/24", i))
+	}
+	feed := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "feed", Namespace: "default"},
+		Data:       map[string]string{"cidrs": strings.Join(lines, "\n")},
+	}
+	resource := &botv1alpha1.BotNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sample",
+			Namespace:   "default",
+			Annotations: map[string]string{debugSampleAnnotation: "configMap=0.25"},
+		},
+	}
+	specs := []botv1alpha1.ProviderSpec{{
+		Name:      "configMap",
+		ConfigMap: &botv1alpha1.ConfigMapProviderSpec{Name: "feed", Key: "cidrs"},
+	}}
+
+	reconciler := newTestReconciler(t, feed)
+	cidrs, _, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	if len(cidrs) != 200 {
+		t.Errorf("collectCIDRs() without the flag = %d CIDRs, want all 200", len(cidrs))
+	}
+
+	reconciler = newTestReconciler(t, feed)
+	reconciler.EnableDebugSampling = true
+	cidrs, warnings, err := reconciler.collectCIDRs(context.Background(), resource, specs, logr.Discard())
+	if err != nil {
+		t.Fatalf("collectCIDRs() error = %v", err)
+	}
+	if len(cidrs) == 0 || len(cidrs) >= 100 {
+		t.Errorf("collectCIDRs() with sampling = %d CIDRs, want about 50", len(cidrs))
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "sampled") {
+		t.Errorf("warnings = %v, want one sampling warning", warnings)
+	}
+}
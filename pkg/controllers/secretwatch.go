@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// secretRefIndex indexes BotNetworkPolicies by the Secrets their providers read, as
+// "namespace/name". Providers only read Secrets from the namespace of the resource.
+const secretRefIndex = "spec.providers.secretRefs"
+
+// secretRefs returns the secretRefIndex values of resource: the Secrets of header secret
+// refs, jsonEndpoint credentials and client certificates, CA bundles, HMAC signing keys,
+// proxy credentials, GitHub tokens and Redis connections.
+func secretRefs(resource *botv1alpha1.BotNetworkPolicy) []string {
+	seen := map[string]bool{}
+	var refs []string
+	add := func(name string) {
+		key := resource.Namespace + "/" + name
+		if name != "" && !seen[key] {
+			seen[key] = true
+			refs = append(refs, key)
+		}
+	}
+	addHeaders := func(headers []botv1alpha1.HTTPHeaderSecretRef) {
+		for _, header := range headers {
+			add(header.SecretKeyRef.Name)
+		}
+	}
+	for _, list := range [][]botv1alpha1.ProviderSpec{resource.Spec.Providers, resource.Spec.IngressProviders, resource.Spec.EgressProviders} {
+		for _, spec := range list {
+			if spec.JSONEndpoint != nil {
+				addHeaders(spec.JSONEndpoint.HeaderSecretRefs)
+				if ref := spec.JSONEndpoint.BasicAuthSecretRef; ref != nil {
+					add(ref.Name)
+				}
+				if ref := spec.JSONEndpoint.BearerTokenSecretRef; ref != nil {
+					add(ref.Name)
+				}
+				if ref := spec.JSONEndpoint.TLSClientSecretRef; ref != nil {
+					add(ref.Name)
+				}
+			}
+			if spec.Directory != nil {
+				addHeaders(spec.Directory.HeaderSecretRefs)
+			}
+			if ref, _ := spec.CABundleRefs(); ref != nil {
+				add(ref.Name)
+			}
+			if spec.HMACSigning != nil {
+				add(spec.HMACSigning.SecretKeyRef.Name)
+			}
+			if spec.ProxyCredentialsSecretRef != nil {
+				add(spec.ProxyCredentialsSecretRef.Name)
+			}
+			if spec.GitHub != nil && spec.GitHub.TokenSecretRef != nil {
+				add(spec.GitHub.TokenSecretRef.Name)
+			}
+			if spec.Redis != nil {
+				add(spec.Redis.ConnectionSecretRef.Name)
+			}
+		}
+	}
+	return refs
+}
+
+func indexSecretRefs(obj client.Object) []string {
+	resource, ok := obj.(*botv1alpha1.BotNetworkPolicy)
+	if !ok {
+		return nil
+	}
+	return secretRefs(resource)
+}
+
+// policiesForSecret lists the BotNetworkPolicies reading secret.
+func (r *BotNetworkPolicyReconciler) policiesForSecret(ctx context.Context, secret client.Object) []botv1alpha1.BotNetworkPolicy {
+	var list botv1alpha1.BotNetworkPolicyList
+	if err := r.List(ctx, &list, client.MatchingFields{secretRefIndex: secret.GetNamespace() + "/" + secret.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list BotNetworkPolicies referencing Secret", "secret", client.ObjectKeyFromObject(secret))
+		return nil
+	}
+	return list.Items
+}
+
+// requestsForSecret maps a Secret to the BotNetworkPolicies reading it, and forgets their
+// last fetch so that rotated credentials are used right away.
+func (r *BotNetworkPolicyReconciler) requestsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	policies := r.policiesForSecret(ctx, secret)
+	requests := make([]reconcile.Request, 0, len(policies))
+	for i := range policies {
+		key := client.ObjectKeyFromObject(&policies[i])
+		r.fetches.forget(key)
+		requests = append(requests, reconcile.Request{NamespacedName: key})
+	}
+	return requests
+}
+
+// secretEventHandler enqueues the BotNetworkPolicies reading a changed Secret. When the
+// Secret is deleted it also emits a SecretDeleted warning on each of them, since the
+// provider errors of the following sync do not say why the credentials went missing.
+func (r *BotNetworkPolicyReconciler) secretEventHandler() handler.EventHandler {
+	enqueue := handler.EnqueueRequestsFromMapFunc(r.requestsForSecret)
+	return handler.Funcs{
+		CreateFunc:  enqueue.Create,
+		UpdateFunc:  enqueue.Update,
+		GenericFunc: enqueue.Generic,
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+			if r.Recorder != nil {
+				policies := r.policiesForSecret(ctx, e.Object)
+				for i := range policies {
+					r.Recorder.Eventf(&policies[i], corev1.EventTypeWarning, ReasonSecretDeleted,
+						"Secret %s read by a provider was deleted; its fetches fail until the Secret is restored", e.Object.GetName())
+				}
+			}
+			enqueue.Delete(ctx, e, queue)
+		},
+	}
+}
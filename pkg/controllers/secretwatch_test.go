@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestSecretEventHandler(t *testing.T) {
+	jsonEndpoint := func(namespace, name, secret string) *botv1alpha1.BotNetworkPolicy {
+		return &botv1alpha1.BotNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: botv1alpha1.BotNetworkPolicySpec{Providers: []botv1alpha1.ProviderSpec{{
+				Name: "jsonEndpoint",
+				JSONEndpoint: &botv1alpha1.JSONEndpointProviderSpec{
+					URL:       "https://example.com/bots.json",
+					FieldPath: "cidrs",
+					HeaderSecretRefs: []botv1alpha1.HTTPHeaderSecretRef{{
+						Name:         "Authorization",
+						SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secret}, Key: "token"},
+					}},
+				},
+			}}},
+		}
+	}
+	kubeClient := newTestClientBuilder(t).
+		WithIndex(&botv1alpha1.BotNetworkPolicy{}, secretRefIndex, indexSecretRefs).
+		WithObjects(
+			jsonEndpoint("default", "api", "bot-endpoint-token"),
+			jsonEndpoint("other", "api", "bot-endpoint-token"),
+			jsonEndpoint("default", "unrelated", "another-token"),
+		).
+		Build()
+	reconciler := reconcilerFor(kubeClient)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	ctx := context.Background()
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bot-endpoint-token"}}
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+
+	handler := reconciler.secretEventHandler()
+	handler.Update(ctx, event.UpdateEvent{ObjectOld: secret, ObjectNew: secret}, queue)
+	if queue.Len() != 1 {
+		t.Fatalf("queued %d requests after a Secret update, want 1", queue.Len())
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("unexpected event after a Secret update: %s", <-recorder.Events)
+	}
+
+	item, _ := queue.Get()
+	queue.Done(item)
+	queue.Forget(item)
+	handler.Delete(ctx, event.DeleteEvent{Object: secret}, queue)
+	if queue.Len() != 1 {
+		t.Fatalf("queued %d requests after a Secret deletion, want 1", queue.Len())
+	}
+	select {
+	case got := <-recorder.Events:
+		if !strings.HasPrefix(got, "Warning SecretDeleted") || !strings.Contains(got, "bot-endpoint-token") {
+			t.Errorf("event = %q, want a SecretDeleted warning naming the Secret", got)
+		}
+	default:
+		t.Error("expected a SecretDeleted event")
+	}
+}
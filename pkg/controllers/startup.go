@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// startupSpreader delays the first full sync of each object by a random amount so that
+// mass creation (e.g. an initial GitOps sync) or an operator restart does not hit every
+// provider at the same instant.
+type startupSpreader struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]struct{}
+}
+
+// delay returns the requeue delay for the first reconcile of key, or zero when key has
+// already been seen or spreading is disabled.
+func (s *startupSpreader) delay(key types.NamespacedName, max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = make(map[types.NamespacedName]struct{})
+	}
+	if _, ok := s.seen[key]; ok {
+		return 0
+	}
+	s.seen[key] = struct{}{}
+	return time.Duration(rand.Int64N(int64(max))) + 1
+}
+
+// jitterSyncPeriod spreads a periodic resync, which is what polls provider feeds, by a
+// random amount of up to fraction of period in either direction, so replicas and
+// operators sharing an upstream do not poll it in lockstep. Fractions outside (0, 1) are
+// clamped; zero or less returns period unchanged.
+func jitterSyncPeriod(period time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || period <= 0 {
+		return period
+	}
+	fraction = min(fraction, 0.99)
+	offset := (rand.Float64()*2 - 1) * fraction * float64(period)
+	return period + time.Duration(offset)
+}
+
+// forget drops key so that a recreated object with the same name is spread again.
+func (s *startupSpreader) forget(key types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.seen, key)
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// WriteStatus prints a table of the BotNetworkPolicy objects in namespace to out, showing
+// the Ready condition, provider and CIDR counts, the last sync and any other condition
+// that is currently True, such as BaselineViolation or NoSources.
+func WriteStatus(ctx context.Context, reader client.Reader, namespace string, out io.Writer) error {
+	var list botv1alpha1.BotNetworkPolicyList
+	if err := reader.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("listing botnetworkpolicies: %w", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREADY\tREASON\tPROVIDERS\tCIDRS\tLAST SYNC\tCONDITIONS")
+	for i := range list.Items {
+		status := &list.Items[i].Status
+		ready, reason := "Unknown", "-"
+		if condition := meta.FindStatusCondition(status.Conditions, botv1alpha1.ConditionReady); condition != nil {
+			ready, reason = string(condition.Status), condition.Reason
+		}
+		cidrs := 0
+		for _, provider := range status.ProviderStatuses {
+			cidrs += provider.CIDRCount
+		}
+		lastSync := "-"
+		if status.LastSyncTime != nil {
+			lastSync = status.LastSyncTime.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n", list.Items[i].Name, ready, reason, status.ProviderCount, cidrs, lastSync, activeConditions(status.Conditions))
+	}
+	return w.Flush()
+}
+
+// activeConditions lists the types of True conditions other than Ready, or "-".
+func activeConditions(conditions []metav1.Condition) string {
+	var active []string
+	for _, condition := range conditions {
+		if condition.Type != botv1alpha1.ConditionReady && condition.Status == metav1.ConditionTrue {
+			active = append(active, condition.Type)
+		}
+	}
+	if len(active) == 0 {
+		return "-"
+	}
+	sort.Strings(active)
+	return strings.Join(active, ",")
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestWriteStatus(t *testing.T) {
+	synced := metav1.NewTime(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC))
+	objects := []*botv1alpha1.BotNetworkPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Status: botv1alpha1.BotNetworkPolicyStatus{
+				Conditions: []metav1.Condition{
+					{Type: botv1alpha1.ConditionReady, Status: metav1.ConditionTrue, Reason: ReasonSynced},
+					{Type: botv1alpha1.ConditionNoSources, Status: metav1.ConditionFalse, Reason: "SourcesConfigured"},
+				},
+				ProviderCount:    2,
+				ProviderStatuses: []botv1alpha1.ProviderStatus{{Name: "google", CIDRCount: 40}, {Name: "aws", CIDRCount: 2}},
+				LastSyncTime:     &synced,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Status: botv1alpha1.BotNetworkPolicyStatus{
+				Conditions: []metav1.Condition{
+					{Type: botv1alpha1.ConditionReady, Status: metav1.ConditionFalse, Reason: ReasonProviderTotalFailure},
+					{Type: botv1alpha1.ConditionNoSources, Status: metav1.ConditionTrue, Reason: "NoSourcesConfigured"},
+					{Type: botv1alpha1.ConditionBaselineViolation, Status: metav1.ConditionTrue, Reason: "MissingCIDRs"},
+				},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "elsewhere", Namespace: "other"}},
+	}
+	objs := make([]client.Object, 0, len(objects))
+	for _, obj := range objects {
+		objs = append(objs, obj)
+	}
+
+	var out bytes.Buffer
+	if err := WriteStatus(context.Background(), newTestClientBuilder(t, objs...).Build(), "default", &out); err != nil {
+		t.Fatalf("WriteStatus() error = %v", err)
+	}
+
+	want := "" +
+		"NAME  READY    REASON                PROVIDERS  CIDRS  LAST SYNC             CONDITIONS\n" +
+		"api   False    ProviderTotalFailure  0          0      -                     BaselineViolation,NoSources\n" +
+		"new   Unknown  -                     0          0      -                     -\n" +
+		"web   True     Synced                2          42     2024-05-01T10:30:00Z  -\n"
+	if out.String() != want {
+		t.Errorf("WriteStatus() output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// maxPendingNames bounds the BotNetworkPolicies named by a failing readiness check.
+const maxPendingNames = 5
+
+// SyncTracker records which BotNetworkPolicies were observed since the operator started,
+// either because their NetworkPolicies were applied or because they reached a terminal
+// InvalidSpec or apply failure, so that readiness can wait for the bot allowlists to
+// exist. It is safe for concurrent use; a nil SyncTracker records nothing.
+type SyncTracker struct {
+	mu       sync.Mutex
+	observed map[types.NamespacedName]struct{}
+	// ready latches once every BotNetworkPolicy was observed.
+	ready bool
+}
+
+// NewSyncTracker returns an empty SyncTracker.
+func NewSyncTracker() *SyncTracker {
+	return &SyncTracker{observed: map[types.NamespacedName]struct{}{}}
+}
+
+// markObserved records that key no longer holds up readiness.
+func (t *SyncTracker) markObserved(key types.NamespacedName) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observed[key] = struct{}{}
+}
+
+// forget drops key so that a recreated object with the same name must be observed again.
+func (t *SyncTracker) forget(key types.NamespacedName) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.observed, key)
+}
+
+// Checker returns a readiness check that fails until every BotNetworkPolicy listed by
+// reader, other than those being deleted or in dry run, was observed. It passes while
+// elected is open, since only the leader reconciles and a standby replica would otherwise
+// never become ready. Once the leader passes it keeps passing, so that BotNetworkPolicies
+// created later do not take the operator, and with it the admission webhook, out of
+// service.
+func (t *SyncTracker) Checker(reader client.Reader, elected <-chan struct{}) healthz.Checker {
+	return func(req *http.Request) error {
+		select {
+		case <-elected:
+		default:
+			return nil
+		}
+		t.mu.Lock()
+		ready := t.ready
+		t.mu.Unlock()
+		if ready {
+			return nil
+		}
+
+		var list botv1alpha1.BotNetworkPolicyList
+		if err := reader.List(req.Context(), &list); err != nil {
+			return fmt.Errorf("listing BotNetworkPolicies: %w", err)
+		}
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		var pending []string
+		var total int
+		for i := range list.Items {
+			item := &list.Items[i]
+			// Objects being deleted and dry runs never apply NetworkPolicies.
+			if !item.DeletionTimestamp.IsZero() || item.Spec.DryRunEnabled() {
+				continue
+			}
+			total++
+			if _, ok := t.observed[client.ObjectKeyFromObject(item)]; !ok {
+				pending = append(pending, item.Namespace+"/"+item.Name)
+			}
+		}
+		if len(pending) > 0 {
+			sort.Strings(pending)
+			count := len(pending)
+			if count > maxPendingNames {
+				pending = append(pending[:maxPendingNames], "...")
+			}
+			return fmt.Errorf("%d of %d BotNetworkPolicies have not synced yet: %s", count, total, strings.Join(pending, ", "))
+		}
+		t.ready = true
+		return nil
+	}
+}
+
+// markPreviouslySynced marks resource as observed when it skips applying NetworkPolicies,
+// because it is pinned, its changes are deferred or its CIDRs could not be collected, but
+// policies applied by an earlier sync, possibly before a restart, are in place.
+func (r *BotNetworkPolicyReconciler) markPreviouslySynced(resource *botv1alpha1.BotNetworkPolicy) {
+	if resource.Status.LastSyncTime == nil || resource.Spec.DryRunEnabled() {
+		return
+	}
+	r.SyncTracker.markObserved(client.ObjectKeyFromObject(resource))
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	botv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// newTrackedResource returns newResource renamed to name.
+func newTrackedResource(name string) *botv1alpha1.BotNetworkPolicy {
+	resource := newResource()
+	resource.Name = name
+	return resource
+}
+
+// electedChannel returns a closed channel, as Manager.Elected returns for the leader.
+func electedChannel() <-chan struct{} {
+	elected := make(chan struct{})
+	close(elected)
+	return elected
+}
+
+func TestSyncTracker_Checker(t *testing.T) {
+	dryRun := true
+	preview := newTrackedResource("preview")
+	preview.Spec.DryRun = &dryRun
+	reconciler := newTestReconciler(t, newTrackedResource("api"), newTrackedResource("web"), preview)
+	tracker := NewSyncTracker()
+	reconciler.SyncTracker = tracker
+	kubeClient := reconciler.Client
+	check := tracker.Checker(kubeClient, electedChannel())
+
+	ctx := context.Background()
+	reconcile := func(name string) {
+		t.Helper()
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}}
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile(%s) error = %v", name, err)
+		}
+	}
+	probe := func() error {
+		return check(httptest.NewRequest("GET", "/readyz", nil))
+	}
+
+	err := probe()
+	if err == nil || !strings.Contains(err.Error(), "2 of 2 BotNetworkPolicies have not synced yet: default/api, default/web") {
+		t.Fatalf("check before any sync = %v", err)
+	}
+
+	// Dry runs never apply NetworkPolicies and are not waited for.
+	reconcile("preview")
+	reconcile("web")
+	if err := probe(); err == nil || !strings.Contains(err.Error(), "1 of 2 BotNetworkPolicies have not synced yet: default/api") {
+		t.Fatalf("check after syncing web = %v", err)
+	}
+
+	reconcile("api")
+	if err := probe(); err != nil {
+		t.Fatalf("check after syncing every resource = %v", err)
+	}
+
+	// Readiness stays latched when resources are created later.
+	if err := kubeClient.Create(ctx, newTrackedResource("late")); err != nil {
+		t.Fatalf("create resource: %v", err)
+	}
+	if err := probe(); err != nil {
+		t.Errorf("check after creating another resource = %v", err)
+	}
+}
+
+func TestSyncTracker_ForgetsDeleted(t *testing.T) {
+	reconciler := newTestReconciler(t)
+	tracker := NewSyncTracker()
+	reconciler.SyncTracker = tracker
+
+	key := client.ObjectKey{Name: "sample", Namespace: "default"}
+	tracker.markObserved(key)
+	if _, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if _, ok := tracker.observed[key]; ok {
+		t.Errorf("deleted resource %s still recorded as observed", key)
+	}
+}
+
+func TestSyncTracker_CheckerPassesUntilElected(t *testing.T) {
+	kubeClient := newTestClientBuilder(t, newTrackedResource("web")).Build()
+	elected := make(chan struct{})
+	check := NewSyncTracker().Checker(kubeClient, elected)
+
+	if err := check(httptest.NewRequest("GET", "/readyz", nil)); err != nil {
+		t.Fatalf("check on a replica waiting for the lease = %v, want ready", err)
+	}
+	close(elected)
+	if err := check(httptest.NewRequest("GET", "/readyz", nil)); err == nil {
+		t.Error("check on the leader passed before web was synced")
+	}
+}
+
+func TestSyncTracker_CheckerObservesFailures(t *testing.T) {
+	invalid := newTrackedResource("invalid")
+	invalid.Spec.Providers = []botv1alpha1.ProviderSpec{{Name: "unknown"}}
+	kubeClient := newTestClientBuilder(t, invalid, newTrackedResource("rejected")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if _, ok := obj.(*networkingv1.NetworkPolicy); ok {
+					return errors.New("admission denied")
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).
+		Build()
+	tracker := NewSyncTracker()
+	reconciler := reconcilerFor(kubeClient)
+	reconciler.SyncTracker = tracker
+
+	ctx := context.Background()
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "invalid", Namespace: "default"}}); err != nil {
+		t.Fatalf("Reconcile(invalid) error = %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "rejected", Namespace: "default"}}); err == nil {
+		t.Fatal("Reconcile(rejected) succeeded, want the apply to fail")
+	}
+	if err := tracker.Checker(kubeClient, electedChannel())(httptest.NewRequest("GET", "/readyz", nil)); err != nil {
+		t.Errorf("check after an invalid spec and a failed apply = %v, want ready", err)
+	}
+}
+
+func TestSyncTracker_CheckerObservesPreviouslySyncedOnCollectFailure(t *testing.T) {
+	egress := true
+	peers := func(name string) *botv1alpha1.BotNetworkPolicy {
+		resource := newTrackedResource(name)
+		resource.Spec.Egress = &egress
+		resource.Spec.EgressPodNamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}}
+		return resource
+	}
+	synced := peers("synced")
+	lastSync := metav1.Now()
+	synced.Status.LastSyncTime = &lastSync
+	kubeClient := newTestClientBuilder(t, synced, peers("fresh")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: fakeServerSideApply,
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := list.(*corev1.NamespaceList); ok {
+					return errors.New("apiserver unavailable")
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+	tracker := NewSyncTracker()
+	reconciler := reconcilerFor(kubeClient)
+	reconciler.SyncTracker = tracker
+
+	ctx := context.Background()
+	for _, name := range []string{"synced", "fresh"} {
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "default"}}); err == nil {
+			t.Fatalf("Reconcile(%s) succeeded, want the collection to fail", name)
+		}
+	}
+	err := tracker.Checker(kubeClient, electedChannel())(httptest.NewRequest("GET", "/readyz", nil))
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 BotNetworkPolicies have not synced yet: default/fresh") {
+		t.Errorf("check after failed collections = %v, want only default/fresh pending", err)
+	}
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/sugaf1204/botnetworkpolicy-operator/pkg/controllers"
+
+// Span attribute keys shared by reconcile, collection and fetch spans.
+const (
+	attrProviderName  = attribute.Key("provider.name")
+	attrProviderCount = attribute.Key("provider.count")
+	attrCIDRCount     = attribute.Key("cidr.count")
+)
+
+// tracer returns the reconciler's tracer, falling back to the global provider, which is a
+// no-op unless tracing was configured at startup.
+func (r *BotNetworkPolicyReconciler) tracer() trace.Tracer {
+	tp := r.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
+
+func (r *BotNetworkPolicyReconciler) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return r.tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// responseBodyLogLevel is the verbosity at which raw provider responses are logged. The
+// factory must also enable body logging with WithResponseBodyLogging.
+const responseBodyLogLevel = 3
+
+// WithResponseBodyLogging logs up to maxBytes of every HTTP provider response body at
+// verbosity 3, with secret-looking values redacted. Zero, the default, disables it.
+func WithResponseBodyLogging(maxBytes int) FactoryOption {
+	return func(f *Factory) {
+		f.bodyLog = bodyLogger{limit: maxBytes}
+	}
+}
+
+// bodyLogger records the start of a response body for debug logging. The zero value logs
+// nothing.
+type bodyLogger struct {
+	limit int
+}
+
+// wrap returns a reader over body that keeps its first bytes, and a function that logs
+// them once the body has been consumed. When logging is disabled, body is returned as is.
+func (l bodyLogger) wrap(ctx context.Context, url string, body io.Reader) (io.Reader, func()) {
+	logger := log.FromContext(ctx).V(responseBodyLogLevel)
+	if l.limit <= 0 || !logger.Enabled() {
+		return body, func() {}
+	}
+	capture := &capturingReader{reader: body, limit: l.limit}
+	return capture, func() {
+		logger.Info("provider response body",
+			"url", redactURL(url),
+			"bytes", capture.total,
+			"truncated", capture.total > int64(l.limit),
+			"body", redactSecrets(capture.buf.String()),
+		)
+	}
+}
+
+type capturingReader struct {
+	reader io.Reader
+	buf    bytes.Buffer
+	limit  int
+	total  int64
+}
+
+func (c *capturingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	if remaining := c.limit - c.buf.Len(); remaining > 0 {
+		c.buf.Write(p[:min(n, remaining)])
+	}
+	c.total += int64(n)
+	return n, err
+}
+
+var secretPatterns = []*regexp.Regexp{
+	// JSON members such as "token": "..." or "api_key": "...".
+	regexp.MustCompile(`(?i)("[^"]*(?:token|secret|password|passwd|api[_-]?key|authorization|credential)[^"]*"\s*:\s*)"[^"]*"`),
+	// Query or form parameters such as access_token=...
+	regexp.MustCompile(`(?i)((?:token|secret|password|passwd|api[_-]?key|signature|sig)=)[^&\s"'<]+`),
+	// Bearer and basic credentials.
+	regexp.MustCompile(`(?i)((?:bearer|basic)\s+)[A-Za-z0-9._~+/=-]+`),
+}
+
+// redactSecrets replaces values that look like credentials with REDACTED.
+func redactSecrets(body string) string {
+	body = secretPatterns[0].ReplaceAllString(body, `$1"REDACTED"`)
+	for _, pattern := range secretPatterns[1:] {
+		body = pattern.ReplaceAllString(body, `${1}REDACTED`)
+	}
+	return body
+}
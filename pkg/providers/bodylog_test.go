@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestResponseBodyLogging(t *testing.T) {
+	body := `{"api_key": "s3cr3t-key", "cidrs": ["192.0.2.0/24", "198.51.100.0/24"], "next": "/page?access_token=abc123", "padding": "` + strings.Repeat("x", 512) + `"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	spec := v1alpha1.ProviderSpec{
+		Name:         "jsonEndpoint",
+		JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "cidrs"},
+	}
+	fetch := func(verbosity int, opts ...FactoryOption) []string {
+		t.Helper()
+		var lines []string
+		logger := funcr.New(func(prefix, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{Verbosity: verbosity})
+		provider, err := NewFactory(nil, server.Client(), opts...).FromSpec("default", spec)
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		if _, err := provider.Fetch(log.IntoContext(context.Background(), logger)); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		return lines
+	}
+
+	lines := fetch(3, WithResponseBodyLogging(128))
+	if len(lines) != 1 {
+		t.Fatalf("expected one body log line, got %v", lines)
+	}
+	logged := lines[0]
+	for _, want := range []string{`"truncated"=true`, `\"api_key\": \"REDACTED\"`, "access_token=REDACTED", "192.0.2.0/24"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("body log missing %q: %s", want, logged)
+		}
+	}
+	for _, secret := range []string{"s3cr3t-key", "abc123"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("body log leaks %q: %s", secret, logged)
+		}
+	}
+	if strings.Contains(logged, strings.Repeat("x", 200)) {
+		t.Errorf("body log was not truncated: %s", logged)
+	}
+
+	if lines := fetch(2, WithResponseBodyLogging(128)); len(lines) != 0 {
+		t.Errorf("body logged below verbosity 3: %v", lines)
+	}
+	if lines := fetch(3); len(lines) != 0 {
+		t.Errorf("body logged without WithResponseBodyLogging: %v", lines)
+	}
+}
@@ -0,0 +1,94 @@
+package providers
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// DefaultCacheTTL is how long a successful fetch of a built-in feed is reused by identical
+// fetches, e.g. from other BotNetworkPolicies or resyncs with a short syncPeriod.
+const DefaultCacheTTL = 5 * time.Minute
+
+// WithCacheTTL sets how long successful fetches of the built-in feeds (google, aws, azure,
+// github and cloudflare) are shared between identical providers. Zero disables the cache.
+func WithCacheTTL(ttl time.Duration) FactoryOption {
+	return func(f *Factory) {
+		if ttl >= 0 {
+			f.responses.ttl = ttl
+		}
+	}
+}
+
+// responseCache holds the CIDRs of recent successful fetches, keyed by what determines the
+// upstream request and its filtering. It is safe for concurrent use.
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedResponse
+	now     func() time.Time
+}
+
+type cachedResponse struct {
+	cidrs    []string
+	dataTime time.Time
+	expires  time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: map[string]cachedResponse{}, now: time.Now}
+}
+
+// get returns a copy of the unexpired entry for key.
+func (c *responseCache) get(key string) (cachedResponse, bool) {
+	if c == nil || c.ttl <= 0 {
+		return cachedResponse{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		return cachedResponse{}, false
+	}
+	entry.cidrs = slices.Clone(entry.cidrs)
+	return entry, true
+}
+
+// put stores the result of a successful fetch and evicts expired entries, so that
+// providers removed from every resource do not stay cached.
+func (c *responseCache) put(key string, cidrs []string, dataTime time.Time) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedResponse{cidrs: slices.Clone(cidrs), dataTime: dataTime, expires: now.Add(c.ttl)}
+}
+
+// responseCacheKey identifies the fetch spec describes. Settings applied by the controller
+// after fetching, and the display name, do not take part, so differently named providers
+// of the same feed share an entry.
+func responseCacheKey(spec v1alpha1.ProviderSpec) string {
+	fetch := spec.DeepCopy()
+	fetch.Name = ""
+	fetch.DisplayName = ""
+	fetch.AllowedSupernets = nil
+	fetch.MaxCIDRs = 0
+	fetch.IPFamily = ""
+	fetch.TimeoutSeconds = 0
+	encoded, err := json.Marshal(fetch)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(spec.Name) + "|" + string(encoded)
+}
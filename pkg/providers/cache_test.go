@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestFactory_CacheTTL(t *testing.T) {
+	var requests atomic.Int32
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 00:00:00 GMT")
+		w.Write([]byte(`{"prefixes":[
+			{"ip_prefix":"52.94.76.0/24","service":"AMAZON","region":"us-east-1"},
+			{"ip_prefix":"54.239.0.0/16","service":"AMAZON","region":"eu-west-1"}]}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	factory := NewFactory(nil, server.Client(), WithAWSEndpoint(server.URL))
+	factory.responses.now = func() time.Time { return now }
+	fetch := func(spec v1alpha1.ProviderSpec) ([]string, error) {
+		t.Helper()
+		provider, err := factory.FromSpec("default", spec)
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		return provider.Fetch(context.Background())
+	}
+
+	all := v1alpha1.ProviderSpec{Name: "aws"}
+	if _, err := fetch(all); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	renamed := v1alpha1.ProviderSpec{Name: "AWS", DisplayName: "edge", MaxCIDRs: 1}
+	provider, err := factory.FromSpec("other", renamed)
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	cidrs, err := provider.Fetch(context.Background())
+	if err != nil || len(cidrs) != 2 {
+		t.Fatalf("cached Fetch() = %v, %v; want both CIDRs", cidrs, err)
+	}
+	if got, ok := provider.(TimestampReporter).DataTimestamp(); !ok || !got.Equal(now) {
+		t.Errorf("cached DataTimestamp() = %v, %v; want %v", got, ok, now)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests after identical fetches = %d, want 1", got)
+	}
+
+	filtered := v1alpha1.ProviderSpec{Name: "aws", AWS: &v1alpha1.AWSProviderSpec{Regions: []string{"eu-west-1"}}}
+	cidrs, err = fetch(filtered)
+	if err != nil || len(cidrs) != 1 {
+		t.Fatalf("filtered Fetch() = %v, %v; want one CIDR", cidrs, err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests after a differently filtered fetch = %d, want 2", got)
+	}
+
+	now = now.Add(DefaultCacheTTL)
+	failing.Store(true)
+	if _, err := fetch(all); err == nil {
+		t.Fatal("Fetch() after expiry succeeded, want the upstream error")
+	}
+	failing.Store(false)
+	if _, err := fetch(all); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("requests after expiry and a failed fetch = %d, want 4", got)
+	}
+
+	uncached := NewFactory(nil, server.Client(), WithAWSEndpoint(server.URL), WithCacheTTL(0))
+	for i := 0; i < 2; i++ {
+		provider, err := uncached.FromSpec("default", all)
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		if _, err := provider.Fetch(context.Background()); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+	if got := requests.Load(); got != 6 {
+		t.Errorf("requests with the cache disabled = %d, want 6", got)
+	}
+}
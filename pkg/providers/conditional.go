@@ -0,0 +1,82 @@
+package providers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// conditionalIdle is how long a stored feed document is kept without being requested
+// again, e.g. after its provider was removed from every resource.
+const conditionalIdle = 24 * time.Hour
+
+// conditionalCache keeps the last document served at each built-in feed URL together with
+// its ETag and Last-Modified validators, so that later fetches send If-None-Match and
+// If-Modified-Since and reuse the document on 304 Not Modified. The document is kept
+// rather than the CIDRs because providers of the same URL select different CIDRs from
+// it, and checks such as the AWS maxFeedAge must still run on every fetch. It is safe for
+// concurrent use.
+type conditionalCache struct {
+	mu      sync.Mutex
+	entries map[string]conditionalEntry
+	now     func() time.Time
+}
+
+type conditionalEntry struct {
+	body         []byte
+	etag         string
+	lastModified string
+	used         time.Time
+}
+
+func newConditionalCache() *conditionalCache {
+	return &conditionalCache{entries: map[string]conditionalEntry{}, now: time.Now}
+}
+
+// get returns the stored document of url.
+func (c *conditionalCache) get(url string) (conditionalEntry, bool) {
+	if c == nil {
+		return conditionalEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[url]
+	if ok {
+		entry.used = c.now()
+		c.entries[url] = entry
+	}
+	return entry, ok
+}
+
+// put stores the document served at url when the response carried a validator, and
+// evicts entries idle for longer than conditionalIdle.
+func (c *conditionalCache) put(url string, body []byte, header http.Header) {
+	if c == nil {
+		return
+	}
+	entry := conditionalEntry{body: body, etag: header.Get("ETag"), lastModified: header.Get("Last-Modified")}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, stored := range c.entries {
+		if now.Sub(stored.used) > conditionalIdle {
+			delete(c.entries, k)
+		}
+	}
+	if entry.etag == "" && entry.lastModified == "" {
+		delete(c.entries, url)
+		return
+	}
+	entry.used = now
+	c.entries[url] = entry
+}
+
+// setHeaders makes req conditional on the document having changed since e was stored.
+func (e conditionalEntry) setHeaders(req *http.Request) {
+	if e.etag != "" {
+		req.Header.Set("If-None-Match", e.etag)
+	}
+	if e.lastModified != "" {
+		req.Header.Set("If-Modified-Since", e.lastModified)
+	}
+}
@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+const conditionalFeed = `{"prefixes":[
+	{"ip_prefix":"52.94.76.0/24","service":"AMAZON","region":"us-east-1"},
+	{"ip_prefix":"54.239.0.0/16","service":"AMAZON","region":"eu-west-1"}]}`
+
+func TestStaticHTTPProvider_ConditionalFetch(t *testing.T) {
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(conditionalFeed))
+	}))
+	defer server.Close()
+
+	// The response cache is disabled so that every Fetch reaches the server.
+	factory := NewFactory(nil, server.Client(), WithAWSEndpoint(server.URL), WithCacheTTL(0))
+	fetch := func(spec v1alpha1.ProviderSpec) []string {
+		t.Helper()
+		provider, err := factory.FromSpec("default", spec)
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		cidrs, err := provider.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		return cidrs
+	}
+
+	all := v1alpha1.ProviderSpec{Name: "aws"}
+	first := fetch(all)
+	second := fetch(all)
+	if len(first) != 2 || !reflect.DeepEqual(first, second) {
+		t.Errorf("Fetch() = %v, then %v after 304; want the same two CIDRs", first, second)
+	}
+	// A provider of the same URL selecting differently reuses the stored document.
+	if cidrs := fetch(v1alpha1.ProviderSpec{Name: "aws", AWS: &v1alpha1.AWSProviderSpec{Regions: []string{"eu-west-1"}}}); len(cidrs) != 1 || cidrs[0] != "54.239.0.0/16" {
+		t.Errorf("filtered Fetch() after 304 = %v, want only 54.239.0.0/16", cidrs)
+	}
+	if full.Load() != 1 || notModified.Load() != 2 {
+		t.Errorf("served %d full and %d 304 responses, want 1 and 2", full.Load(), notModified.Load())
+	}
+}
+
+func TestStaticHTTPProvider_UnexpectedNotModified(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// Behave like a stale intermediary: answer 304 unless told to bypass caches.
+		if r.Header.Get("Cache-Control") != "no-cache" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(conditionalFeed))
+	}))
+	defer server.Close()
+
+	factory := NewFactory(nil, server.Client(), WithAWSEndpoint(server.URL))
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{Name: "aws"})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	cidrs, err := provider.Fetch(context.Background())
+	if err != nil || len(cidrs) != 2 {
+		t.Fatalf("Fetch() = %v, %v; want the full feed after the unexpected 304", cidrs, err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want the 304 and one full refetch", got)
+	}
+}
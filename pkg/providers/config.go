@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"crypto/tls"
+	"net/url"
+	"time"
+)
+
+// FactoryConfig is a snapshot of the effective factory settings, suitable for logging.
+// Endpoint URLs have any userinfo password redacted.
+type FactoryConfig struct {
+	GoogleEndpoint   string
+	CloudEndpoint    string
+	AWSEndpoint      string
+	GitHubEndpoint   string
+	HTTPTimeout      time.Duration
+	MinTLSVersion    string
+	RetryMaxAttempts int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	RetryMaxElapsed  time.Duration
+
+	ResponseBodyLogBytes int
+	AWSExcludedRegions   []string
+	CloudflareEndpoint   string
+	AzureEndpoint        string
+	CacheTTL             time.Duration
+	Concurrency          int
+}
+
+// Config returns the effective factory configuration with secrets redacted.
+func (f *Factory) Config() FactoryConfig {
+	cfg := FactoryConfig{
+		GoogleEndpoint:   redactURL(f.googleEndpoint),
+		CloudEndpoint:    redactURL(f.cloudEndpoint),
+		AWSEndpoint:      redactURL(f.awsEndpoint),
+		GitHubEndpoint:   redactURL(f.githubEndpoint),
+		RetryMaxAttempts: f.retry.maxAttempts,
+		RetryBaseDelay:   f.retry.baseDelay,
+		RetryMaxDelay:    f.retry.maxDelay,
+		RetryMaxElapsed:  f.retry.maxElapsed,
+		MinTLSVersion:    tls.VersionName(f.minTLSVersion),
+
+		ResponseBodyLogBytes: f.bodyLog.limit,
+		AWSExcludedRegions:   f.awsExcludedRegions,
+		CloudflareEndpoint:   redactURL(f.cloudflareEndpoint),
+		AzureEndpoint:        redactURL(f.azureEndpoint),
+		CacheTTL:             f.responses.ttl,
+		Concurrency:          f.concurrency,
+	}
+	if f.httpClient != nil {
+		cfg.HTTPTimeout = f.httpClient.Timeout
+	}
+	return cfg
+}
+
+// redactURL hides the password of any userinfo and drops the query string, which may
+// carry API keys. Unparseable values are replaced entirely.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<invalid url>"
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "REDACTED"
+	}
+	return u.Redacted()
+}
@@ -2,30 +2,135 @@ package providers
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1alpha1 "github.com/sugaf1204/botnetworkpolicy/api/v1alpha1"
 )
 
 type configMapProvider struct {
-	client    client.Reader
-	namespace string
-	name      string
-	key       string
+	client     client.Reader
+	namespace  string
+	name       string
+	key        string
+	allowEmpty bool
+	cache      *configMapCache
+	// namespaceSelector, when set, reads the ConfigMap from every matching namespace
+	// instead of namespace.
+	namespaceSelector labels.Selector
+
+	versionPrefix string
+	version       string
 }
 
 func (p *configMapProvider) Fetch(ctx context.Context) ([]string, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("kube client not configured for configmap provider")
+	}
+	if p.namespaceSelector != nil {
+		return p.fetchSelected(ctx)
+	}
 	var cfg corev1.ConfigMap
-	if err := p.client.Get(ctx, client.ObjectKey{Name: p.name, Namespace: p.namespace}, &cfg); err != nil {
+	key := client.ObjectKey{Name: p.name, Namespace: p.namespace}
+	if err := p.client.Get(ctx, key, &cfg); err != nil {
+		if apierrors.IsNotFound(err) {
+			p.cache.forget(key)
+		}
+		return nil, err
+	}
+	cidrs, version, err := p.read(&cfg)
+	if err != nil {
 		return nil, err
 	}
+	p.version = version
+	return sanitize(cidrs, p.allowEmpty)
+}
+
+// fetchSelected unions the CIDRs of the ConfigMap in every namespace matching the
+// namespace selector. Namespaces without the ConfigMap are skipped; the feed version is
+// taken from the first namespace, in name order, that reports one.
+func (p *configMapProvider) fetchSelected(ctx context.Context) ([]string, error) {
+	var namespaces corev1.NamespaceList
+	if err := p.client.List(ctx, &namespaces, client.MatchingLabelsSelector{Selector: p.namespaceSelector}); err != nil {
+		return nil, err
+	}
+	// Lists are not guaranteed to be ordered, and the feed version depends on the order.
+	slices.SortFunc(namespaces.Items, func(a, b corev1.Namespace) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	cidrs := sets.New[string]()
+	var version string
+	for _, ns := range namespaces.Items {
+		var cfg corev1.ConfigMap
+		key := client.ObjectKey{Name: p.name, Namespace: ns.Name}
+		if err := p.client.Get(ctx, key, &cfg); err != nil {
+			if apierrors.IsNotFound(err) {
+				p.cache.forget(key)
+				continue
+			}
+			return nil, err
+		}
+		found, nsVersion, err := p.read(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		cidrs.Insert(found...)
+		if version == "" {
+			version = nsVersion
+		}
+	}
+	p.version = version
+	return sanitize(sets.List(cidrs), p.allowEmpty)
+}
+
+// read returns the CIDRs and feed version held in the provider's key of cfg.
+func (p *configMapProvider) read(cfg *corev1.ConfigMap) ([]string, string, error) {
 	payload, ok := cfg.Data[p.key]
 	if !ok {
-		return nil, errMissingKey(p.key)
+		p.cache.forget(client.ObjectKeyFromObject(cfg))
+		return nil, "", errMissingKey(p.key)
+	}
+	var version string
+	if p.versionPrefix != "" {
+		payload, version = splitVersionComment(payload, p.versionPrefix)
+	}
+	if p.cache == nil {
+		return v1alpha1.ExtractCIDRs(payload), version, nil
+	}
+	return p.cache.parse(cfg, configMapCacheKey{key: p.key, versionPrefix: p.versionPrefix}, payload), version, nil
+}
+
+// FeedVersion implements VersionReporter.
+func (p *configMapProvider) FeedVersion() string {
+	return p.version
+}
+
+// splitVersionComment removes every line starting with prefix, e.g. "# serial ", from
+// payload and returns the remainder of the first such line as the feed version.
+func splitVersionComment(payload, prefix string) (string, string) {
+	var version string
+	lines := strings.Split(payload, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(trimmed, prefix); ok {
+			if version == "" {
+				version = strings.TrimSpace(rest)
+			}
+			continue
+		}
+		kept = append(kept, line)
 	}
-	return sanitize(v1alpha1.ExtractCIDRs(payload))
+	return strings.Join(kept, "\n"), version
 }
 
 type errMissingKey string
@@ -33,3 +138,63 @@ type errMissingKey string
 func (e errMissingKey) Error() string {
 	return "configmap missing key: " + string(e)
 }
+
+// configMapCache remembers the parsed CIDRs of each ConfigMap key together with the
+// ConfigMap's resourceVersion, so an unchanged ConfigMap is not re-parsed on every sync.
+// Entries are dropped once a fetch finds their ConfigMap or key gone.
+type configMapCache struct {
+	mu      sync.Mutex
+	entries map[configMapCacheKey]configMapCacheEntry
+	extract func(string) []string
+}
+
+type configMapCacheKey struct {
+	object        types.NamespacedName
+	key           string
+	versionPrefix string
+}
+
+type configMapCacheEntry struct {
+	resourceVersion string
+	cidrs           []string
+}
+
+func newConfigMapCache() *configMapCache {
+	return &configMapCache{
+		entries: make(map[configMapCacheKey]configMapCacheEntry),
+		extract: v1alpha1.ExtractCIDRs,
+	}
+}
+
+// parse returns the CIDRs in payload, reusing the cached result when the ConfigMap's
+// resourceVersion has not changed since it was last parsed. cacheKey identifies the data
+// key and how the payload was prepared; its object is filled in from cfg.
+func (c *configMapCache) parse(cfg *corev1.ConfigMap, cacheKey configMapCacheKey, payload string) []string {
+	cacheKey.object = types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+	version := cfg.ResourceVersion
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[cacheKey]; ok && version != "" && entry.resourceVersion == version {
+		return append([]string(nil), entry.cidrs...)
+	}
+	cidrs := c.extract(payload)
+	if version != "" {
+		c.entries[cacheKey] = configMapCacheEntry{resourceVersion: version, cidrs: cidrs}
+	}
+	return append([]string(nil), cidrs...)
+}
+
+// forget drops every entry of the ConfigMap object. It is a no-op on a nil cache.
+func (c *configMapCache) forget(object types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for cacheKey := range c.entries {
+		if cacheKey.object == object {
+			delete(c.entries, cacheKey)
+		}
+	}
+}
@@ -2,12 +2,21 @@ package providers
 
 import (
 	"context"
+	"reflect"
+	"slices"
+	"sort"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	operatorv1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy/api/v1alpha1"
 )
 
 func TestConfigMapProvider_Fetch(t *testing.T) {
@@ -202,3 +211,281 @@ func TestErrMissingKey(t *testing.T) {
 		t.Errorf("errMissingKey.Error() = %v, want %v", err.Error(), expected)
 	}
 }
+
+func TestConfigMapProvider_FetchAllowEmpty(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-config",
+			Namespace: "default",
+		},
+		Data: map[string]string{
+			"cidrs": "",
+		},
+	}
+
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(configMap).
+		Build()
+
+	provider := &configMapProvider{
+		client:     kubeClient,
+		namespace:  "default",
+		name:       "test-config",
+		key:        "cidrs",
+		allowEmpty: true,
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("configMapProvider.Fetch() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("configMapProvider.Fetch() got %d CIDRs, want 0", len(got))
+	}
+}
+
+func TestConfigMapProvider_CachesByResourceVersion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "allowlist", Namespace: "default"},
+		Data:       map[string]string{"cidrs": "10.0.0.0/24\n10.0.1.0/24"},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+	cache := newConfigMapCache()
+	parses := 0
+	cache.extract = func(payload string) []string {
+		parses++
+		return v1alpha1.ExtractCIDRs(payload)
+	}
+	provider := &configMapProvider{client: kubeClient, namespace: "default", name: "allowlist", key: "cidrs", cache: cache}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		got, err := provider.Fetch(ctx)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("Fetch() got %d CIDRs, want 2", len(got))
+		}
+	}
+	if parses != 1 {
+		t.Errorf("parses with unchanged resourceVersion = %d, want 1", parses)
+	}
+
+	var current corev1.ConfigMap
+	if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(configMap), &current); err != nil {
+		t.Fatalf("get configmap: %v", err)
+	}
+	current.Data["cidrs"] = "192.0.2.0/24"
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update configmap: %v", err)
+	}
+
+	got, err := provider.Fetch(ctx)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != "192.0.2.0/24" {
+		t.Errorf("Fetch() after update = %v, want [192.0.2.0/24]", got)
+	}
+	if parses != 2 {
+		t.Errorf("parses after resourceVersion change = %d, want 2", parses)
+	}
+}
+
+func TestConfigMapProvider_EvictsGoneConfigMaps(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "allowlist", Namespace: "default"},
+		Data:       map[string]string{"cidrs": "10.0.0.0/24", "extra": "10.0.1.0/24"},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+	cache := newConfigMapCache()
+	provider := &configMapProvider{client: kubeClient, namespace: "default", name: "allowlist", key: "cidrs", cache: cache}
+	extra := &configMapProvider{client: kubeClient, namespace: "default", name: "allowlist", key: "extra", cache: cache}
+
+	ctx := context.Background()
+	for _, p := range []*configMapProvider{provider, extra} {
+		if _, err := p.Fetch(ctx); err != nil {
+			t.Fatalf("Fetch(%s) error = %v", p.key, err)
+		}
+	}
+	if len(cache.entries) != 2 {
+		t.Fatalf("cache holds %d entries, want 2", len(cache.entries))
+	}
+
+	// A key removed from the ConfigMap evicts the ConfigMap's entries.
+	var current corev1.ConfigMap
+	if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(configMap), &current); err != nil {
+		t.Fatalf("get configmap: %v", err)
+	}
+	delete(current.Data, "cidrs")
+	if err := kubeClient.Update(ctx, &current); err != nil {
+		t.Fatalf("update configmap: %v", err)
+	}
+	if _, err := provider.Fetch(ctx); err == nil {
+		t.Fatal("Fetch() of a removed key succeeded")
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("cache holds %d entries after the key was removed, want 0", len(cache.entries))
+	}
+
+	// A deleted ConfigMap evicts its entries.
+	if _, err := extra.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch(extra) error = %v", err)
+	}
+	if err := kubeClient.Delete(ctx, &current); err != nil {
+		t.Fatalf("delete configmap: %v", err)
+	}
+	if _, err := extra.Fetch(ctx); err == nil {
+		t.Fatal("Fetch() of a deleted ConfigMap succeeded")
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("cache holds %d entries after the ConfigMap was deleted, want 0", len(cache.entries))
+	}
+}
+
+func TestConfigMapProvider_FetchVersionComment(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "feed", Namespace: "default", ResourceVersion: "7"},
+			Data:       map[string]string{"cidrs": "# serial 2024010101\n10.0.0.0/24\n10.0.1.0/24\n"},
+		}).
+		Build()
+
+	cache := newConfigMapCache()
+	provider := &configMapProvider{client: kubeClient, namespace: "default", name: "feed", key: "cidrs", cache: cache, versionPrefix: "# serial"}
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "10.0.0.0/24" || got[1] != "10.0.1.0/24" {
+		t.Errorf("Fetch() = %v, want the two CIDRs without the version line", got)
+	}
+	if version := provider.FeedVersion(); version != "2024010101" {
+		t.Errorf("FeedVersion() = %q, want 2024010101", version)
+	}
+
+	// A provider without the prefix on the same key must not reuse the stripped result.
+	plain := &configMapProvider{client: kubeClient, namespace: "default", name: "feed", key: "cidrs", cache: cache}
+	got, err = plain.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("Fetch() without prefix = %v, want the version line kept as an entry", got)
+	}
+	if version := plain.FeedVersion(); version != "" {
+		t.Errorf("FeedVersion() without prefix = %q, want empty", version)
+	}
+}
+
+func TestConfigMapProvider_NamespaceSelectorUnion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	allowlist := func(namespace, cidrs string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "allowlist", Namespace: namespace},
+			Data:       map[string]string{"cidrs": cidrs},
+		}
+	}
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	shared := map[string]string{"bot.networking.dev/allowlist": "shared"}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			namespace("team-a", shared),
+			namespace("team-b", shared),
+			namespace("team-c", shared),
+			namespace("other", nil),
+			allowlist("team-a", "10.0.0.0/24\n192.0.2.0/24"),
+			allowlist("team-b", "198.51.100.0/24\n192.0.2.0/24"),
+			allowlist("other", "203.0.113.0/24"),
+		).
+		Build()
+
+	factory := NewFactory(kubeClient, nil)
+	provider, err := factory.FromSpec("default", operatorv1alpha1.ProviderSpec{
+		Name: "configMap",
+		ConfigMap: &operatorv1alpha1.ConfigMapProviderSpec{
+			Name:              "allowlist",
+			Key:               "cidrs",
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: shared},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	sort.Strings(got)
+	want := []string{"10.0.0.0/24", "192.0.2.0/24", "198.51.100.0/24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Fetch() = %v, want %v", got, want)
+	}
+}
+
+func TestConfigMapProvider_NamespaceSelectorVersionOrder(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var objects []client.Object
+	for _, name := range []string{"team-a", "team-b", "team-c"} {
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"feed": "shared"}}},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "feed", Namespace: name},
+				Data:       map[string]string{"cidrs": "# serial " + name + "\n10.0.0.0/24\n"},
+			})
+	}
+	// The API server does not promise an order; list the namespaces in reverse.
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if err := c.List(ctx, list, opts...); err != nil {
+					return err
+				}
+				if namespaces, ok := list.(*corev1.NamespaceList); ok {
+					slices.Reverse(namespaces.Items)
+				}
+				return nil
+			},
+		}).
+		Build()
+
+	provider := &configMapProvider{
+		client:            kubeClient,
+		name:              "feed",
+		key:               "cidrs",
+		versionPrefix:     "# serial",
+		namespaceSelector: labels.SelectorFromSet(labels.Set{"feed": "shared"}),
+	}
+	if _, err := provider.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if version := provider.FeedVersion(); version != "team-a" {
+		t.Errorf("FeedVersion() = %q, want the version of the first namespace by name, team-a", version)
+	}
+}
@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// ResolveAll resolves the ConfigRef of each spec. Both returned slices are indexed like
+// specs: a spec that fails to resolve is returned unchanged together with its error.
+func (f *Factory) ResolveAll(ctx context.Context, namespace string, specs []v1alpha1.ProviderSpec) ([]v1alpha1.ProviderSpec, []error) {
+	resolved := make([]v1alpha1.ProviderSpec, len(specs))
+	errs := make([]error, len(specs))
+	for i, spec := range specs {
+		resolved[i], errs[i] = f.ResolveConfigRef(ctx, namespace, spec)
+	}
+	return resolved, errs
+}
+
+// ResolveConfigRef returns spec merged over the shared provider configuration named by its
+// ConfigRef, read from namespace. Top-level fields set in spec replace the shared ones;
+// nested objects are not merged. Specs without a ConfigRef are returned unchanged.
+func (f *Factory) ResolveConfigRef(ctx context.Context, namespace string, spec v1alpha1.ProviderSpec) (v1alpha1.ProviderSpec, error) {
+	ref := spec.ConfigRef
+	if ref == nil {
+		return spec, nil
+	}
+	if f.kubeClient == nil {
+		return spec, fmt.Errorf("kube client not configured for provider configRef")
+	}
+
+	var cfg corev1.ConfigMap
+	if err := f.kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, &cfg); err != nil {
+		return spec, fmt.Errorf("reading provider config %s: %w", ref.Name, err)
+	}
+	payload, ok := cfg.Data[ref.ConfigKey()]
+	if !ok {
+		return spec, fmt.Errorf("provider config %s: %w", ref.Name, errMissingKey(ref.ConfigKey()))
+	}
+	sharedJSON, err := yaml.YAMLToJSON([]byte(payload))
+	if err != nil {
+		return spec, fmt.Errorf("parsing provider config %s: %w", ref.Name, err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(sharedJSON, &fields); err != nil {
+		return spec, fmt.Errorf("parsing provider config %s: %w", ref.Name, err)
+	}
+	if _, nested := fields["configRef"]; nested {
+		return spec, fmt.Errorf("provider config %s must not itself contain a configRef", ref.Name)
+	}
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+
+	inline := spec.DeepCopy()
+	inline.ConfigRef = nil
+	inlineJSON, err := json.Marshal(inline)
+	if err != nil {
+		return spec, err
+	}
+	var overrides map[string]json.RawMessage
+	if err := json.Unmarshal(inlineJSON, &overrides); err != nil {
+		return spec, err
+	}
+	if inline.Name == "" {
+		delete(overrides, "name")
+	}
+	for key, value := range overrides {
+		fields[key] = value
+	}
+
+	mergedJSON, err := json.Marshal(fields)
+	if err != nil {
+		return spec, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(mergedJSON))
+	decoder.DisallowUnknownFields()
+	var merged v1alpha1.ProviderSpec
+	if err := decoder.Decode(&merged); err != nil {
+		return spec, fmt.Errorf("provider config %s: %w", ref.Name, err)
+	}
+	return merged, nil
+}
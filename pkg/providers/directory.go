@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// defaultMaxPages bounds paging of providers that do not set maxPages.
+const defaultMaxPages = 100
+
+// directoryProvider reads CIDRs from a multi-valued attribute of directory entries served
+// as paged JSON documents. It reuses the JSON endpoint provider for requests, headers and
+// paging, with the endpoint's fieldPath selecting the entries of each page.
+type directoryProvider struct {
+	endpoint  *jsonEndpointProvider
+	attribute string
+}
+
+func (p *directoryProvider) Fetch(ctx context.Context) ([]string, error) {
+	headers, err := p.endpoint.resolveHeaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.endpoint.fetchValue(ctx, p.endpoint.client, headers)
+	if err != nil {
+		return nil, err
+	}
+	entries, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("entries at %q are not an array", p.endpoint.fieldPath)
+	}
+
+	cidrs := make([]string, 0)
+	for i, entry := range entries {
+		if _, ok := entry.(map[string]any); !ok {
+			return nil, fmt.Errorf("entry %d at %q is not an object", i, p.endpoint.fieldPath)
+		}
+		attr, err := navigateField(entry, p.attribute, p.endpoint.pathSeparator)
+		if err != nil {
+			// Directory entries commonly omit attributes they have no values for.
+			continue
+		}
+		values, err := interpretCIDRs(attr, nil)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", p.attribute, err)
+		}
+		cidrs = append(cidrs, values...)
+	}
+	return sanitize(cidrs, p.endpoint.allowEmpty)
+}
+
+// nextPageURL returns the absolute URL of the page following current, read from payload at
+// path, or "" when there is none.
+func nextPageURL(payload any, current, path, separator string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	value, err := navigateField(payload, path, separator)
+	if err != nil {
+		return "", nil
+	}
+	next, _ := value.(string)
+	if next == "" {
+		return "", nil
+	}
+
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return "", fmt.Errorf("invalid next page url %q: %w", next, err)
+	}
+	resolved := base.ResolveReference(ref)
+	// Pages are requested with the provider's credentials, which must not follow a link to
+	// another origin.
+	if resolved.Scheme != base.Scheme || resolved.Host != base.Host {
+		return "", fmt.Errorf("next page url %q is not on %s://%s", next, base.Scheme, base.Host)
+	}
+	if resolved.String() == current {
+		return "", fmt.Errorf("next page url %q points back to the current page", next)
+	}
+	return resolved.String(), nil
+}
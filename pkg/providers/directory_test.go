@@ -0,0 +1,122 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestDirectoryProvider_Fetch(t *testing.T) {
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Write([]byte(`{
+				"result": {"entries": [
+					{"dn": "cn=edge-a,ou=networks", "attributes": {"ipNetworkNumber": ["10.0.0.0/24", "10.0.1.0/24"]}},
+					{"dn": "cn=printer,ou=networks", "attributes": {}},
+					{"dn": "cn=edge-b,ou=networks", "attributes": {"ipNetworkNumber": "192.0.2.0/24"}}
+				]},
+				"paging": {"next": "?page=2"}
+			}`))
+		case "2":
+			w.Write([]byte(`{
+				"result": {"entries": [
+					{"dn": "cn=edge-c,ou=networks", "attributes": {"ipNetworkNumber": ["198.51.100.0/24"]}}
+				]},
+				"paging": {"next": ""}
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	factory := NewFactory(nil, server.Client())
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "directory",
+		Directory: &v1alpha1.DirectoryProviderSpec{
+			URL:          server.URL,
+			EntriesPath:  "result.entries",
+			Attribute:    "attributes.ipNetworkNumber",
+			NextPagePath: "paging.next",
+			Headers:      map[string]string{"Authorization": "Bearer token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("directoryProvider.Fetch() error = %v", err)
+	}
+	want := []string{"10.0.0.0/24", "10.0.1.0/24", "192.0.2.0/24", "198.51.100.0/24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("directoryProvider.Fetch() = %v, want %v", got, want)
+	}
+	if len(authHeaders) != 2 || authHeaders[0] != "Bearer token" || authHeaders[1] != "Bearer token" {
+		t.Errorf("Authorization headers per page = %v, want the header on both pages", authHeaders)
+	}
+}
+
+func TestDirectoryProvider_FetchMaxPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"entries": [{"cidr": "10.0.0.0/24"}], "next": "/page?n=` + r.URL.Query().Get("n") + `x"}`))
+	}))
+	defer server.Close()
+
+	provider := &directoryProvider{
+		endpoint:  &jsonEndpointProvider{client: server.Client(), url: server.URL, fieldPath: "entries", nextPagePath: "next", maxPages: 3},
+		attribute: "cidr",
+	}
+	if _, err := provider.Fetch(context.Background()); err == nil {
+		t.Fatal("expected error when paging exceeds maxPages, got nil")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.Fetch(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Fetch() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestDirectoryProvider_FetchStaysOnHost(t *testing.T) {
+	var foreignRequests int
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignRequests++
+		w.Write([]byte(`{"entries": []}`))
+	}))
+	defer foreign.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"entries": [{"cidr": "10.0.0.0/24"}], "next": "` + foreign.URL + `/page"}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(nil, server.Client()).FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "directory",
+		Directory: &v1alpha1.DirectoryProviderSpec{
+			URL:          server.URL,
+			EntriesPath:  "entries",
+			Attribute:    "cidr",
+			NextPagePath: "next",
+			Headers:      map[string]string{"Authorization": "Bearer token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	if _, err := provider.Fetch(context.Background()); err == nil {
+		t.Fatal("Fetch() error = nil, want the next page on another host to be rejected")
+	}
+	if foreignRequests != 0 {
+		t.Errorf("other host received %d requests, want none", foreignRequests)
+	}
+}
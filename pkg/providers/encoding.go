@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding lists the content codings decodeBody understands. Setting it explicitly
+// turns off the transport's transparent gzip handling, so gzip is decoded here too.
+const acceptEncoding = "gzip, br"
+
+// maxDecodedBodyBytes bounds a response body after decompression, so that neither an
+// oversized document nor a small compressed payload expanding without limit exhausts memory.
+const maxDecodedBodyBytes = 64 << 20
+
+// decodeBody returns a reader of resp's body decoded according to its Content-Encoding.
+// The body is capped at limit decoded bytes; reading beyond it fails.
+func decodeBody(resp *http.Response, limit int64) (io.Reader, error) {
+	var decoded io.Reader
+	switch encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		decoded = resp.Body
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("decoding gzip response: %w", err)
+		}
+		decoded = reader
+	case "br":
+		decoded = brotli.NewReader(resp.Body)
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+	return capReader(decoded, limit), nil
+}
+
+// capReader returns a reader of reader that fails once more than limit bytes were read.
+func capReader(reader io.Reader, limit int64) io.Reader {
+	return &cappedReader{reader: io.LimitReader(reader, limit+1), limit: limit}
+}
+
+// cappedReader fails once more than limit bytes have been read, instead of silently
+// truncating like io.LimitReader.
+type cappedReader struct {
+	reader io.Reader
+	read   int64
+	limit  int64
+}
+
+func (r *cappedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.read > r.limit {
+		return n, fmt.Errorf("response body exceeds %d bytes", r.limit)
+	}
+	return n, err
+}
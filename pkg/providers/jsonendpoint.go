@@ -1,10 +1,14 @@
 package providers
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -14,13 +18,43 @@ import (
 
 type jsonEndpointProvider struct {
 	client        *http.Client
+	retry         retryPolicy
+	insecure      bool
+	signer        RequestSigner
 	kubeClient    client.Reader
 	namespace     string
 	url           string
 	fieldPath     string
+	pathSeparator string
 	headers       http.Header
 	secretHeaders []secretHeaderRef
+	basicAuth     *basicAuthRef
+	bearerToken   *corev1.SecretKeySelector
+	clientTLS     clientTLS
 	filter        *jsonFilter
+	minItems      int
+	allowEmpty    bool
+	stripJSONP    bool
+	bodyLog       bodyLogger
+
+	// nextPagePath, when set, selects the URL of the next page within each page.
+	nextPagePath string
+	maxPages     int
+
+	metadataFields []string
+	metadata       CIDRMetadata
+}
+
+// MinItemsError reports that the value at the configured field path held fewer
+// items than the provider's minItems threshold.
+type MinItemsError struct {
+	FieldPath string
+	Got       int
+	Want      int
+}
+
+func (e *MinItemsError) Error() string {
+	return fmt.Sprintf("field %q has %d items, expected at least %d", e.FieldPath, e.Got, e.Want)
 }
 
 type jsonFilter struct {
@@ -33,22 +67,121 @@ type fieldCondition struct {
 }
 
 func (p *jsonEndpointProvider) Fetch(ctx context.Context) ([]string, error) {
+	warnInsecure(ctx, p.insecure, p.url)
 	headers, err := p.resolveHeaders(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	httpClient, err := p.clientTLS.client(ctx, p.kubeClient, p.namespace, p.client)
+	if err != nil {
+		return nil, err
+	}
+	if httpClient != p.client {
+		defer httpClient.CloseIdleConnections()
+	}
+
+	value, err := p.fetchValue(ctx, httpClient, headers)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkMinItems(value); err != nil {
+		return nil, err
+	}
+
+	cidrs, err := interpretCIDRs(value, p.filter)
+	if err != nil {
+		return nil, err
+	}
+	cidrs, err = sanitize(cidrs, p.allowEmpty)
 	if err != nil {
 		return nil, err
 	}
-	for k, values := range headers {
-		for _, v := range values {
-			req.Header.Add(k, v)
+	p.metadata = captureMetadata(value, p.metadataFields, p.filter)
+	return cidrs, nil
+}
+
+// fetchValue returns the value at fieldPath of the document at url. With nextPagePath set,
+// it follows the next page URLs, up to maxPages pages, and returns the values of every
+// page concatenated into one array.
+func (p *jsonEndpointProvider) fetchValue(ctx context.Context, httpClient *http.Client, headers http.Header) (any, error) {
+	if p.nextPagePath == "" {
+		payload, err := p.fetchDocument(ctx, httpClient, p.url, headers)
+		if err != nil {
+			return nil, err
 		}
+		return p.extractValue(payload)
+	}
+
+	maxPages := p.maxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
 	}
+	items := make([]any, 0)
+	pageURL := p.url
+	for page := 1; pageURL != ""; page++ {
+		if page > maxPages {
+			return nil, fmt.Errorf("paging exceeded %d pages", maxPages)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
 
-	resp, err := p.client.Do(req)
+		payload, err := p.fetchDocument(ctx, httpClient, pageURL, headers)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		value, err := p.extractValue(payload)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		if list, ok := value.([]any); ok {
+			items = append(items, list...)
+		} else {
+			items = append(items, value)
+		}
+
+		pageURL, err = nextPageURL(payload, pageURL, p.nextPagePath, p.pathSeparator)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+	}
+	return items, nil
+}
+
+// extractValue returns the value at fieldPath of payload.
+func (p *jsonEndpointProvider) extractValue(payload any) (any, error) {
+	value, err := navigateField(payload, p.fieldPath, p.pathSeparator)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.(map[string]any); ok && isRootPath(p.fieldPath, p.pathSeparator) {
+		return nil, fmt.Errorf("document root is an object; set fieldPath to the field holding the CIDR list")
+	}
+	return value, nil
+}
+
+// LastMetadata implements MetadataReporter.
+func (p *jsonEndpointProvider) LastMetadata() CIDRMetadata {
+	return p.metadata
+}
+
+// fetchDocument GETs url with the given headers using httpClient and decodes the JSON
+// response body.
+func (p *jsonEndpointProvider) fetchDocument(ctx context.Context, httpClient *http.Client, url string, headers http.Header) (any, error) {
+	resp, err := p.retry.do(ctx, httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		for k, values := range headers {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+		return sign(p.signer, req)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -58,21 +191,78 @@ func (p *jsonEndpointProvider) Fetch(ctx context.Context) ([]string, error) {
 		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
 	}
 
-	var payload any
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+	decoded, err := decodeBody(resp, maxDecodedBodyBytes)
+	if err != nil {
 		return nil, err
 	}
-
-	value, err := navigateField(payload, p.fieldPath)
+	reader, logBody := p.bodyLog.wrap(ctx, url, decoded)
+	body, err := io.ReadAll(reader)
+	logBody()
 	if err != nil {
 		return nil, err
 	}
+	if p.stripJSONP {
+		body = stripJSONP(body)
+	}
 
-	cidrs, err := interpretCIDRs(value, p.filter)
-	if err != nil {
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
 		return nil, err
 	}
-	return sanitize(cidrs)
+	return payload, nil
+}
+
+// stripJSONP unwraps a body of the form `identifier(...)` with an optional trailing
+// semicolon. The identifier may be dotted (`jQuery.cb`). Anything else, including
+// plain JSON, is returned unchanged.
+func stripJSONP(body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+	trimmed = bytes.TrimSpace(bytes.TrimSuffix(trimmed, []byte(";")))
+	if len(trimmed) == 0 || trimmed[len(trimmed)-1] != ')' {
+		return body
+	}
+	open := bytes.IndexByte(trimmed, '(')
+	if open <= 0 || !isJSONPCallback(bytes.TrimSpace(trimmed[:open])) {
+		return body
+	}
+	return trimmed[open+1 : len(trimmed)-1]
+}
+
+func isJSONPCallback(name []byte) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for _, part := range bytes.Split(name, []byte(".")) {
+		if len(part) == 0 {
+			return false
+		}
+		for i, c := range part {
+			switch {
+			case c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			case i > 0 && c >= '0' && c <= '9':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (p *jsonEndpointProvider) checkMinItems(value any) error {
+	if p.minItems <= 0 {
+		return nil
+	}
+	count := 0
+	switch v := value.(type) {
+	case []any:
+		count = len(v)
+	case string:
+		count = 1
+	}
+	if count < p.minItems {
+		return &MinItemsError{FieldPath: p.fieldPath, Got: count, Want: p.minItems}
+	}
+	return nil
 }
 
 func (p *jsonEndpointProvider) resolveHeaders(ctx context.Context) (http.Header, error) {
@@ -88,7 +278,27 @@ func (p *jsonEndpointProvider) resolveHeaders(ctx context.Context) (http.Header,
 		if err != nil {
 			return nil, err
 		}
-		headers.Add(secretHeader.name, value)
+		headers.Add(secretHeader.name, secretHeader.prefix+value)
+	}
+
+	switch {
+	case p.basicAuth != nil:
+		username, err := p.resolveSecretHeader(ctx, secretHeaderRef{selector: p.basicAuth.username})
+		if err != nil {
+			return nil, err
+		}
+		password, err := p.resolveSecretHeader(ctx, secretHeaderRef{selector: p.basicAuth.password})
+		if err != nil {
+			return nil, err
+		}
+		credentials := strings.TrimRight(username, "\r\n") + ":" + strings.TrimRight(password, "\r\n")
+		headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	case p.bearerToken != nil:
+		token, err := p.resolveSecretHeader(ctx, secretHeaderRef{selector: *p.bearerToken})
+		if err != nil {
+			return nil, err
+		}
+		headers.Set("Authorization", "Bearer "+strings.TrimSpace(token))
 	}
 
 	return headers, nil
@@ -98,16 +308,20 @@ func (p *jsonEndpointProvider) resolveSecretHeader(ctx context.Context, ref secr
 	if p.kubeClient == nil {
 		return "", fmt.Errorf("kube client not configured for secret-backed headers")
 	}
+	return readSecretKey(ctx, p.kubeClient, p.namespace, ref.selector)
+}
 
+// readSecretKey returns the value stored under selector.Key in the named Secret.
+func readSecretKey(ctx context.Context, kubeClient client.Reader, namespace string, selector corev1.SecretKeySelector) (string, error) {
 	secret := &corev1.Secret{}
-	key := types.NamespacedName{Name: ref.selector.Name, Namespace: p.namespace}
-	if err := p.kubeClient.Get(ctx, key, secret); err != nil {
+	key := types.NamespacedName{Name: selector.Name, Namespace: namespace}
+	if err := kubeClient.Get(ctx, key, secret); err != nil {
 		return "", fmt.Errorf("fetching secret %s: %w", key.String(), err)
 	}
 
-	data, ok := secret.Data[ref.selector.Key]
+	data, ok := secret.Data[selector.Key]
 	if !ok {
-		return "", fmt.Errorf("secret %s missing key %s", key.String(), ref.selector.Key)
+		return "", fmt.Errorf("secret %s missing key %s", key.String(), selector.Key)
 	}
 	return string(data), nil
 }
@@ -115,25 +329,102 @@ func (p *jsonEndpointProvider) resolveSecretHeader(ctx context.Context, ref secr
 type secretHeaderRef struct {
 	name     string
 	selector corev1.SecretKeySelector
+	prefix   string
+}
+
+// basicAuthRef selects the Secret keys holding basic auth credentials.
+type basicAuthRef struct {
+	username corev1.SecretKeySelector
+	password corev1.SecretKeySelector
 }
 
-func navigateField(input any, path string) (any, error) {
-	current := input
-	for _, segment := range strings.Split(path, ".") {
+// defaultPathSeparator separates field path segments unless configured otherwise.
+const defaultPathSeparator = "."
+
+// wildcardSuffix marks a path segment that maps the rest of the path over an array.
+const wildcardSuffix = "[*]"
+
+// navigateField walks path through input. Segments select object keys, and numeric
+// segments also index into arrays, e.g. "regions.0.cidrs". A segment ending in [*], e.g.
+// "regions[*].cidr", selects an array and collects the rest of the path from each element.
+func navigateField(input any, path, separator string) (any, error) {
+	if separator == "" {
+		separator = defaultPathSeparator
+	}
+	return navigateSegments(input, strings.Split(path, separator))
+}
+
+func navigateSegments(current any, segments []string) (any, error) {
+	for i, segment := range segments {
 		if segment == "" {
 			continue
 		}
-		m, ok := current.(map[string]any)
-		if !ok {
-			return nil, fmt.Errorf("segment %q not an object", segment)
+		key, wildcard := strings.CutSuffix(segment, wildcardSuffix)
+		if key != "" {
+			next, err := navigateSegment(current, key)
+			if err != nil {
+				return nil, err
+			}
+			current = next
 		}
-		next, ok := m[segment]
+		if wildcard {
+			items, ok := current.([]any)
+			if !ok {
+				return nil, fmt.Errorf("segment %q not an array", segment)
+			}
+			return collectWildcard(items, segments[i+1:]), nil
+		}
+	}
+	return current, nil
+}
+
+func navigateSegment(current any, segment string) (any, error) {
+	switch value := current.(type) {
+	case map[string]any:
+		next, ok := value[segment]
 		if !ok {
 			return nil, fmt.Errorf("missing segment %q", segment)
 		}
-		current = next
+		return next, nil
+	case []any:
+		index, err := strconv.Atoi(segment)
+		if err != nil {
+			return nil, fmt.Errorf("segment %q not an object", segment)
+		}
+		if index < 0 || index >= len(value) {
+			return nil, fmt.Errorf("index %d out of range for array of %d items", index, len(value))
+		}
+		return value[index], nil
+	default:
+		return nil, fmt.Errorf("segment %q not an object", segment)
 	}
-	return current, nil
+}
+
+// collectWildcard navigates rest from every item and flattens the results into one array,
+// which interpretCIDRs reads like any other: strings are CIDRs and objects go through
+// extractCIDRFromObject. As in JSONPath, items lacking the rest of the path are skipped.
+func collectWildcard(items []any, rest []string) []any {
+	collected := make([]any, 0, len(items))
+	for _, item := range items {
+		value, err := navigateSegments(item, rest)
+		if err != nil {
+			continue
+		}
+		if values, ok := value.([]any); ok {
+			collected = append(collected, values...)
+		} else {
+			collected = append(collected, value)
+		}
+	}
+	return collected
+}
+
+// isRootPath reports whether path has no segments and so selects the document root.
+func isRootPath(path, separator string) bool {
+	if separator == "" {
+		separator = defaultPathSeparator
+	}
+	return strings.ReplaceAll(path, separator, "") == ""
 }
 
 func interpretCIDRs(value any, filter *jsonFilter) ([]string, error) {
@@ -1,16 +1,28 @@
 package providers
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 
+	"github.com/andybalholm/brotli"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
 )
 
 func TestNavigateField(t *testing.T) {
@@ -83,7 +95,7 @@ func TestNavigateField(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := navigateField(tt.payload, tt.path)
+			got, err := navigateField(tt.payload, tt.path, "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("navigateField() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -105,6 +117,114 @@ func TestNavigateField(t *testing.T) {
 	}
 }
 
+func TestNavigateField_ArrayIndex(t *testing.T) {
+	payload := map[string]any{
+		"data": map[string]any{
+			"regions": []any{
+				map[string]any{"cidrs": []any{"10.0.0.0/24"}},
+				map[string]any{"cidrs": []any{"10.1.0.0/24", "10.2.0.0/24"}, "0": "key"},
+			},
+			"matrix": []any{[]any{"skip"}, []any{"skip", "10.3.0.0/24"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    any
+		wantErr string
+	}{
+		{name: "first element", path: "data.regions.0.cidrs", want: []any{"10.0.0.0/24"}},
+		{name: "later element", path: "data.regions.1.cidrs", want: []any{"10.1.0.0/24", "10.2.0.0/24"}},
+		{name: "nested arrays", path: "data.matrix.1.1", want: "10.3.0.0/24"},
+		{name: "numeric object key", path: "data.regions.1.0", want: "key"},
+		{name: "out of range", path: "data.regions.2.cidrs", wantErr: "index 2 out of range for array of 2 items"},
+		{name: "negative index", path: "data.regions.-1.cidrs", wantErr: "out of range"},
+		{name: "non-numeric segment on array", path: "data.regions.first.cidrs", wantErr: `segment "first" not an object`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := navigateField(payload, tt.path, "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("navigateField() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("navigateField() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("navigateField() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONEndpointProvider_FetchWildcard(t *testing.T) {
+	body := `{"regions": [
+		{"name": "us", "cidr": "10.0.0.0/24"},
+		{"name": "eu", "cidr": "10.1.0.0/24"},
+		{"name": "ap"},
+		{"name": "sa", "prefixes": [{"ip_prefix": "10.2.0.0/24"}, {"ipv6Prefix": "2001:db8::/32"}]}
+	]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		fieldPath string
+		want      []string
+	}{
+		{name: "string leaves", fieldPath: "regions[*].cidr", want: []string{"10.0.0.0/24", "10.1.0.0/24"}},
+		{name: "object leaves", fieldPath: "regions[*].prefixes", want: []string{"10.2.0.0/24", "2001:db8::/32"}},
+		{name: "nested wildcards", fieldPath: "regions[*].prefixes[*]", want: []string{"10.2.0.0/24", "2001:db8::/32"}},
+		{name: "index then wildcard", fieldPath: "regions.3.prefixes[*].ip_prefix", want: []string{"10.2.0.0/24"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &jsonEndpointProvider{client: server.Client(), url: server.URL, fieldPath: tt.fieldPath, headers: http.Header{}}
+			got, err := provider.Fetch(context.Background())
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := navigateField(map[string]any{"regions": map[string]any{}}, "regions[*].cidr", ""); err == nil || !strings.Contains(err.Error(), "not an array") {
+		t.Errorf("wildcard over an object: error = %v, want a not an array error", err)
+	}
+}
+
+func TestNavigateField_CustomSeparator(t *testing.T) {
+	payload := map[string]any{
+		"data": map[string]any{
+			"region.name": map[string]any{
+				"cidrs.v4": []any{"10.0.0.0/24"},
+			},
+		},
+	}
+
+	got, err := navigateField(payload, "data/region.name/cidrs.v4", "/")
+	if err != nil {
+		t.Fatalf("navigateField() error = %v", err)
+	}
+	arr, ok := got.([]any)
+	if !ok || len(arr) != 1 || arr[0] != "10.0.0.0/24" {
+		t.Errorf("navigateField() = %#v, want [10.0.0.0/24]", got)
+	}
+
+	// The default separator cannot reach keys containing dots.
+	if _, err := navigateField(payload, "data.region.name.cidrs.v4", ""); err == nil {
+		t.Error("expected error navigating dotted keys with the default separator, got nil")
+	}
+}
+
 func TestInterpretCIDRs(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -298,6 +418,41 @@ func TestJSONEndpointProvider_Fetch(t *testing.T) {
 	}
 }
 
+func TestJSONEndpointProvider_FetchRootDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr string
+	}{
+		{name: "array at the root", body: `["10.0.0.0/24", "192.168.0.0/16"]`, want: []string{"10.0.0.0/24", "192.168.0.0/16"}},
+		{name: "object at the root", body: `{"cidrs": ["10.0.0.0/24"]}`, wantErr: "document root is an object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := &jsonEndpointProvider{client: server.Client(), url: server.URL, headers: http.Header{}}
+			got, err := provider.Fetch(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestJSONEndpointProvider_FetchWithSecretHeaders(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -455,3 +610,473 @@ func TestJSONEndpointProvider_FetchContextCancellation(t *testing.T) {
 		t.Error("expected error when context is cancelled, got nil")
 	}
 }
+
+func TestJSONEndpointProvider_FetchMinItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]any{
+			"cidrs": []any{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"},
+		})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		minItems int
+		wantErr  bool
+	}{
+		{name: "disabled", minItems: 0, wantErr: false},
+		{name: "below threshold", minItems: 4, wantErr: true},
+		{name: "at threshold", minItems: 3, wantErr: false},
+		{name: "above threshold", minItems: 2, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &jsonEndpointProvider{
+				client:    server.Client(),
+				url:       server.URL,
+				fieldPath: "cidrs",
+				headers:   http.Header{},
+				minItems:  tt.minItems,
+			}
+
+			got, err := provider.Fetch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("jsonEndpointProvider.Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var minErr *MinItemsError
+				if !errors.As(err, &minErr) {
+					t.Fatalf("expected *MinItemsError, got %T", err)
+				}
+				if minErr.Got != 3 || minErr.Want != tt.minItems {
+					t.Errorf("MinItemsError = %+v, want Got=3 Want=%d", minErr, tt.minItems)
+				}
+				return
+			}
+			if len(got) != 3 {
+				t.Errorf("jsonEndpointProvider.Fetch() got %d CIDRs, want 3", len(got))
+			}
+		})
+	}
+}
+
+func TestJSONEndpointProvider_FetchStripJSONP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`jQuery.cb_1({"cidrs":["10.0.0.0/24","10.0.1.0/24"]});` + "\n"))
+	}))
+	defer server.Close()
+
+	provider := &jsonEndpointProvider{
+		client:     server.Client(),
+		url:        server.URL,
+		fieldPath:  "cidrs",
+		headers:    http.Header{},
+		stripJSONP: true,
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("jsonEndpointProvider.Fetch() error = %v", err)
+	}
+	want := []string{"10.0.0.0/24", "10.0.1.0/24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jsonEndpointProvider.Fetch() = %v, want %v", got, want)
+	}
+}
+
+func TestJSONEndpointProvider_FetchContentEncoding(t *testing.T) {
+	document := `{"cidrs":["10.0.0.0/24","10.0.1.0/24"]}`
+	compress := map[string]func(io.Writer) io.WriteCloser{
+		"br":   func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	}
+
+	for encoding, newWriter := range compress {
+		t.Run(encoding, func(t *testing.T) {
+			var body bytes.Buffer
+			writer := newWriter(&body)
+			_, _ = writer.Write([]byte(document))
+			_ = writer.Close()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.Contains(r.Header.Get("Accept-Encoding"), encoding) {
+					t.Errorf("Accept-Encoding = %q, want it to offer %s", r.Header.Get("Accept-Encoding"), encoding)
+				}
+				w.Header().Set("Content-Encoding", encoding)
+				_, _ = w.Write(body.Bytes())
+			}))
+			defer server.Close()
+
+			provider := &jsonEndpointProvider{client: server.Client(), url: server.URL, fieldPath: "cidrs", headers: http.Header{}}
+			got, err := provider.Fetch(context.Background())
+			if err != nil {
+				t.Fatalf("jsonEndpointProvider.Fetch() error = %v", err)
+			}
+			want := []string{"10.0.0.0/24", "10.0.1.0/24"}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("jsonEndpointProvider.Fetch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeBody_Limit(t *testing.T) {
+	var body bytes.Buffer
+	writer := brotli.NewWriter(&body)
+	_, _ = writer.Write(bytes.Repeat([]byte("0"), 4096))
+	_ = writer.Close()
+
+	resp := &http.Response{Header: http.Header{"Content-Encoding": {"br"}}, Body: io.NopCloser(&body)}
+	reader, err := decodeBody(resp, 1024)
+	if err != nil {
+		t.Fatalf("decodeBody() error = %v", err)
+	}
+	if _, err := io.ReadAll(reader); err == nil || !strings.Contains(err.Error(), "exceeds 1024 bytes") {
+		t.Errorf("reading past the limit error = %v, want a size error", err)
+	}
+
+	resp = &http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(bytes.Repeat([]byte("0"), 4096)))}
+	reader, err = decodeBody(resp, 1024)
+	if err != nil {
+		t.Fatalf("decodeBody() error = %v", err)
+	}
+	if _, err := io.ReadAll(reader); err == nil || !strings.Contains(err.Error(), "exceeds 1024 bytes") {
+		t.Errorf("reading an uncompressed body past the limit error = %v, want a size error", err)
+	}
+
+	resp = &http.Response{Header: http.Header{"Content-Encoding": {"zstd"}}, Body: io.NopCloser(&body)}
+	if _, err := decodeBody(resp, 1024); err == nil {
+		t.Error("decodeBody() accepted an unsupported Content-Encoding")
+	}
+}
+
+func TestJSONEndpointProvider_FetchMetadataFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"prefixes":[
+			{"cidr":"192.0.2.0/24","asn":64496,"org":"Example Net","region":"eu"},
+			{"cidr":"198.51.100.0/24","org":"Other Net"},
+			{"cidr":"203.0.113.0/24"}
+		]}`))
+	}))
+	defer server.Close()
+
+	provider := &jsonEndpointProvider{
+		client:         server.Client(),
+		url:            server.URL,
+		fieldPath:      "prefixes",
+		headers:        http.Header{},
+		metadataFields: []string{"asn", "org"},
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("jsonEndpointProvider.Fetch() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("jsonEndpointProvider.Fetch() = %v, want 3 CIDRs", got)
+	}
+
+	want := CIDRMetadata{
+		"192.0.2.0/24":    {"asn": "64496", "org": "Example Net"},
+		"198.51.100.0/24": {"org": "Other Net"},
+	}
+	if metadata := provider.LastMetadata(); !reflect.DeepEqual(metadata, want) {
+		t.Errorf("LastMetadata() = %v, want %v", metadata, want)
+	}
+}
+
+func TestStripJSONP(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "wrapped", in: `callback({"a":1})`, want: `{"a":1}`},
+		{name: "semicolon and whitespace", in: " cb ( [1,2] ) ;\n", want: ` [1,2] `},
+		{name: "plain object", in: `{"a":"f(x)"}`, want: `{"a":"f(x)"}`},
+		{name: "plain array", in: `["x(1)"]`, want: `["x(1)"]`},
+		{name: "string literal", in: `"cb(1)"`, want: `"cb(1)"`},
+		{name: "invalid identifier", in: `1cb({})`, want: `1cb({})`},
+		{name: "no closing paren", in: `cb({}`, want: `cb({}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(stripJSONP([]byte(tt.in))); got != tt.want {
+				t.Errorf("stripJSONP(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONEndpointProvider_FetchWithSecretHeaderValuePrefix(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("abc123")},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(map[string]any{"cidrs": []any{"10.0.0.0/24"}})
+	}))
+	defer server.Close()
+
+	factory := NewFactory(kubeClient, server.Client())
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "jsonEndpoint",
+		JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{
+			URL:       server.URL,
+			FieldPath: "cidrs",
+			HeaderSecretRefs: []v1alpha1.HTTPHeaderSecretRef{{
+				Name: "Authorization",
+				SecretKeyRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-token"},
+					Key:                  "token",
+				},
+				ValuePrefix: "Bearer ",
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	if _, err := provider.Fetch(context.Background()); err != nil {
+		t.Fatalf("jsonEndpointProvider.Fetch() error = %v", err)
+	}
+	if gotAuth != "Bearer abc123" {
+		t.Errorf("Authorization header = %q, want %q", gotAuth, "Bearer abc123")
+	}
+}
+
+func TestJSONEndpointProvider_FetchWithAuthSecretRefs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "endpoint-basic", Namespace: "default"},
+			Type:       corev1.SecretTypeBasicAuth,
+			Data:       map[string][]byte{"username": []byte("bot\n"), "password": []byte("s3cret")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "endpoint-creds", Namespace: "default"},
+			Data:       map[string][]byte{"user": []byte("feed"), "pass": []byte("pa:ss"), "token": []byte("abc123\n")},
+		},
+	).Build()
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(map[string]any{"cidrs": []any{"10.0.0.0/24"}})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		spec v1alpha1.JSONEndpointProviderSpec
+		want string
+	}{
+		{
+			name: "basic auth with default keys",
+			spec: v1alpha1.JSONEndpointProviderSpec{
+				BasicAuthSecretRef: &v1alpha1.BasicAuthSecretRef{Name: "endpoint-basic"},
+			},
+			want: "Basic " + base64.StdEncoding.EncodeToString([]byte("bot:s3cret")),
+		},
+		{
+			name: "basic auth with custom keys",
+			spec: v1alpha1.JSONEndpointProviderSpec{
+				BasicAuthSecretRef: &v1alpha1.BasicAuthSecretRef{Name: "endpoint-creds", UsernameKey: "user", PasswordKey: "pass"},
+			},
+			want: "Basic " + base64.StdEncoding.EncodeToString([]byte("feed:pa:ss")),
+		},
+		{
+			name: "bearer token",
+			spec: v1alpha1.JSONEndpointProviderSpec{
+				BearerTokenSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "endpoint-creds"},
+					Key:                  "token",
+				},
+			},
+			want: "Bearer abc123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAuth = ""
+			spec := tt.spec
+			spec.URL = server.URL
+			spec.FieldPath = "cidrs"
+			provider, err := NewFactory(kubeClient, server.Client()).FromSpec("default", v1alpha1.ProviderSpec{Name: "jsonEndpoint", JSONEndpoint: &spec})
+			if err != nil {
+				t.Fatalf("FromSpec() error = %v", err)
+			}
+			if _, err := provider.Fetch(context.Background()); err != nil {
+				t.Fatalf("jsonEndpointProvider.Fetch() error = %v", err)
+			}
+			if gotAuth != tt.want {
+				t.Errorf("Authorization header = %q, want %q", gotAuth, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONEndpointProvider_FetchWithBasicAuthMissingKey(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "endpoint-basic", Namespace: "default"},
+		Data:       map[string][]byte{"username": []byte("bot")},
+	}).Build()
+
+	provider, err := NewFactory(kubeClient, http.DefaultClient).FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "jsonEndpoint",
+		JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{
+			URL:                "http://127.0.0.1:0/unused",
+			BasicAuthSecretRef: &v1alpha1.BasicAuthSecretRef{Name: "endpoint-basic"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	_, err = provider.Fetch(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "missing key password") {
+		t.Fatalf("Fetch() error = %v, want missing key password", err)
+	}
+}
+
+func TestJSONEndpointProvider_FetchPaged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			json.NewEncoder(w).Encode(map[string]any{
+				"data":  []any{map[string]any{"cidr": "10.0.0.0/24", "region": "eu"}},
+				"links": map[string]any{"next": "/ranges?page=2"},
+			})
+		case "2":
+			json.NewEncoder(w).Encode(map[string]any{
+				"data":  []any{map[string]any{"cidr": "10.0.1.0/24", "region": "us"}, map[string]any{"cidr": "10.0.2.0/24", "region": "us"}},
+				"links": map[string]any{"next": ""},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(nil, server.Client()).FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "jsonEndpoint",
+		JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{
+			URL:               server.URL + "/ranges",
+			FieldPath:         "data",
+			NextPageFieldPath: "links.next",
+			MinItems:          3,
+			MetadataFields:    []string{"region"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if want := []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() = %v, want %v", got, want)
+	}
+	metadata := provider.(MetadataReporter).LastMetadata()
+	if metadata["10.0.0.0/24"]["region"] != "eu" || metadata["10.0.2.0/24"]["region"] != "us" {
+		t.Errorf("LastMetadata() = %v, want the regions of both pages", metadata)
+	}
+}
+
+func TestJSONEndpointProvider_FetchPagedLimits(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{
+			"cidrs": []any{"10.0.0.0/24"},
+			"next":  fmt.Sprintf("/ranges?page=%d", n+1),
+		})
+	}))
+	defer server.Close()
+
+	spec := v1alpha1.ProviderSpec{
+		Name: "jsonEndpoint",
+		JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{
+			URL:               server.URL + "/ranges",
+			FieldPath:         "cidrs",
+			NextPageFieldPath: "next",
+			MaxPages:          3,
+		},
+	}
+	provider, err := NewFactory(nil, server.Client()).FromSpec("default", spec)
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	_, err = provider.Fetch(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "paging exceeded 3 pages") {
+		t.Fatalf("Fetch() error = %v, want the page limit to be exceeded", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requested %d pages, want 3", got)
+	}
+
+	requests.Store(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = provider.Fetch(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Fetch() with a cancelled context error = %v, want context.Canceled", err)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("requested %d pages after cancellation, want 0", got)
+	}
+}
+
+func TestJSONEndpointProvider_FetchPagedStaysOnHost(t *testing.T) {
+	var foreignAuth []string
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignAuth = append(foreignAuth, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]any{"cidrs": []any{"10.0.1.0/24"}})
+	}))
+	defer foreign.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"cidrs": []any{"10.0.0.0/24"},
+			"next":  foreign.URL + "/ranges?page=2",
+		})
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(nil, server.Client()).FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "jsonEndpoint",
+		JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{
+			URL:               server.URL + "/ranges",
+			FieldPath:         "cidrs",
+			NextPageFieldPath: "next",
+			Headers:           map[string]string{"Authorization": "Bearer token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	_, err = provider.Fetch(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "is not on "+server.URL) {
+		t.Fatalf("Fetch() error = %v, want the next page on another host to be rejected", err)
+	}
+	if len(foreignAuth) != 0 {
+		t.Errorf("other host received %d requests, want none", len(foreignAuth))
+	}
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxMetadataEntries bounds the number of CIDRs for which metadata is retained per fetch.
+const maxMetadataEntries = 1000
+
+// CIDRMetadata maps a CIDR to the captured fields of the JSON object it was read from.
+type CIDRMetadata map[string]map[string]string
+
+// MetadataReporter is implemented by providers that can capture per-CIDR metadata.
+// LastMetadata returns the metadata captured by the most recent successful Fetch.
+type MetadataReporter interface {
+	LastMetadata() CIDRMetadata
+}
+
+// VersionReporter is implemented by providers that can read a feed version, e.g. from a
+// version comment line. FeedVersion returns the version seen by the most recent Fetch.
+type VersionReporter interface {
+	FeedVersion() string
+}
+
+// TimestampReporter is implemented by providers that can tell when their data was
+// produced. DataTimestamp returns the time seen by the most recent Fetch, or false when
+// the source did not report one.
+type TimestampReporter interface {
+	DataTimestamp() (time.Time, bool)
+}
+
+// captureMetadata collects the named fields from each array-of-objects entry that yields a
+// CIDR, honouring the same filter as interpretCIDRs. At most maxMetadataEntries are kept.
+func captureMetadata(value any, fields []string, filter *jsonFilter) CIDRMetadata {
+	items, ok := value.([]any)
+	if !ok || len(fields) == 0 {
+		return nil
+	}
+	metadata := CIDRMetadata{}
+	for _, item := range items {
+		if len(metadata) >= maxMetadataEntries {
+			break
+		}
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if filter != nil && !matchesFilter(obj, filter) {
+			continue
+		}
+		cidr := extractCIDRFromObject(obj)
+		if cidr == "" {
+			continue
+		}
+		values := make(map[string]string, len(fields))
+		for _, field := range fields {
+			if raw, ok := obj[field]; ok && raw != nil {
+				values[field] = strings.TrimSpace(fmt.Sprint(raw))
+			}
+		}
+		if len(values) > 0 {
+			metadata[cidr] = values
+		}
+	}
+	return metadata
+}
@@ -2,12 +2,16 @@ package providers
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
@@ -23,13 +27,37 @@ type Provider interface {
 	Fetch(ctx context.Context) ([]string, error)
 }
 
-// Factory constructs providers from CRD specs.
+// Factory constructs providers from CRD specs. A Factory may be long-lived; it caches
+// parsed ConfigMap contents across fetches.
 type Factory struct {
 	kubeClient     client.Reader
 	httpClient     *http.Client
 	googleEndpoint string
+	cloudEndpoint  string
 	awsEndpoint    string
 	githubEndpoint string
+	retry          retryPolicy
+	configMaps     *configMapCache
+	signers        map[string]RequestSigner
+	minTLSVersion  uint16
+	bodyLog        bodyLogger
+
+	// insecureClient is httpClient with certificate verification disabled, for providers
+	// setting insecureSkipTLSVerify. clientErr is set instead when httpClient has a custom
+	// RoundTripper, which cannot be configured.
+	insecureClient *http.Client
+	clientErr      error
+	proxies        *proxyCache
+
+	// awsExcludedRegions are dropped from the AWS feed unless a spec lists its own regions.
+	awsExcludedRegions []string
+
+	cloudflareEndpoint string
+	// azureEndpoint has no default: the service tags document lives under a dated URL.
+	azureEndpoint string
+	responses     *responseCache
+	conditional   *conditionalCache
+	concurrency   int
 }
 
 // NewFactory returns a provider factory.
@@ -38,12 +66,23 @@ func NewFactory(kubeClient client.Reader, httpClient *http.Client, opts ...Facto
 		kubeClient:     kubeClient,
 		httpClient:     httpClient,
 		googleEndpoint: defaultGoogleEndpoint,
+		cloudEndpoint:  defaultGoogleCloudEndpoint,
 		awsEndpoint:    defaultAWSEndpoint,
 		githubEndpoint: defaultGitHubEndpoint,
+		retry:          defaultRetryPolicy(),
+		configMaps:     newConfigMapCache(),
+		minTLSVersion:  tls.VersionTLS12,
+
+		cloudflareEndpoint: defaultCloudflareEndpoint,
+		responses:          newResponseCache(DefaultCacheTTL),
+		conditional:        newConditionalCache(),
+		proxies:            newProxyCache(),
+		concurrency:        DefaultConcurrency,
 	}
 	for _, opt := range opts {
 		opt(factory)
 	}
+	factory.setClients(factory.httpClient)
 	return factory
 }
 
@@ -68,6 +107,19 @@ func WithAWSEndpoint(endpoint string) FactoryOption {
 	}
 }
 
+// WithAWSExcludedRegions drops the given regions from every AWS provider whose spec does
+// not list regions of its own, so individual resources need not repeat the exclusion.
+func WithAWSExcludedRegions(regions ...string) FactoryOption {
+	return func(f *Factory) {
+		f.awsExcludedRegions = nil
+		for _, region := range regions {
+			if region = strings.TrimSpace(region); region != "" {
+				f.awsExcludedRegions = append(f.awsExcludedRegions, region)
+			}
+		}
+	}
+}
+
 // WithGitHubEndpoint overrides the GitHub provider endpoint.
 func WithGitHubEndpoint(endpoint string) FactoryOption {
 	return func(f *Factory) {
@@ -77,30 +129,136 @@ func WithGitHubEndpoint(endpoint string) FactoryOption {
 	}
 }
 
+// cached enables the response cache and conditional requests for a built-in feed provider
+// built from spec. Authenticated or signed requests, and those trusting a custom CA or
+// going through a proxy, are never shared, since their credentials, trust and network
+// path are scoped to the resource.
+func (f *Factory) cached(spec v1alpha1.ProviderSpec, p *staticHTTPProvider) *staticHTTPProvider {
+	if p.signer != nil || p.tokenRef != nil || p.clientTLS.enabled() || spec.ProxyURL != "" {
+		return p
+	}
+	if key := responseCacheKey(spec); key != "" {
+		p.cache, p.cacheKey = f.responses, key
+	}
+	p.conditional = f.conditional
+	return p
+}
+
+// WithAzureEndpoint sets the Azure provider endpoint used by specs without their own URL.
+func WithAzureEndpoint(endpoint string) FactoryOption {
+	return func(f *Factory) {
+		if strings.TrimSpace(endpoint) != "" {
+			f.azureEndpoint = endpoint
+		}
+	}
+}
+
+// WithCloudflareEndpoint overrides the Cloudflare provider endpoint.
+func WithCloudflareEndpoint(endpoint string) FactoryOption {
+	return func(f *Factory) {
+		if strings.TrimSpace(endpoint) != "" {
+			f.cloudflareEndpoint = endpoint
+		}
+	}
+}
+
+// WithMinTLSVersion sets the oldest TLS version provider requests accept, e.g.
+// tls.VersionTLS13. Defaults to tls.VersionTLS12.
+func WithMinTLSVersion(version uint16) FactoryOption {
+	return func(f *Factory) {
+		if version != 0 {
+			f.minTLSVersion = version
+		}
+	}
+}
+
+// DefaultConcurrency is how many providers of one BotNetworkPolicy are fetched at the same
+// time unless WithConcurrency overrides it.
+const DefaultConcurrency = 4
+
+// WithConcurrency caps how many providers of one BotNetworkPolicy are fetched at the same
+// time, bounding outbound connections per reconcile. One fetches providers serially;
+// values below one are ignored.
+func WithConcurrency(n int) FactoryOption {
+	return func(f *Factory) {
+		if n >= 1 {
+			f.concurrency = n
+		}
+	}
+}
+
+// Concurrency returns how many providers may be fetched at the same time.
+func (f *Factory) Concurrency() int {
+	return f.concurrency
+}
+
+// BuildAll constructs a Provider for each spec. Both returned slices are indexed like
+// specs: a spec that fails validation or construction has a nil Provider and its error,
+// and the remaining providers are still built.
+func (f *Factory) BuildAll(namespace string, specs []v1alpha1.ProviderSpec) ([]Provider, []error) {
+	built := make([]Provider, len(specs))
+	errs := make([]error, len(specs))
+	for i, spec := range specs {
+		built[i], errs[i] = f.FromSpec(namespace, spec)
+	}
+	return built, errs
+}
+
 // FromSpec constructs a Provider from the given specification.
 func (f *Factory) FromSpec(namespace string, spec v1alpha1.ProviderSpec) (Provider, error) {
+	if spec.ConfigRef != nil {
+		return nil, fmt.Errorf("%s provider configRef %s must be resolved with ResolveConfigRef first", spec.Name, spec.ConfigRef.Name)
+	}
 	if err := spec.Validate(); err != nil {
 		return nil, err
 	}
+	if spec.ProxyURL != "" {
+		proxied, err := f.withProxy(namespace, spec)
+		if err != nil {
+			return nil, err
+		}
+		f = proxied
+	}
 
 	switch strings.ToLower(spec.Name) {
 	case "google":
 		url := f.googleEndpoint
-		var scopes []string
+		var scopes, fallbacks []string
+		var insecure, cloud bool
+		var clientTLS clientTLS
 		if spec.Google != nil {
+			cloud = spec.Google.Feed == v1alpha1.GoogleFeedCloud
+			if cloud {
+				url = f.cloudEndpoint
+			}
 			if spec.Google.URL != "" {
 				url = spec.Google.URL
 			}
+			fallbacks = spec.Google.FallbackURLs
 			scopes = spec.Google.Scope
+			insecure = isTrue(spec.Google.InsecureSkipTLSVerify)
+			clientTLS.caSecret, clientTLS.caConfigMap = spec.Google.CASecretRef, spec.Google.CAConfigMapRef
 		}
 		selector := func(data map[string]any) ([]string, error) {
 			return googleSelectorWithScope(data, scopes)
 		}
-		return &staticHTTPProvider{client: f.httpClient, url: url, selector: selector}, nil
+		if cloud {
+			selector = func(data map[string]any) ([]string, error) {
+				return cloudSelectorWithScope(data, scopes)
+			}
+		}
+		httpClient, err := f.clientFor(insecure)
+		if err != nil {
+			return nil, err
+		}
+		return f.cached(spec, &staticHTTPProvider{client: httpClient, url: url, fallbackURLs: fallbacks, selector: selector, retry: f.retry, insecure: insecure, signer: f.signerFor(namespace, spec), bodyLog: f.bodyLog, kubeClient: f.kubeClient, namespace: namespace, clientTLS: clientTLS}), nil
 
 	case "aws":
 		url := f.awsEndpoint
-		var services, regions, nbgs []string
+		var services, regions, nbgs, fallbacks []string
+		var insecure bool
+		var maxAge time.Duration
+		var clientTLS clientTLS
 
 		// When spec.AWS is provided, respect the API contract:
 		// - Empty services = all services
@@ -110,30 +268,102 @@ func (f *Factory) FromSpec(namespace string, spec v1alpha1.ProviderSpec) (Provid
 			if spec.AWS.URL != "" {
 				url = spec.AWS.URL
 			}
+			fallbacks = spec.AWS.FallbackURLs
 			services = spec.AWS.Services
 			regions = spec.AWS.Regions
 			nbgs = spec.AWS.NetworkBorderGroups
+			insecure = isTrue(spec.AWS.InsecureSkipTLSVerify)
+			maxAge = spec.AWS.MaxFeedAge.Duration
+			clientTLS.caSecret, clientTLS.caConfigMap = spec.AWS.CASecretRef, spec.AWS.CAConfigMapRef
 		}
 		// If spec.AWS is nil (name: aws only), all fields are empty = all IPs
+		// An explicit region list overrides the factory-wide exclusions.
+		var excluded []string
+		if len(regions) == 0 {
+			excluded = f.awsExcludedRegions
+		}
 
 		selector := func(data map[string]any) ([]string, error) {
-			return awsSelectorWithFilter(data, services, regions, nbgs)
+			if err := checkAWSFeedAge(data, maxAge, time.Now()); err != nil {
+				return nil, err
+			}
+			return awsSelectorWithFilter(excludeAWSRegions(data, excluded), services, regions, nbgs)
+		}
+		httpClient, err := f.clientFor(insecure)
+		if err != nil {
+			return nil, err
 		}
-		return &staticHTTPProvider{client: f.httpClient, url: url, selector: selector}, nil
+		return f.cached(spec, &staticHTTPProvider{client: httpClient, url: url, fallbackURLs: fallbacks, selector: selector, feedTime: awsFeedTime, retry: f.retry, insecure: insecure, signer: f.signerFor(namespace, spec), bodyLog: f.bodyLog, kubeClient: f.kubeClient, namespace: namespace, clientTLS: clientTLS}), nil
 
 	case "github":
 		url := f.githubEndpoint
-		var roles []string
+		var roles, fallbacks []string
+		var insecure bool
+		var tokenRef *corev1.SecretKeySelector
+		var clientTLS clientTLS
 		if spec.GitHub != nil {
 			if spec.GitHub.URL != "" {
 				url = spec.GitHub.URL
 			}
+			fallbacks = spec.GitHub.FallbackURLs
 			roles = spec.GitHub.Roles
+			insecure = isTrue(spec.GitHub.InsecureSkipTLSVerify)
+			tokenRef = spec.GitHub.TokenSecretRef
+			clientTLS.caSecret, clientTLS.caConfigMap = spec.GitHub.CASecretRef, spec.GitHub.CAConfigMapRef
 		}
 		selector := func(data map[string]any) ([]string, error) {
 			return githubSelectorWithRoles(data, roles)
 		}
-		return &staticHTTPProvider{client: f.httpClient, url: url, selector: selector}, nil
+		httpClient, err := f.clientFor(insecure)
+		if err != nil {
+			return nil, err
+		}
+		return f.cached(spec, &staticHTTPProvider{
+			client:       httpClient,
+			url:          url,
+			fallbackURLs: fallbacks,
+			selector:     selector,
+			retry:        f.retry,
+			insecure:     insecure,
+			kubeClient:   f.kubeClient,
+			namespace:    namespace,
+			tokenRef:     tokenRef,
+			clientTLS:    clientTLS,
+			signer:       f.signerFor(namespace, spec),
+			bodyLog:      f.bodyLog,
+		}), nil
+
+	case "azure":
+		url := f.azureEndpoint
+		var tags []string
+		if spec.Azure != nil {
+			if spec.Azure.URL != "" {
+				url = spec.Azure.URL
+			}
+			tags = spec.Azure.ServiceTags
+		}
+		if url == "" {
+			return nil, fmt.Errorf("azure provider requires azure.url: the service tags document has no stable default URL")
+		}
+		selector := func(data map[string]any) ([]string, error) {
+			return azureSelectorWithTags(data, tags)
+		}
+		httpClient, err := f.clientFor(false)
+		if err != nil {
+			return nil, err
+		}
+		return f.cached(spec, &staticHTTPProvider{client: httpClient, url: url, selector: selector, retry: f.retry, signer: f.signerFor(namespace, spec), bodyLog: f.bodyLog}), nil
+
+	case "cloudflare":
+		url := f.cloudflareEndpoint
+		if spec.Cloudflare != nil && spec.Cloudflare.URL != "" {
+			url = spec.Cloudflare.URL
+		}
+		httpClient, err := f.clientFor(false)
+		if err != nil {
+			return nil, err
+		}
+		return f.cached(spec, &staticHTTPProvider{client: httpClient, url: url, selector: cloudflareSelector, retry: f.retry, signer: f.signerFor(namespace, spec), bodyLog: f.bodyLog}), nil
 
 	case "configmap":
 		cfg := spec.ConfigMap
@@ -141,7 +371,15 @@ func (f *Factory) FromSpec(namespace string, spec v1alpha1.ProviderSpec) (Provid
 		if ns == "" {
 			ns = namespace
 		}
-		return &configMapProvider{client: f.kubeClient, namespace: ns, name: cfg.Name, key: cfg.Key}, nil
+		provider := &configMapProvider{client: f.kubeClient, namespace: ns, name: cfg.Name, key: cfg.Key, allowEmpty: allowEmpty(spec), cache: f.configMaps, versionPrefix: cfg.VersionCommentPrefix}
+		if cfg.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(cfg.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("configMap namespaceSelector: %w", err)
+			}
+			provider.namespaceSelector = selector
+		}
+		return provider, nil
 
 	case "jsonendpoint":
 		cfg := spec.JSONEndpoint
@@ -151,7 +389,22 @@ func (f *Factory) FromSpec(namespace string, spec v1alpha1.ProviderSpec) (Provid
 		}
 		secretHeaders := make([]secretHeaderRef, 0, len(cfg.HeaderSecretRefs))
 		for _, ref := range cfg.HeaderSecretRefs {
-			secretHeaders = append(secretHeaders, secretHeaderRef{name: ref.Name, selector: ref.SecretKeyRef})
+			secretHeaders = append(secretHeaders, secretHeaderRef{name: ref.Name, selector: ref.SecretKeyRef, prefix: ref.ValuePrefix})
+		}
+		var basicAuth *basicAuthRef
+		if ref := cfg.BasicAuthSecretRef; ref != nil {
+			secret := corev1.LocalObjectReference{Name: ref.Name}
+			usernameKey, passwordKey := ref.UsernameKey, ref.PasswordKey
+			if usernameKey == "" {
+				usernameKey = corev1.BasicAuthUsernameKey
+			}
+			if passwordKey == "" {
+				passwordKey = corev1.BasicAuthPasswordKey
+			}
+			basicAuth = &basicAuthRef{
+				username: corev1.SecretKeySelector{LocalObjectReference: secret, Key: usernameKey},
+				password: corev1.SecretKeySelector{LocalObjectReference: secret, Key: passwordKey},
+			}
 		}
 
 		var filter *jsonFilter
@@ -167,23 +420,130 @@ func (f *Factory) FromSpec(namespace string, spec v1alpha1.ProviderSpec) (Provid
 			}
 		}
 
+		clientTLS := clientTLS{caSecret: cfg.CASecretRef, caConfigMap: cfg.CAConfigMapRef}
+		if cfg.TLSClientSecretRef != nil {
+			clientTLS.certSecret = cfg.TLSClientSecretRef.Name
+		}
+
+		insecure := isTrue(cfg.InsecureSkipTLSVerify)
+		httpClient, err := f.clientFor(insecure)
+		if err != nil {
+			return nil, err
+		}
 		return &jsonEndpointProvider{
-			client:        f.httpClient,
+			client:        httpClient,
+			retry:         f.retry,
+			insecure:      insecure,
+			signer:        f.signerFor(namespace, spec),
 			kubeClient:    f.kubeClient,
 			namespace:     namespace,
 			url:           cfg.URL,
 			fieldPath:     cfg.FieldPath,
+			pathSeparator: cfg.PathSeparator,
 			headers:       headers,
 			secretHeaders: secretHeaders,
+			basicAuth:     basicAuth,
+			bearerToken:   cfg.BearerTokenSecretRef,
+			clientTLS:     clientTLS,
 			filter:        filter,
+			minItems:      cfg.MinItems,
+			allowEmpty:    allowEmpty(spec),
+			stripJSONP:    cfg.StripJSONP,
+			bodyLog:       f.bodyLog,
+
+			nextPagePath: cfg.NextPageFieldPath,
+			maxPages:     cfg.MaxPages,
+
+			metadataFields: cfg.MetadataFields,
+		}, nil
+
+	case "directory":
+		cfg := spec.Directory
+		headers := http.Header{}
+		for k, v := range cfg.Headers {
+			headers.Set(k, v)
+		}
+		secretHeaders := make([]secretHeaderRef, 0, len(cfg.HeaderSecretRefs))
+		for _, ref := range cfg.HeaderSecretRefs {
+			secretHeaders = append(secretHeaders, secretHeaderRef{name: ref.Name, selector: ref.SecretKeyRef, prefix: ref.ValuePrefix})
+		}
+
+		httpClient, err := f.clientFor(false)
+		if err != nil {
+			return nil, err
+		}
+		return &directoryProvider{
+			endpoint: &jsonEndpointProvider{
+				client:        httpClient,
+				retry:         f.retry,
+				signer:        f.signerFor(namespace, spec),
+				kubeClient:    f.kubeClient,
+				namespace:     namespace,
+				url:           cfg.URL,
+				fieldPath:     cfg.EntriesPath,
+				pathSeparator: cfg.PathSeparator,
+				headers:       headers,
+				secretHeaders: secretHeaders,
+				allowEmpty:    allowEmpty(spec),
+				bodyLog:       f.bodyLog,
+				nextPagePath:  cfg.NextPagePath,
+				maxPages:      cfg.MaxPages,
+			},
+			attribute: cfg.Attribute,
+		}, nil
+
+	case "redis":
+		cfg := spec.Redis
+		return &redisProvider{
+			kubeClient:    f.kubeClient,
+			namespace:     namespace,
+			secretName:    cfg.ConnectionSecretRef.Name,
+			key:           cfg.Key,
+			db:            cfg.DB,
+			tls:           cfg.TLS,
+			minTLSVersion: f.minTLSVersion,
+			allowEmpty:    allowEmpty(spec),
+		}, nil
+
+	case "scrape":
+		cfg := spec.Scrape
+		var pattern *regexp.Regexp
+		if cfg.Pattern != "" {
+			var err error
+			if pattern, err = regexp.Compile(cfg.Pattern); err != nil {
+				return nil, fmt.Errorf("scrape pattern: %w", err)
+			}
+		}
+		httpClient, err := f.clientFor(false)
+		if err != nil {
+			return nil, err
+		}
+		return &scrapeProvider{
+			client:       httpClient,
+			retry:        f.retry,
+			signer:       f.signerFor(namespace, spec),
+			url:          cfg.URL,
+			pattern:      pattern,
+			maxBodyBytes: cfg.MaxBodyBytes,
+			allowEmpty:   allowEmpty(spec),
+			bodyLog:      f.bodyLog,
 		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", spec.Name)
 	}
 }
 
-// sanitize ensures CIDRs are trimmed and non-empty.
-func sanitize(cidrs []string) ([]string, error) {
+func allowEmpty(spec v1alpha1.ProviderSpec) bool {
+	return isTrue(spec.AllowEmpty)
+}
+
+// ErrEmptyFeed is returned when a provider's source was reachable and well-formed but
+// yielded no CIDRs, as distinct from an unreachable endpoint or unparsable document.
+var ErrEmptyFeed = errors.New("provider returned no CIDRs")
+
+// sanitize ensures CIDRs are trimmed and non-empty. An empty result is ErrEmptyFeed unless
+// allowEmpty is set.
+func sanitize(cidrs []string, allowEmpty bool) ([]string, error) {
 	results := make([]string, 0, len(cidrs))
 	for _, cidr := range cidrs {
 		trimmed := strings.TrimSpace(cidr)
@@ -192,8 +552,8 @@ func sanitize(cidrs []string) ([]string, error) {
 		}
 		results = append(results, trimmed)
 	}
-	if len(results) == 0 {
-		return nil, errors.New("provider returned no CIDRs")
+	if len(results) == 0 && !allowEmpty {
+		return nil, ErrEmptyFeed
 	}
 	return results, nil
 }
@@ -1,10 +1,14 @@
 package providers
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -106,6 +110,105 @@ func TestFactory_WithEmptyOptions(t *testing.T) {
 	}
 }
 
+func TestFactory_BuildAll(t *testing.T) {
+	factory := NewFactory(nil, &http.Client{})
+	specs := []v1alpha1.ProviderSpec{
+		{Name: "google"},
+		{Name: "configMap", ConfigMap: &v1alpha1.ConfigMapProviderSpec{Name: "allowlist"}},
+		{Name: "aws"},
+	}
+
+	built, errs := factory.BuildAll("default", specs)
+	if len(built) != len(specs) || len(errs) != len(specs) {
+		t.Fatalf("BuildAll() returned %d providers and %d errors, want %d of each", len(built), len(errs), len(specs))
+	}
+	for _, i := range []int{0, 2} {
+		if errs[i] != nil || built[i] == nil {
+			t.Errorf("spec %d: provider = %v, err = %v, want a provider", i, built[i], errs[i])
+		}
+	}
+	if built[1] != nil {
+		t.Errorf("invalid spec built a provider: %v", built[1])
+	}
+	if errs[1] == nil || errs[1].Error() != "configMap provider requires name and key" {
+		t.Errorf("invalid spec error = %v, want the validation error", errs[1])
+	}
+}
+
+func TestFactory_ResolveConfigRef(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"cidrs":["192.0.2.0/24","198.51.100.0/24","203.0.113.0/24"]}`))
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared-feed", Namespace: "default"},
+				Data: map[string]string{v1alpha1.DefaultProviderConfigKey: "name: jsonEndpoint\n" +
+					"displayName: shared\n" +
+					"jsonEndpoint:\n  url: " + server.URL + "\n  fieldPath: cidrs\n" +
+					"allowedSupernets: [\"192.0.0.0/8\"]\n"},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "nested", Namespace: "default"},
+				Data:       map[string]string{"spec": "configRef: {name: shared-feed}"},
+			},
+		).
+		Build()
+	factory := NewFactory(kubeClient, server.Client())
+
+	inline := v1alpha1.ProviderSpec{
+		Name:             "jsonEndpoint",
+		DisplayName:      "partner",
+		JSONEndpoint:     &v1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "cidrs"},
+		AllowedSupernets: []string{"192.0.0.0/8"},
+	}
+	referenced := v1alpha1.ProviderSpec{
+		Name:        "jsonEndpoint",
+		DisplayName: "partner",
+		ConfigRef:   &v1alpha1.ProviderConfigRef{Name: "shared-feed"},
+	}
+
+	resolved, err := factory.ResolveConfigRef(context.Background(), "default", referenced)
+	if err != nil {
+		t.Fatalf("ResolveConfigRef() error = %v", err)
+	}
+	if !reflect.DeepEqual(resolved, inline) {
+		t.Errorf("ResolveConfigRef() = %+v, want %+v", resolved, inline)
+	}
+
+	fetch := func(spec v1alpha1.ProviderSpec) []string {
+		t.Helper()
+		provider, err := factory.FromSpec("default", spec)
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		cidrs, err := provider.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		return cidrs
+	}
+	if got, want := fetch(resolved), fetch(inline); !reflect.DeepEqual(got, want) {
+		t.Errorf("referenced provider fetched %v, inline provider fetched %v", got, want)
+	}
+
+	if _, err := factory.FromSpec("default", referenced); err == nil {
+		t.Error("FromSpec() built a provider from an unresolved configRef")
+	}
+	for _, ref := range []v1alpha1.ProviderConfigRef{{Name: "missing"}, {Name: "shared-feed", Key: "other"}, {Name: "nested", Key: "spec"}} {
+		spec := referenced
+		spec.ConfigRef = &ref
+		if _, err := factory.ResolveConfigRef(context.Background(), "default", spec); err == nil {
+			t.Errorf("ResolveConfigRef(%+v) succeeded, want an error", ref)
+		}
+	}
+}
+
 func TestFactory_FromSpec_Google(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -135,6 +238,63 @@ func TestFactory_FromSpec_Google(t *testing.T) {
 	}
 }
 
+func TestFactory_FromSpec_GoogleFeed(t *testing.T) {
+	goog := map[string]any{"prefixes": []any{
+		map[string]any{"ipv4Prefix": "8.8.4.0/24"},
+	}}
+	cloud := map[string]any{"prefixes": []any{
+		map[string]any{"ipv4Prefix": "34.80.0.0/15", "service": "Google Cloud", "scope": "asia-east1"},
+		map[string]any{"ipv4Prefix": "34.16.0.0/17", "service": "Google Cloud", "scope": "us-central1"},
+	}}
+
+	tests := []struct {
+		name         string
+		feed         string
+		scope        []string
+		wantEndpoint string
+		wantCloud    bool
+	}{
+		{name: "default", wantEndpoint: defaultGoogleEndpoint},
+		{name: "goog", feed: "goog", wantEndpoint: defaultGoogleEndpoint},
+		{name: "cloud", feed: "cloud", scope: []string{"us-central1"}, wantEndpoint: defaultGoogleCloudEndpoint, wantCloud: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := NewFactory(nil, &http.Client{})
+			provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+				Name:   "google",
+				Google: &v1alpha1.GoogleProviderSpec{Feed: tt.feed, Scope: tt.scope},
+			})
+			if err != nil {
+				t.Fatalf("FromSpec() error = %v", err)
+			}
+			static := provider.(*staticHTTPProvider)
+			if static.url != tt.wantEndpoint {
+				t.Errorf("endpoint = %q, want %q", static.url, tt.wantEndpoint)
+			}
+
+			got, err := static.selector(cloud)
+			if err != nil {
+				t.Fatalf("selector(cloud.json) error = %v", err)
+			}
+			if tt.wantCloud && (len(got) != 1 || got[0] != "34.16.0.0/17") {
+				t.Errorf("selector(cloud.json) = %v, want the us-central1 range", got)
+			}
+			_, err = static.selector(goog)
+			if gotErr := err != nil; gotErr != tt.wantCloud {
+				t.Errorf("selector(goog.json) error = %v, want error %v", err, tt.wantCloud)
+			}
+		})
+	}
+
+	if _, err := NewFactory(nil, &http.Client{}).FromSpec("default", v1alpha1.ProviderSpec{
+		Name:   "google",
+		Google: &v1alpha1.GoogleProviderSpec{Feed: "ipranges"},
+	}); err == nil {
+		t.Error("FromSpec() accepted an unknown google feed")
+	}
+}
+
 func TestFactory_FromSpec_AWS(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -164,6 +324,45 @@ func TestFactory_FromSpec_AWS(t *testing.T) {
 	}
 }
 
+func TestFactory_FromSpec_AWSExcludedRegions(t *testing.T) {
+	feed := map[string]any{"prefixes": []any{
+		map[string]any{"ip_prefix": "3.5.140.0/22", "region": "ap-northeast-2", "service": "AMAZON"},
+		map[string]any{"ip_prefix": "52.80.0.0/16", "region": "cn-north-1", "service": "AMAZON"},
+		map[string]any{"ip_prefix": "52.82.0.0/17", "region": "CN-NORTHWEST-1", "service": "AMAZON"},
+	}}
+
+	tests := []struct {
+		name    string
+		regions []string
+		want    []string
+	}{
+		{name: "no spec regions", want: []string{"3.5.140.0/22"}},
+		{name: "spec regions override", regions: []string{"cn-north-1"}, want: []string{"52.80.0.0/16"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := NewFactory(nil, &http.Client{}, WithAWSExcludedRegions("cn-north-1", " cn-northwest-1", ""))
+			provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+				Name: "aws",
+				AWS:  &v1alpha1.AWSProviderSpec{Regions: tt.regions},
+			})
+			if err != nil {
+				t.Fatalf("FromSpec() error = %v", err)
+			}
+			got, err := provider.(*staticHTTPProvider).selector(feed)
+			if err != nil {
+				t.Fatalf("selector() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := len(feed["prefixes"].([]any)); got != 3 {
+		t.Errorf("feed was modified: %d prefixes left, want 3", got)
+	}
+}
+
 func TestFactory_FromSpec_GitHub(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -440,3 +639,73 @@ func TestFactory_FromSpec_CaseInsensitive(t *testing.T) {
 		})
 	}
 }
+
+func TestFactory_FromSpec_AllowEmpty(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "default"},
+		Data:       map[string]string{"cidrs": ""},
+	}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(configMap).
+		Build()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"prefixes": []}`))
+	}))
+	defer server.Close()
+
+	factory := NewFactory(kubeClient, server.Client(), WithGoogleEndpoint(server.URL))
+	allow := true
+
+	t.Run("static provider errors on empty", func(t *testing.T) {
+		provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{Name: "google"})
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		if _, err := provider.Fetch(context.Background()); err == nil {
+			t.Error("expected error for empty google feed, got nil")
+		}
+	})
+
+	t.Run("static provider rejects allowEmpty", func(t *testing.T) {
+		if _, err := factory.FromSpec("default", v1alpha1.ProviderSpec{Name: "google", AllowEmpty: &allow}); err == nil {
+			t.Error("expected validation error for allowEmpty on google provider, got nil")
+		}
+	})
+
+	t.Run("configmap errors on empty by default", func(t *testing.T) {
+		provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+			Name:      "configMap",
+			ConfigMap: &v1alpha1.ConfigMapProviderSpec{Name: "empty", Key: "cidrs"},
+		})
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		if _, err := provider.Fetch(context.Background()); err == nil {
+			t.Error("expected error for empty configmap without allowEmpty, got nil")
+		}
+	})
+
+	t.Run("configmap allows empty when configured", func(t *testing.T) {
+		provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+			Name:       "configMap",
+			ConfigMap:  &v1alpha1.ConfigMapProviderSpec{Name: "empty", Key: "cidrs"},
+			AllowEmpty: &allow,
+		})
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		got, err := provider.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("Fetch() got %d CIDRs, want 0", len(got))
+		}
+	})
+}
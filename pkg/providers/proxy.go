@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+// proxyKey identifies the proxied clients of a Factory. namespace is only set with
+// credentials, which are read from it.
+type proxyKey struct {
+	namespace   string
+	url         string
+	credentials v1alpha1.BasicAuthSecretRef
+}
+
+// proxyCache keeps one proxied Factory per proxyKey, so that providers rebuilt on every
+// sync reuse the connection pool of their proxy instead of opening a new one. It is safe
+// for concurrent use.
+type proxyCache struct {
+	mu        sync.Mutex
+	factories map[proxyKey]*Factory
+}
+
+func newProxyCache() *proxyCache {
+	return &proxyCache{factories: map[proxyKey]*Factory{}}
+}
+
+// withProxy returns a copy of f whose HTTP clients send every request through the
+// proxyURL of spec, so that each provider built from it, insecure or not, uses the proxy.
+// f is not modified, and the copy is reused by every spec naming the same proxy and
+// credentials. The credentials of proxyCredentialsSecretRef are read from the namespace
+// for every request, so rotated credentials are used right away.
+func (f *Factory) withProxy(namespace string, spec v1alpha1.ProviderSpec) (*Factory, error) {
+	key := proxyKey{url: spec.ProxyURL}
+	if ref := spec.ProxyCredentialsSecretRef; ref != nil {
+		key.namespace, key.credentials = namespace, *ref
+	}
+	if f.proxies != nil {
+		f.proxies.mu.Lock()
+		defer f.proxies.mu.Unlock()
+		if proxied, ok := f.proxies.factories[key]; ok {
+			return proxied, nil
+		}
+	}
+
+	proxyURL, err := url.Parse(spec.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s provider proxyURL: %w", spec.Name, err)
+	}
+	if f.clientErr != nil {
+		return nil, f.clientErr
+	}
+	client, transport, err := cloneTransport(f.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("%s provider proxyURL: %w", spec.Name, err)
+	}
+	transport.Proxy = http.ProxyURL(proxyURL)
+	if ref := spec.ProxyCredentialsSecretRef; ref != nil {
+		transport.Proxy = f.proxyWithCredentials(namespace, proxyURL, ref)
+	}
+
+	proxied := *f
+	proxied.setClients(client)
+	if f.proxies != nil {
+		f.proxies.factories[key] = &proxied
+	}
+	return &proxied, nil
+}
+
+// proxyWithCredentials returns a Transport.Proxy function adding the username and password
+// of ref to proxyURL. The Secret is read with the context of the request.
+func (f *Factory) proxyWithCredentials(namespace string, proxyURL *url.URL, ref *v1alpha1.BasicAuthSecretRef) func(*http.Request) (*url.URL, error) {
+	secret := corev1.LocalObjectReference{Name: ref.Name}
+	usernameKey, passwordKey := ref.UsernameKey, ref.PasswordKey
+	if usernameKey == "" {
+		usernameKey = corev1.BasicAuthUsernameKey
+	}
+	if passwordKey == "" {
+		passwordKey = corev1.BasicAuthPasswordKey
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if f.kubeClient == nil {
+			return nil, fmt.Errorf("kube client not configured for proxy credentials")
+		}
+		username, err := readSecretKey(req.Context(), f.kubeClient, namespace, corev1.SecretKeySelector{LocalObjectReference: secret, Key: usernameKey})
+		if err != nil {
+			return nil, fmt.Errorf("reading proxy credentials: %w", err)
+		}
+		password, err := readSecretKey(req.Context(), f.kubeClient, namespace, corev1.SecretKeySelector{LocalObjectReference: secret, Key: passwordKey})
+		if err != nil {
+			return nil, fmt.Errorf("reading proxy credentials: %w", err)
+		}
+		authenticated := *proxyURL
+		authenticated.User = url.UserPassword(strings.TrimRight(username, "\r\n"), strings.TrimRight(password, "\r\n"))
+		return &authenticated, nil
+	}
+}
@@ -0,0 +1,147 @@
+package providers
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestFactory_ProxyURL(t *testing.T) {
+	// The proxy stub answers for hosts that do not resolve, so a successful fetch shows
+	// that the request went through it.
+	var requested []string
+	var gotAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.String())
+		gotAuth = r.Header.Get("Proxy-Authorization")
+		w.Write([]byte(`{"cidrs":["10.0.0.0/24"],"prefixes":[{"ipv4Prefix":"8.8.8.0/24"}]}`))
+	}))
+	defer proxy.Close()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy-credentials", Namespace: "default"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data:       map[string][]byte{"username": []byte("operator"), "password": []byte("s3cret\n")},
+	}).Build()
+
+	tests := []struct {
+		name     string
+		spec     v1alpha1.ProviderSpec
+		want     []string
+		wantAuth string
+		wantErr  string
+	}{
+		{
+			name: "jsonEndpoint",
+			spec: v1alpha1.ProviderSpec{
+				Name:         "jsonEndpoint",
+				JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{URL: "http://cidrs.internal.invalid/ranges.json", FieldPath: "cidrs"},
+				ProxyURL:     proxy.URL,
+			},
+			want: []string{"10.0.0.0/24"},
+		},
+		{
+			name: "google with proxy credentials",
+			spec: v1alpha1.ProviderSpec{
+				Name:                      "google",
+				Google:                    &v1alpha1.GoogleProviderSpec{URL: "http://feeds.internal.invalid/goog.json"},
+				ProxyURL:                  proxy.URL,
+				ProxyCredentialsSecretRef: &v1alpha1.BasicAuthSecretRef{Name: "proxy-credentials"},
+			},
+			want:     []string{"8.8.8.0/24"},
+			wantAuth: "Basic " + base64.StdEncoding.EncodeToString([]byte("operator:s3cret")),
+		},
+		{
+			name: "missing proxy credentials",
+			spec: v1alpha1.ProviderSpec{
+				Name:                      "jsonEndpoint",
+				JSONEndpoint:              &v1alpha1.JSONEndpointProviderSpec{URL: "http://cidrs.internal.invalid/ranges.json", FieldPath: "cidrs"},
+				ProxyURL:                  proxy.URL,
+				ProxyCredentialsSecretRef: &v1alpha1.BasicAuthSecretRef{Name: "missing"},
+			},
+			wantErr: "reading proxy credentials: fetching secret default/missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested, gotAuth = nil, ""
+			shared := &http.Client{}
+			provider, err := NewFactory(kubeClient, shared, WithRetry(1, 0)).FromSpec("default", tt.spec)
+			if err != nil {
+				t.Fatalf("FromSpec() error = %v", err)
+			}
+			got, err := provider.Fetch(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(requested) != 0 {
+					t.Errorf("proxy received %v without credentials", requested)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.want)
+			}
+			if len(requested) != 1 || !strings.HasPrefix(requested[0], "http://") || !strings.Contains(requested[0], ".internal.invalid/") {
+				t.Errorf("proxy received %v, want the provider URL", requested)
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("Proxy-Authorization = %q, want %q", gotAuth, tt.wantAuth)
+			}
+			if shared.Transport != nil {
+				t.Error("the shared HTTP client was modified")
+			}
+		})
+	}
+}
+
+func TestFactory_ProxyURLReusesClient(t *testing.T) {
+	factory := NewFactory(nil, &http.Client{})
+	build := func(namespace string, credentials *v1alpha1.BasicAuthSecretRef) *http.Client {
+		t.Helper()
+		provider, err := factory.FromSpec(namespace, v1alpha1.ProviderSpec{
+			Name:                      "jsonEndpoint",
+			JSONEndpoint:              &v1alpha1.JSONEndpointProviderSpec{URL: "http://cidrs.internal.invalid/ranges.json", FieldPath: "cidrs"},
+			ProxyURL:                  "http://proxy.internal.invalid:3128",
+			ProxyCredentialsSecretRef: credentials,
+		})
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		return provider.(*jsonEndpointProvider).client
+	}
+
+	first := build("team-a", nil)
+	if build("team-b", nil) != first {
+		t.Error("providers of the same proxy use different HTTP clients")
+	}
+	credentials := &v1alpha1.BasicAuthSecretRef{Name: "proxy-credentials"}
+	withCredentials := build("team-a", credentials)
+	if withCredentials == first {
+		t.Error("providers with proxy credentials share the client of providers without")
+	}
+	if build("team-b", credentials) == withCredentials {
+		t.Error("proxy credentials of different namespaces share an HTTP client")
+	}
+	if build("team-a", credentials) != withCredentials {
+		t.Error("providers with the same proxy credentials use different HTTP clients")
+	}
+}
@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Keys read from the Redis connection Secret.
+const (
+	redisAddressKey  = "address"
+	redisUsernameKey = "username"
+	redisPasswordKey = "password"
+)
+
+type redisProvider struct {
+	kubeClient client.Reader
+	namespace  string
+	secretName string
+	key        string
+	db         int
+	tls        bool
+	// minTLSVersion is the oldest TLS version accepted when tls is set.
+	minTLSVersion uint16
+	allowEmpty    bool
+}
+
+func (p *redisProvider) Fetch(ctx context.Context) ([]string, error) {
+	opts, err := p.options(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rdb := redis.NewClient(opts)
+	defer rdb.Close()
+
+	kind, err := rdb.Type(ctx, p.key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis type %s: %w", p.key, err)
+	}
+	var members []string
+	switch kind {
+	case "set":
+		members, err = rdb.SMembers(ctx, p.key).Result()
+		sort.Strings(members)
+	case "list":
+		members, err = rdb.LRange(ctx, p.key, 0, -1).Result()
+	case "none":
+		// A missing key is an empty allowlist.
+	default:
+		return nil, fmt.Errorf("redis key %s has type %s, expected set or list", p.key, kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis read %s: %w", p.key, err)
+	}
+	return sanitize(members, p.allowEmpty)
+}
+
+// options builds client options from the connection Secret.
+func (p *redisProvider) options(ctx context.Context) (*redis.Options, error) {
+	if p.kubeClient == nil {
+		return nil, fmt.Errorf("kube client not configured for redis connection secret")
+	}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: p.secretName, Namespace: p.namespace}
+	if err := p.kubeClient.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("fetching secret %s: %w", key.String(), err)
+	}
+	address := string(secret.Data[redisAddressKey])
+	if address == "" {
+		return nil, fmt.Errorf("secret %s missing key %s", key.String(), redisAddressKey)
+	}
+	opts := &redis.Options{
+		Addr:     address,
+		Username: string(secret.Data[redisUsernameKey]),
+		Password: string(secret.Data[redisPasswordKey]),
+		DB:       p.db,
+	}
+	if p.tls {
+		opts.TLSConfig = &tls.Config{MinVersion: p.minTLSVersion}
+	}
+	return opts, nil
+}
@@ -0,0 +1,122 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"reflect"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestRedisProvider_Fetch(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("bots", "s3cret")
+	if _, err := server.SAdd("allow:set", "198.51.100.0/24", "192.0.2.0/24"); err != nil {
+		t.Fatalf("SAdd: %v", err)
+	}
+	if _, err := server.Push("allow:list", "203.0.113.0/24", " ", "192.0.2.0/24"); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	server.Set("allow:string", "10.0.0.0/8")
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"},
+			Data: map[string][]byte{
+				"address":  []byte(server.Addr()),
+				"username": []byte("bots"),
+				"password": []byte("s3cret"),
+			},
+		}).
+		Build()
+	factory := NewFactory(kubeClient, nil)
+
+	fetch := func(key string, allowEmpty bool) ([]string, error) {
+		provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+			Name:       "redis",
+			Redis:      &v1alpha1.RedisProviderSpec{Key: key, ConnectionSecretRef: corev1.LocalObjectReference{Name: "redis"}},
+			AllowEmpty: &allowEmpty,
+		})
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		return provider.Fetch(context.Background())
+	}
+
+	got, err := fetch("allow:set", false)
+	if err != nil {
+		t.Fatalf("Fetch(set) error = %v", err)
+	}
+	if want := []string{"192.0.2.0/24", "198.51.100.0/24"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch(set) = %v, want %v", got, want)
+	}
+
+	got, err = fetch("allow:list", false)
+	if err != nil {
+		t.Fatalf("Fetch(list) error = %v", err)
+	}
+	if want := []string{"203.0.113.0/24", "192.0.2.0/24"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch(list) = %v, want %v", got, want)
+	}
+
+	if _, err := fetch("allow:string", false); err == nil {
+		t.Error("Fetch(string) error = nil, want unsupported type error")
+	}
+	if _, err := fetch("allow:missing", false); err == nil {
+		t.Error("Fetch(missing) error = nil, want empty result error")
+	}
+	if got, err := fetch("allow:missing", true); err != nil || len(got) != 0 {
+		t.Errorf("Fetch(missing, allowEmpty) = %v, %v, want empty result", got, err)
+	}
+}
+
+func TestRedisProvider_MissingAddress(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"}}).
+		Build()
+
+	provider := &redisProvider{kubeClient: kubeClient, namespace: "default", secretName: "redis", key: "allow"}
+	if _, err := provider.Fetch(context.Background()); err == nil {
+		t.Fatal("Fetch() error = nil, want missing address error")
+	}
+}
+
+func TestRedisProvider_MinTLSVersion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"},
+			Data:       map[string][]byte{"address": []byte("redis.example:6380")},
+		}).
+		Build()
+	provider, err := NewFactory(kubeClient, nil, WithMinTLSVersion(tls.VersionTLS13)).FromSpec("default", v1alpha1.ProviderSpec{
+		Name:  "redis",
+		Redis: &v1alpha1.RedisProviderSpec{Key: "allow", TLS: true, ConnectionSecretRef: corev1.LocalObjectReference{Name: "redis"}},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	opts, err := provider.(*redisProvider).options(context.Background())
+	if err != nil {
+		t.Fatalf("options() error = %v", err)
+	}
+	if opts.TLSConfig == nil || opts.TLSConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("TLSConfig = %#v, want MinVersion TLS 1.3", opts.TLSConfig)
+	}
+}
@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultRetryMaxDelay caps the delay between two attempts.
+	defaultRetryMaxDelay = 30 * time.Second
+)
+
+// retryPolicy controls how provider HTTP requests are retried.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	maxElapsed  time.Duration
+}
+
+// WithRetry retries provider requests that fail with a network error or a 5xx response,
+// up to maxAttempts attempts in total, doubling the delay after baseDelay on each retry.
+func WithRetry(maxAttempts int, baseDelay time.Duration) FactoryOption {
+	return func(f *Factory) {
+		if maxAttempts > 0 {
+			f.retry.maxAttempts = maxAttempts
+		}
+		if baseDelay > 0 {
+			f.retry.baseDelay = baseDelay
+		}
+	}
+}
+
+// WithRetryMaxElapsed bounds the total time spent retrying a single fetch. Once the next
+// attempt would start after d, the last error is returned. Zero means no bound.
+func WithRetryMaxElapsed(d time.Duration) FactoryOption {
+	return func(f *Factory) {
+		if d >= 0 {
+			f.retry.maxElapsed = d
+		}
+	}
+}
+
+func defaultRetryPolicy() retryPolicy {
+	return retryPolicy{
+		maxAttempts: 1,
+		baseDelay:   500 * time.Millisecond,
+		maxDelay:    defaultRetryMaxDelay,
+	}
+}
+
+// do sends the request produced by newRequest, retrying according to the policy. The
+// response of the final attempt is returned as-is, so callers keep their status handling.
+func (p retryPolicy) do(ctx context.Context, client *http.Client, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if !p.retryable(ctx, resp, err) || attempt >= p.maxAttempts {
+			return resp, err
+		}
+
+		delay := p.backoff(attempt)
+		if p.maxElapsed > 0 && time.Since(start)+delay > p.maxElapsed {
+			return resp, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (p retryPolicy) retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.baseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.maxDelay > 0 && delay >= p.maxDelay {
+			return p.maxDelay
+		}
+	}
+	if p.maxDelay > 0 && delay > p.maxDelay {
+		return p.maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,178 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := retryPolicy{baseDelay: 100 * time.Millisecond, maxDelay: 350 * time.Millisecond}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 3, want: 350 * time.Millisecond},
+		{attempt: 10, want: 350 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := policy.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestStaticHTTPProvider_RetryMaxElapsed(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	factory := NewFactory(nil, server.Client(),
+		WithGoogleEndpoint(server.URL),
+		WithRetry(100, 20*time.Millisecond),
+		WithRetryMaxElapsed(150*time.Millisecond),
+	)
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{Name: "google"})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	start := time.Now()
+	_, err = provider.Fetch(context.Background())
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected error from always-failing server, got nil")
+	}
+	if got := attempts.Load(); got < 2 || got >= 100 {
+		t.Errorf("attempts = %d, want more than one and fewer than the attempt limit", got)
+	}
+	if elapsed > time.Second {
+		t.Errorf("retry loop ran for %v, expected it to stop after the configured elapsed time", elapsed)
+	}
+}
+
+func TestStaticHTTPProvider_RetryRespectsContextDeadline(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider := &staticHTTPProvider{
+		client:   server.Client(),
+		url:      server.URL,
+		selector: googleSelector,
+		retry:    retryPolicy{maxAttempts: 10, baseDelay: time.Second, maxDelay: time.Minute},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := provider.Fetch(ctx); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Fetch() took %v, expected it to give up before the context deadline", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1 since the backoff exceeds the deadline", got)
+	}
+}
+
+func TestStaticHTTPProvider_RetrySucceedsAfterTransientFailure(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"prefixes":[{"ipv4Prefix":"8.8.8.0/24"}]}`))
+	}))
+	defer server.Close()
+
+	provider := &staticHTTPProvider{
+		client:   server.Client(),
+		url:      server.URL,
+		selector: googleSelector,
+		retry:    retryPolicy{maxAttempts: 5, baseDelay: 5 * time.Millisecond, maxDelay: time.Second},
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("Fetch() got %d CIDRs, want 1", len(got))
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("attempts = %d, want 3", attempts.Load())
+	}
+}
+
+func TestRetry_ClientErrorsAreNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	policy := retryPolicy{maxAttempts: 5, baseDelay: time.Millisecond}
+	tests := map[string]Provider{
+		"static": &staticHTTPProvider{client: server.Client(), url: server.URL, selector: googleSelector, retry: policy},
+		"jsonEndpoint": &jsonEndpointProvider{
+			client: server.Client(), url: server.URL, fieldPath: "cidrs", headers: http.Header{}, retry: policy,
+		},
+	}
+	for name, provider := range tests {
+		t.Run(name, func(t *testing.T) {
+			attempts.Store(0)
+			if _, err := provider.Fetch(context.Background()); err == nil {
+				t.Fatal("expected error for 403 response, got nil")
+			}
+			if got := attempts.Load(); got != 1 {
+				t.Errorf("attempts = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestRetry_StopsWhenContextCanceledBetweenAttempts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		cancel()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	provider := &staticHTTPProvider{
+		client:   server.Client(),
+		url:      server.URL,
+		selector: googleSelector,
+		retry:    retryPolicy{maxAttempts: 5, baseDelay: time.Hour},
+	}
+	if _, err := provider.Fetch(ctx); err == nil {
+		t.Fatal("expected error after cancellation, got nil")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
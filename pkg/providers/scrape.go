@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+)
+
+// defaultScrapePattern matches IPv4 and IPv6 CIDR-shaped tokens. Candidates are checked
+// with net.ParseCIDR, so the pattern may be loose.
+var defaultScrapePattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}/\d{1,2}\b|(?i:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}/\d{1,3}\b`)
+
+// defaultScrapeMaxBodyBytes bounds the size of a scraped page unless configured otherwise.
+const defaultScrapeMaxBodyBytes = 5 << 20
+
+// scrapeProvider fetches a page, such as HTML or an RSS/Atom feed, and extracts the CIDRs
+// matching pattern from its body.
+type scrapeProvider struct {
+	client       *http.Client
+	retry        retryPolicy
+	signer       RequestSigner
+	url          string
+	pattern      *regexp.Regexp
+	maxBodyBytes int64
+	allowEmpty   bool
+	bodyLog      bodyLogger
+}
+
+func (p *scrapeProvider) Fetch(ctx context.Context) ([]string, error) {
+	resp, err := p.retry.do(ctx, p.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+		if err != nil {
+			return nil, err
+		}
+		return sign(p.signer, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	limit := p.maxBodyBytes
+	if limit <= 0 {
+		limit = defaultScrapeMaxBodyBytes
+	}
+	reader, logBody := p.bodyLog.wrap(ctx, p.url, capReader(resp.Body, limit))
+	body, err := io.ReadAll(reader)
+	logBody()
+	if err != nil {
+		return nil, err
+	}
+	return sanitize(scrapeCIDRs(body, p.pattern), p.allowEmpty)
+}
+
+// scrapeCIDRs returns the tokens in body matching pattern that parse as CIDRs, in order of
+// first appearance. When pattern has a capturing group, the first group is the token.
+func scrapeCIDRs(body []byte, pattern *regexp.Regexp) []string {
+	if pattern == nil {
+		pattern = defaultScrapePattern
+	}
+	seen := make(map[string]struct{})
+	var cidrs []string
+	for _, match := range pattern.FindAllSubmatch(body, -1) {
+		token := match[0]
+		if len(match) > 1 {
+			token = match[1]
+		}
+		if _, _, err := net.ParseCIDR(string(token)); err != nil {
+			continue
+		}
+		if _, ok := seen[string(token)]; ok {
+			continue
+		}
+		seen[string(token)] = struct{}{}
+		cidrs = append(cidrs, string(token))
+	}
+	return cidrs
+}
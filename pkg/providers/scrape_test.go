@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+const scrapePage = `<html>
+<head><title>Crawler IP ranges (v2.1, updated 2024-05-01)</title></head>
+<body>
+<p>Our crawler connects from the following ranges:</p>
+<ul>
+  <li>192.0.2.0/24</li>
+  <li><code>198.51.100.0/25</code> (EU)</li>
+  <li>2001:db8:1::/48</li>
+  <li>192.0.2.0/24</li>
+</ul>
+<p>Contact 10.0.0.1 or see page 3/4. Ratio 1.2.3/45 and 300.1.1.1/24 are not ranges.</p>
+<a href="https://example.com/docs/2024/05">docs</a>
+</body>
+</html>`
+
+func TestScrapeProvider_ExtractsOnlyCIDRs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(scrapePage))
+	}))
+	defer server.Close()
+
+	factory := NewFactory(nil, server.Client())
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name:   "scrape",
+		Scrape: &v1alpha1.ScrapeProviderSpec{URL: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	want := []string{"192.0.2.0/24", "198.51.100.0/25", "2001:db8:1::/48"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Fetch() = %v, want %v", got, want)
+	}
+}
+
+func TestScrapeProvider_PatternCaptureGroup(t *testing.T) {
+	got := scrapeCIDRs([]byte(scrapePage), regexp.MustCompile(`<code>([^<]+)</code>`))
+	if want := []string{"198.51.100.0/25"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("scrapeCIDRs() = %v, want %v", got, want)
+	}
+}
+
+func TestScrapeProvider_BodyLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("192.0.2.0/24\n", 100)))
+	}))
+	defer server.Close()
+
+	provider := &scrapeProvider{client: server.Client(), url: server.URL, maxBodyBytes: 64}
+	if _, err := provider.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "exceeds 64 bytes") {
+		t.Fatalf("expected body limit error, got %v", err)
+	}
+}
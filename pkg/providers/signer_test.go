@@ -0,0 +1,117 @@
+package providers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestHMACSigning_VerifiableSignature(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	key := []byte("shared-secret")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "feed-signing", Namespace: "default"},
+		Data:       map[string][]byte{"key": key},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp := r.Header.Get(SignatureTimestampHeader)
+		got, err := hex.DecodeString(r.Header.Get("X-Feed-Signature"))
+		if timestamp == "" || err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(SignaturePayload(r.Method, r.URL.RequestURI(), timestamp)))
+		if !hmac.Equal(got, mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"cidrs":["10.0.0.0/24"]}`))
+	}))
+	defer server.Close()
+
+	factory := NewFactory(kubeClient, server.Client())
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "jsonEndpoint",
+		JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{
+			URL:       server.URL + "/ranges?format=json",
+			FieldPath: "cidrs",
+		},
+		HMACSigning: &v1alpha1.HMACSigningSpec{
+			SecretKeyRef: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "feed-signing"},
+				Key:                  "key",
+			},
+			Header: "X-Feed-Signature",
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != "10.0.0.0/24" {
+		t.Errorf("Fetch() = %v, want [10.0.0.0/24]", got)
+	}
+}
+
+type recordingSigner struct {
+	calls int
+}
+
+func (s *recordingSigner) Sign(req *http.Request) error {
+	s.calls++
+	req.Header.Set("X-Custom-Auth", "signed")
+	return nil
+}
+
+func TestWithRequestSigner_RegisteredByLabel(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("X-Custom-Auth")
+		w.Write([]byte(`{"prefixes":[{"ipv4Prefix":"8.8.8.0/24"}]}`))
+	}))
+	defer server.Close()
+
+	signer := &recordingSigner{}
+	factory := NewFactory(nil, server.Client(), WithGoogleEndpoint(server.URL), WithRequestSigner("Partner-Feed", signer))
+
+	unsigned, err := factory.FromSpec("default", v1alpha1.ProviderSpec{Name: "google"})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	if _, err := unsigned.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if gotAuth != "" || signer.calls != 0 {
+		t.Errorf("unregistered provider was signed: header=%q calls=%d", gotAuth, signer.calls)
+	}
+
+	signed, err := factory.FromSpec("default", v1alpha1.ProviderSpec{Name: "google", DisplayName: "partner-feed"})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	if _, err := signed.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if gotAuth != "signed" || signer.calls != 1 {
+		t.Errorf("registered provider not signed: header=%q calls=%d", gotAuth, signer.calls)
+	}
+}
@@ -3,49 +3,242 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type staticHTTPProvider struct {
 	client   *http.Client
 	url      string
 	selector func(map[string]any) ([]string, error)
+
+	// fallbackURLs are tried in order after url fails. They are fetched without the
+	// bearer token and request signer, which are meant for url's host only.
+	fallbackURLs []string
+
+	// feedTime reads the data timestamp from the document, e.g. AWS createDate. When it is
+	// nil or finds none, the response's Last-Modified header is used instead.
+	feedTime func(map[string]any) (time.Time, bool)
+	dataTime time.Time
+
+	retry    retryPolicy
+	insecure bool
+	signer   RequestSigner
+	bodyLog  bodyLogger
+
+	// kubeClient and namespace resolve tokenRef, when set, into a bearer token, and the
+	// references of clientTLS.
+	kubeClient client.Reader
+	namespace  string
+	tokenRef   *corev1.SecretKeySelector
+	clientTLS  clientTLS
+
+	// cache, when set, shares successful fetches under cacheKey.
+	cache    *responseCache
+	cacheKey string
+	// conditional, when set, turns refetches of an unchanged document into 304 responses.
+	conditional *conditionalCache
 }
 
+// Fetch tries url and then each fallback URL in order, returning the CIDRs of the first
+// endpoint that serves a usable document. A cached result of an identical fetch is
+// returned instead while it is fresh.
 func (p *staticHTTPProvider) Fetch(ctx context.Context) ([]string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if entry, ok := p.cache.get(p.cacheKey); ok {
+		p.dataTime = entry.dataTime
+		return entry.cidrs, nil
+	}
+	cidrs, err := p.fetch(ctx)
 	if err != nil {
 		return nil, err
 	}
+	p.cache.put(p.cacheKey, cidrs, p.dataTime)
+	return cidrs, nil
+}
+
+func (p *staticHTTPProvider) fetch(ctx context.Context) ([]string, error) {
+	if len(p.fallbackURLs) == 0 {
+		return p.fetchFrom(ctx, p.url)
+	}
 
-	resp, err := p.client.Do(req)
+	logger := log.FromContext(ctx)
+	var errs []error
+	for _, url := range append([]string{p.url}, p.fallbackURLs...) {
+		cidrs, err := p.fetchFrom(ctx, url)
+		if err == nil {
+			return cidrs, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		logger.Info("provider endpoint failed, trying next mirror", "url", redactURL(url), "error", err.Error())
+		errs = append(errs, fmt.Errorf("%s: %w", redactURL(url), err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (p *staticHTTPProvider) fetchFrom(ctx context.Context, url string) ([]string, error) {
+	warnInsecure(ctx, p.insecure, url)
+	payload, lastModified, err := p.fetchPayload(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	cidrs, err := p.selector(payload)
+	if err != nil {
+		return nil, err
+	}
+	cidrs, err = sanitize(cidrs, false)
 	if err != nil {
 		return nil, err
 	}
+	p.dataTime = lastModified
+	if p.feedTime != nil {
+		if created, ok := p.feedTime(payload); ok {
+			p.dataTime = created
+		}
+	}
+	return cidrs, nil
+}
+
+// DataTimestamp implements TimestampReporter.
+func (p *staticHTTPProvider) DataTimestamp() (time.Time, bool) {
+	return p.dataTime, !p.dataTime.IsZero()
+}
+
+// errUnexpectedNotModified reports a 304 response to a request that was not conditional.
+var errUnexpectedNotModified = errors.New("unexpected status: 304 Not Modified")
+
+// fetchPayload GETs url and decodes the JSON document, also returning the response's
+// Last-Modified time, which is zero when absent or malformed. With a conditional cache,
+// a document stored by an earlier fetch is revalidated and reused when the server answers
+// 304 Not Modified.
+func (p *staticHTTPProvider) fetchPayload(ctx context.Context, url string) (map[string]any, time.Time, error) {
+	previous, ok := p.conditional.get(url)
+	var since *conditionalEntry
+	if ok {
+		since = &previous
+	}
+	body, header, err := p.fetchDocument(ctx, url, since, false)
+	if errors.Is(err, errUnexpectedNotModified) {
+		// Nothing is stored to reuse, e.g. because an intermediary cache answered an
+		// unconditional request; fetch the full document past any caches instead.
+		log.FromContext(ctx).Info("provider endpoint answered 304 without a stored document, refetching", "url", redactURL(url))
+		body, header, err = p.fetchDocument(ctx, url, nil, true)
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, time.Time{}, err
+	}
+	lastModified, _ := http.ParseTime(header.Get("Last-Modified"))
+	return payload, lastModified, nil
+}
+
+// fetchDocument GETs the body of url. When since is set the request is conditional on it
+// and a 304 response returns its stored body and validators. bypassCaches asks
+// intermediaries not to answer from their cache.
+func (p *staticHTTPProvider) fetchDocument(ctx context.Context, url string, since *conditionalEntry, bypassCaches bool) ([]byte, http.Header, error) {
+	// Mirrors may be run by third parties, so credentials go to the primary endpoint only.
+	var token string
+	var signer RequestSigner
+	if url == p.url {
+		var err error
+		if token, err = p.resolveToken(ctx); err != nil {
+			return nil, nil, err
+		}
+		signer = p.signer
+	}
+	httpClient, err := p.clientTLS.client(ctx, p.kubeClient, p.namespace, p.client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if httpClient != p.client {
+		defer httpClient.CloseIdleConnections()
+	}
+
+	resp, err := p.retry.do(ctx, httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if since != nil {
+			since.setHeaders(req)
+		}
+		if bypassCaches {
+			req.Header.Set("Cache-Control", "no-cache")
+		}
+		return sign(signer, req)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		if since == nil {
+			return nil, nil, errUnexpectedNotModified
+		}
+		header := http.Header{}
+		header.Set("Last-Modified", since.lastModified)
+		return since.body, header, nil
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+		return nil, nil, fmt.Errorf("unexpected status: %s", resp.Status)
 	}
 
-	var payload map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return nil, err
+	decoded, err := decodeBody(resp, maxDecodedBodyBytes)
+	if err != nil {
+		return nil, nil, err
 	}
+	reader, logBody := p.bodyLog.wrap(ctx, url, decoded)
+	defer logBody()
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.conditional.put(url, body, resp.Header)
+	return body, resp.Header, nil
+}
 
-	cidrs, err := p.selector(payload)
+// resolveToken reads the bearer token from tokenRef, returning "" when none is configured.
+func (p *staticHTTPProvider) resolveToken(ctx context.Context) (string, error) {
+	if p.tokenRef == nil {
+		return "", nil
+	}
+	if p.kubeClient == nil {
+		return "", fmt.Errorf("kube client not configured for token secret")
+	}
+	token, err := readSecretKey(ctx, p.kubeClient, p.namespace, *p.tokenRef)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return sanitize(cidrs)
+	return strings.TrimSpace(token), nil
 }
 
 const (
 	defaultGoogleEndpoint = "https://www.gstatic.com/ipranges/goog.json"
-	defaultAWSEndpoint    = "https://ip-ranges.amazonaws.com/ip-ranges.json"
-	defaultGitHubEndpoint = "https://api.github.com/meta"
+	// defaultGoogleCloudEndpoint serves the cloud feed of the google provider.
+	defaultGoogleCloudEndpoint = "https://www.gstatic.com/ipranges/cloud.json"
+	defaultAWSEndpoint         = "https://ip-ranges.amazonaws.com/ip-ranges.json"
+	defaultGitHubEndpoint      = "https://api.github.com/meta"
+	defaultCloudflareEndpoint  = "https://api.cloudflare.com/client/v4/ips"
 )
 
 func googleSelectorWithScope(data map[string]any, scopes []string) ([]string, error) {
@@ -90,10 +283,122 @@ func googleSelectorWithScope(data map[string]any, scopes []string) ([]string, er
 	return results, nil
 }
 
+// cloudSelectorWithScope reads cloud.json, whose entries each carry a region as their
+// scope. Documents with unscoped entries, such as goog.json served at the wrong URL, are
+// rejected rather than silently matching nothing under a scope filter.
+func cloudSelectorWithScope(data map[string]any, scopes []string) ([]string, error) {
+	prefixesRaw, ok := data["prefixes"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing prefixes")
+	}
+	for _, prefix := range prefixesRaw {
+		item, _ := prefix.(map[string]any)
+		if item == nil {
+			continue
+		}
+		if scope, _ := item["scope"].(string); strings.TrimSpace(scope) == "" {
+			return nil, fmt.Errorf("prefix entry without scope; not a cloud.json feed")
+		}
+	}
+	return googleSelectorWithScope(data, scopes)
+}
+
+// GoogleFeedSummary lists the distinct filter values found in a Google IP ranges feed.
+type GoogleFeedSummary struct {
+	// Scopes are the values usable in GoogleProviderSpec.Scope.
+	Scopes []string
+	// Services are the service names published alongside each prefix.
+	Services []string
+}
+
+// DescribeGoogleFeed fetches the Google IP ranges feed and returns the distinct scopes and
+// services it contains. An empty url uses the factory's Google endpoint.
+func (f *Factory) DescribeGoogleFeed(ctx context.Context, url string) (GoogleFeedSummary, error) {
+	if strings.TrimSpace(url) == "" {
+		url = f.googleEndpoint
+	}
+	httpClient, err := f.clientFor(false)
+	if err != nil {
+		return GoogleFeedSummary{}, err
+	}
+	provider := &staticHTTPProvider{client: httpClient, url: url, retry: f.retry}
+	payload, _, err := provider.fetchPayload(ctx, url)
+	if err != nil {
+		return GoogleFeedSummary{}, err
+	}
+	return summarizeGoogleFeed(payload)
+}
+
+func summarizeGoogleFeed(data map[string]any) (GoogleFeedSummary, error) {
+	prefixesRaw, ok := data["prefixes"].([]any)
+	if !ok {
+		return GoogleFeedSummary{}, fmt.Errorf("missing prefixes")
+	}
+
+	scopes := map[string]struct{}{}
+	services := map[string]struct{}{}
+	for _, prefix := range prefixesRaw {
+		item, _ := prefix.(map[string]any)
+		if item == nil {
+			continue
+		}
+		if scope, _ := item["scope"].(string); strings.TrimSpace(scope) != "" {
+			scopes[strings.TrimSpace(scope)] = struct{}{}
+		}
+		if service, _ := item["service"].(string); strings.TrimSpace(service) != "" {
+			services[strings.TrimSpace(service)] = struct{}{}
+		}
+	}
+	return GoogleFeedSummary{Scopes: sortedKeys(scopes), Services: sortedKeys(services)}, nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func googleSelector(data map[string]any) ([]string, error) {
 	return googleSelectorWithScope(data, nil)
 }
 
+// awsCreateDateLayout is the format of the createDate field in ip-ranges.json.
+const awsCreateDateLayout = "2006-01-02-15-04-05"
+
+// checkAWSFeedAge returns an error when the feed was published more than maxAge before
+// now. The publication time is read from createDate, falling back to syncToken (Unix
+// seconds). A zero maxAge disables the check.
+func checkAWSFeedAge(data map[string]any, maxAge time.Duration, now time.Time) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	created, ok := awsFeedTime(data)
+	if !ok {
+		return fmt.Errorf("cannot determine feed age: missing or invalid createDate and syncToken")
+	}
+	if age := now.Sub(created); age > maxAge {
+		return fmt.Errorf("feed is stale: created %s, %s ago exceeds maxFeedAge %s", created.Format(time.RFC3339), age.Round(time.Second), maxAge)
+	}
+	return nil
+}
+
+func awsFeedTime(data map[string]any) (time.Time, bool) {
+	if raw, ok := data["createDate"].(string); ok {
+		if created, err := time.Parse(awsCreateDateLayout, raw); err == nil {
+			return created, true
+		}
+	}
+	if raw, ok := data["syncToken"].(string); ok {
+		if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return time.Unix(seconds, 0).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
 func awsSelectorWithFilter(data map[string]any, services, regions, networkBorderGroups []string) ([]string, error) {
 	prefixesRaw, ok := data["prefixes"].([]any)
 	if !ok {
@@ -159,6 +464,35 @@ func awsSelectorWithFilter(data map[string]any, services, regions, networkBorder
 	return results, nil
 }
 
+// excludeAWSRegions returns a copy of the AWS feed without the prefixes of the given
+// regions. data is not modified.
+func excludeAWSRegions(data map[string]any, regions []string) map[string]any {
+	prefixesRaw, ok := data["prefixes"].([]any)
+	if !ok || len(regions) == 0 {
+		return data
+	}
+	excluded := make(map[string]bool, len(regions))
+	for _, reg := range regions {
+		excluded[strings.ToLower(strings.TrimSpace(reg))] = true
+	}
+	kept := make([]any, 0, len(prefixesRaw))
+	for _, prefix := range prefixesRaw {
+		if item, _ := prefix.(map[string]any); item != nil {
+			region, _ := item["region"].(string)
+			if excluded[strings.ToLower(strings.TrimSpace(region))] {
+				continue
+			}
+		}
+		kept = append(kept, prefix)
+	}
+	filtered := make(map[string]any, len(data))
+	for k, v := range data {
+		filtered[k] = v
+	}
+	filtered["prefixes"] = kept
+	return filtered
+}
+
 func awsSelector(data map[string]any) ([]string, error) {
 	defaultServices := []string{"AMAZON", "AMAZON_CONNECT"}
 	defaultRegions := []string{"GLOBAL", "us-east-1"}
@@ -172,6 +506,7 @@ func githubSelectorWithRoles(data map[string]any, roles []string) ([]string, err
 	}
 
 	results := make([]string, 0)
+	found := false
 	for _, role := range roles {
 		roleKey := strings.ToLower(strings.TrimSpace(role))
 		roleData, ok := data[roleKey].([]any)
@@ -179,6 +514,7 @@ func githubSelectorWithRoles(data map[string]any, roles []string) ([]string, err
 			// Role field doesn't exist or is not an array, skip
 			continue
 		}
+		found = true
 
 		for _, item := range roleData {
 			if cidr, ok := item.(string); ok {
@@ -189,28 +525,120 @@ func githubSelectorWithRoles(data map[string]any, roles []string) ([]string, err
 		}
 	}
 
-	if len(results) == 0 {
+	// Present but empty role arrays are a valid, empty feed; sanitize reports it.
+	if !found {
 		return nil, fmt.Errorf("no CIDRs found for roles: %v", roles)
 	}
 	return results, nil
 }
 
 func githubSelector(data map[string]any) ([]string, error) {
-	hooks, ok := data["hooks"]
-	emptyHooksArray := false
-	if ok {
-		if arr, ok := hooks.([]any); ok {
-			if len(arr) == 0 {
-				emptyHooksArray = true
+	return githubSelectorWithRoles(data, nil)
+}
+
+// azureSelectorWithTags flattens the addressPrefixes of the entries of an Azure service
+// tags document, keeping only entries named in tags when any are given. Names match
+// case-insensitively.
+func azureSelectorWithTags(data map[string]any, tags []string) ([]string, error) {
+	values, ok := data["values"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing values")
+	}
+
+	wanted := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			wanted[tag] = true
+		}
+	}
+
+	results := make([]string, 0)
+	matched := false
+	for _, value := range values {
+		entry, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if len(wanted) > 0 {
+			name, _ := entry["name"].(string)
+			if !wanted[strings.ToLower(name)] {
+				continue
+			}
+		}
+		matched = true
+		properties, _ := entry["properties"].(map[string]any)
+		prefixes, _ := properties["addressPrefixes"].([]any)
+		for _, item := range prefixes {
+			if cidr, ok := item.(string); ok {
+				if value := strings.TrimSpace(cidr); value != "" {
+					results = append(results, value)
+				}
 			}
 		}
 	}
-	results, err := githubSelectorWithRoles(data, nil)
-	if err != nil {
-		if emptyHooksArray && strings.Contains(err.Error(), "no CIDRs found") {
-			return []string{}, nil
+
+	if len(wanted) > 0 && !matched {
+		return nil, fmt.Errorf("no entries found for service tags: %v", tags)
+	}
+	return results, nil
+}
+
+func azureSelector(data map[string]any) ([]string, error) {
+	return azureSelectorWithTags(data, nil)
+}
+
+// cloudflareSelector reads the IPv4 and IPv6 ranges from the result object of the
+// Cloudflare API envelope. A response with success=false is an error even when it is
+// otherwise well-formed, since Cloudflare reports failures in-band.
+func cloudflareSelector(data map[string]any) ([]string, error) {
+	success, ok := data["success"].(bool)
+	if !ok {
+		return nil, fmt.Errorf("missing success flag")
+	}
+	if !success {
+		return nil, fmt.Errorf("cloudflare API reported failure: %s", cloudflareErrors(data))
+	}
+	result, ok := data["result"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("missing result")
+	}
+
+	results := make([]string, 0)
+	found := false
+	for _, key := range []string{"ipv4_cidrs", "ipv6_cidrs"} {
+		items, ok := result[key].([]any)
+		if !ok {
+			continue
+		}
+		found = true
+		for _, item := range items {
+			if cidr, ok := item.(string); ok {
+				if value := strings.TrimSpace(cidr); value != "" {
+					results = append(results, value)
+				}
+			}
 		}
-		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("missing ipv4_cidrs and ipv6_cidrs")
 	}
 	return results, nil
 }
+
+// cloudflareErrors joins the messages of the errors array of a failed Cloudflare response.
+func cloudflareErrors(data map[string]any) string {
+	var messages []string
+	if errs, ok := data["errors"].([]any); ok {
+		for _, item := range errs {
+			if entry, ok := item.(map[string]any); ok {
+				if message, ok := entry["message"].(string); ok && message != "" {
+					messages = append(messages, message)
+				}
+			}
+		}
+	}
+	if len(messages) == 0 {
+		return "no error details"
+	}
+	return strings.Join(messages, "; ")
+}
@@ -3,9 +3,20 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
 )
 
 func TestGoogleSelector(t *testing.T) {
@@ -255,6 +266,228 @@ func TestGitHubSelector(t *testing.T) {
 	}
 }
 
+// azureServiceTags builds a service tags document with n regional AzureCloud entries
+// followed by an AzureFrontDoor.Backend entry.
+func azureServiceTags(n int) map[string]any {
+	values := make([]any, 0, n+1)
+	for i := 0; i < n; i++ {
+		values = append(values, map[string]any{
+			"name": "AzureCloud.region" + strconv.Itoa(i),
+			"id":   "AzureCloud.region" + strconv.Itoa(i),
+			"properties": map[string]any{
+				"changeNumber":    float64(1),
+				"region":          "region" + strconv.Itoa(i),
+				"addressPrefixes": []any{"10." + strconv.Itoa(i/256) + "." + strconv.Itoa(i%256) + ".0/24", "2603:1000:" + strconv.FormatInt(int64(i), 16) + "::/48"},
+			},
+		})
+	}
+	values = append(values, map[string]any{
+		"name": "AzureFrontDoor.Backend",
+		"id":   "AzureFrontDoor.Backend",
+		"properties": map[string]any{
+			"addressPrefixes": []any{"13.73.248.16/29", "20.21.37.40/29"},
+		},
+	})
+	return map[string]any{"changeNumber": float64(300), "cloud": "Public", "values": values}
+}
+
+func TestAzureSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]any
+		tags    []string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "all entries of a large document",
+			data:    azureServiceTags(500),
+			wantLen: 1002,
+		},
+		{
+			name:    "service tag filter",
+			data:    azureServiceTags(500),
+			tags:    []string{"azurefrontdoor.backend"},
+			wantLen: 2,
+		},
+		{
+			name:    "unknown service tag",
+			data:    azureServiceTags(3),
+			tags:    []string{"AzureTrafficManager"},
+			wantErr: true,
+		},
+		{
+			name: "entries without prefixes are skipped",
+			data: map[string]any{"values": []any{
+				map[string]any{"name": "Empty", "properties": map[string]any{}},
+				"not an entry",
+				map[string]any{"name": "Storage", "properties": map[string]any{"addressPrefixes": []any{"20.38.96.0/19", ""}}},
+			}},
+			wantLen: 1,
+		},
+		{
+			name:    "missing values",
+			data:    map[string]any{"cloud": "Public"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := azureSelectorWithTags(tt.data, tt.tags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("azureSelectorWithTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(got) != tt.wantLen {
+				t.Errorf("azureSelectorWithTags() got %d CIDRs, want %d", len(got), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestAzureProvider_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(azureServiceTags(200))
+	}))
+	defer server.Close()
+
+	factory := NewFactory(nil, server.Client(), WithAzureEndpoint(server.URL))
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name:  "azure",
+		Azure: &v1alpha1.AzureProviderSpec{ServiceTags: []string{"AzureFrontDoor.Backend"}},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if strings.Join(got, ",") != "13.73.248.16/29,20.21.37.40/29" {
+		t.Errorf("Fetch() = %v, want the AzureFrontDoor.Backend prefixes", got)
+	}
+
+	if _, err := NewFactory(nil, server.Client()).FromSpec("default", v1alpha1.ProviderSpec{Name: "azure"}); err == nil {
+		t.Error("FromSpec() without any Azure URL succeeded, want error")
+	}
+}
+
+func TestCloudflareSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]any
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "valid Cloudflare response",
+			data: map[string]any{
+				"success": true,
+				"result": map[string]any{
+					"ipv4_cidrs": []any{"173.245.48.0/20", " 103.21.244.0/22 "},
+					"ipv6_cidrs": []any{"2400:cb00::/32", ""},
+					"etag":       "38f79d050aa027e3be3865e495dcc9bc",
+				},
+				"errors":   []any{},
+				"messages": []any{},
+			},
+			want: []string{"173.245.48.0/20", "103.21.244.0/22", "2400:cb00::/32"},
+		},
+		{
+			name: "IPv4 only",
+			data: map[string]any{
+				"success": true,
+				"result":  map[string]any{"ipv4_cidrs": []any{"173.245.48.0/20"}},
+			},
+			want: []string{"173.245.48.0/20"},
+		},
+		{
+			name: "success false",
+			data: map[string]any{
+				"success": false,
+				"errors":  []any{map[string]any{"code": 10000, "message": "Authentication error"}},
+				"result":  nil,
+			},
+			wantErr: "cloudflare API reported failure: Authentication error",
+		},
+		{
+			name:    "missing success flag",
+			data:    map[string]any{"result": map[string]any{"ipv4_cidrs": []any{"173.245.48.0/20"}}},
+			wantErr: "missing success flag",
+		},
+		{
+			name:    "missing result",
+			data:    map[string]any{"success": true},
+			wantErr: "missing result",
+		},
+		{
+			name:    "result without CIDR arrays",
+			data:    map[string]any{"success": true, "result": map[string]any{"etag": "x"}},
+			wantErr: "missing ipv4_cidrs and ipv6_cidrs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cloudflareSelector(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("cloudflareSelector() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cloudflareSelector() error = %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("cloudflareSelector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloudflareProvider_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"success":  true,
+			"errors":   []any{},
+			"messages": []any{},
+			"result": map[string]any{
+				"ipv4_cidrs": []any{"173.245.48.0/20", "103.21.244.0/22"},
+				"ipv6_cidrs": []any{"2400:cb00::/32"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	factory := NewFactory(nil, server.Client(), WithCloudflareEndpoint(server.URL))
+	if got := factory.Config().CloudflareEndpoint; got != server.URL {
+		t.Errorf("Config().CloudflareEndpoint = %q, want %q", got, server.URL)
+	}
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{Name: "cloudflare"})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	want := []string{"173.245.48.0/20", "103.21.244.0/22", "2400:cb00::/32"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Fetch() = %v, want %v", got, want)
+	}
+
+	// A per-spec URL takes precedence over the factory endpoint.
+	provider, err = NewFactory(nil, server.Client(), WithCloudflareEndpoint("http://127.0.0.1:1")).
+		FromSpec("default", v1alpha1.ProviderSpec{Name: "cloudflare", Cloudflare: &v1alpha1.CloudflareProviderSpec{URL: server.URL}})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	if _, err := provider.Fetch(context.Background()); err != nil {
+		t.Errorf("Fetch() with spec URL error = %v", err)
+	}
+}
+
 func TestStaticHTTPProvider_Fetch(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -381,3 +614,352 @@ func TestStaticHTTPProvider_FetchContextCancellation(t *testing.T) {
 		t.Error("expected error when context is cancelled, got nil")
 	}
 }
+
+func TestDescribeGoogleFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]any{
+			"prefixes": []any{
+				map[string]any{"ipv4Prefix": "34.1.208.0/20", "service": "Google Cloud", "scope": "africa-south1"},
+				map[string]any{"ipv4Prefix": "34.35.0.0/16", "service": "Google Cloud", "scope": "africa-south1"},
+				map[string]any{"ipv6Prefix": "2600:1900:8000::/44", "service": "Google Cloud", "scope": "us-central1"},
+				map[string]any{"ipv4Prefix": "8.8.4.0/24"},
+				"invalid",
+			},
+		})
+	}))
+	defer server.Close()
+
+	factory := NewFactory(nil, server.Client(), WithGoogleEndpoint(server.URL))
+	summary, err := factory.DescribeGoogleFeed(context.Background(), "")
+	if err != nil {
+		t.Fatalf("DescribeGoogleFeed() error = %v", err)
+	}
+
+	wantScopes := []string{"africa-south1", "us-central1"}
+	if len(summary.Scopes) != len(wantScopes) {
+		t.Fatalf("Scopes = %v, want %v", summary.Scopes, wantScopes)
+	}
+	for i := range wantScopes {
+		if summary.Scopes[i] != wantScopes[i] {
+			t.Errorf("Scopes[%d] = %v, want %v", i, summary.Scopes[i], wantScopes[i])
+		}
+	}
+	if len(summary.Services) != 1 || summary.Services[0] != "Google Cloud" {
+		t.Errorf("Services = %v, want [Google Cloud]", summary.Services)
+	}
+}
+
+func TestSummarizeGoogleFeed_MissingPrefixes(t *testing.T) {
+	if _, err := summarizeGoogleFeed(map[string]any{}); err == nil {
+		t.Error("expected error for payload without prefixes, got nil")
+	}
+}
+
+func TestAWSProvider_MaxFeedAge(t *testing.T) {
+	tests := []struct {
+		name       string
+		createDate string
+		wantErr    bool
+	}{
+		{name: "stale feed", createDate: "2020-01-02-03-04-05", wantErr: true},
+		{name: "fresh feed", createDate: time.Now().UTC().Add(-time.Hour).Format(awsCreateDateLayout), wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]any{
+					"syncToken":  "1577934245",
+					"createDate": tt.createDate,
+					"prefixes": []any{
+						map[string]any{"ip_prefix": "3.5.140.0/22", "region": "ap-northeast-2", "service": "AMAZON", "network_border_group": "ap-northeast-2"},
+					},
+				})
+			}))
+			defer server.Close()
+
+			factory := NewFactory(nil, server.Client(), WithAWSEndpoint(server.URL))
+			provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+				Name: "aws",
+				AWS:  &v1alpha1.AWSProviderSpec{MaxFeedAge: metav1.Duration{Duration: 7 * 24 * time.Hour}},
+			})
+			if err != nil {
+				t.Fatalf("FromSpec() error = %v", err)
+			}
+
+			got, err := provider.Fetch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "stale") {
+					t.Errorf("Fetch() error = %v, want a staleness error", err)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Errorf("Fetch() got %d CIDRs, want 1", len(got))
+			}
+		})
+	}
+}
+
+func TestCheckAWSFeedAge_SyncTokenFallback(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	data := map[string]any{"syncToken": strconv.FormatInt(now.Add(-48*time.Hour).Unix(), 10)}
+
+	if err := checkAWSFeedAge(data, 24*time.Hour, now); err == nil {
+		t.Error("expected staleness error from syncToken, got nil")
+	}
+	if err := checkAWSFeedAge(data, 72*time.Hour, now); err != nil {
+		t.Errorf("checkAWSFeedAge() error = %v, want nil", err)
+	}
+	if err := checkAWSFeedAge(map[string]any{}, 24*time.Hour, now); err == nil {
+		t.Error("expected error when the feed age cannot be determined, got nil")
+	}
+}
+
+func TestGitHubProvider_TokenSecretRef(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("ghp_example\n")},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		// Authenticated responses carry additional fields alongside the role lists.
+		json.NewEncoder(w).Encode(map[string]any{
+			"verifiable_password_authentication": false,
+			"ssh_key_fingerprints":               map[string]any{"SHA256_ED25519": "+DiY3wvvV6TuJJhbpZisF/zLDA0zPMSvHdkr4UvCOqU"},
+			"domains":                            map[string]any{"website": []any{"*.github.com"}},
+			"hooks":                              []any{"192.30.252.0/22", "185.199.108.0/22"},
+			"actions":                            []any{"4.175.114.51/32"},
+		})
+	}))
+	defer server.Close()
+
+	factory := NewFactory(kubeClient, server.Client(), WithGitHubEndpoint(server.URL))
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "github",
+		GitHub: &v1alpha1.GitHubProviderSpec{
+			TokenSecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "github-token"},
+				Key:                  "token",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if gotAuth != "Bearer ghp_example" {
+		t.Errorf("Authorization header = %q, want %q", gotAuth, "Bearer ghp_example")
+	}
+	if len(got) != 2 || got[0] != "192.30.252.0/22" || got[1] != "185.199.108.0/22" {
+		t.Errorf("Fetch() = %v, want the hooks ranges", got)
+	}
+}
+
+func TestStaticHTTPProvider_FallbackURLs(t *testing.T) {
+	var primaryHits int
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"unexpected":true}`))
+	}))
+	defer broken.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"prefixes":[{"ipv4Prefix":"8.8.4.0/24"}]}`))
+	}))
+	defer mirror.Close()
+
+	factory := NewFactory(nil, http.DefaultClient)
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "google",
+		Google: &v1alpha1.GoogleProviderSpec{
+			URL:          primary.URL,
+			FallbackURLs: []string{broken.URL, mirror.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	got, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != "8.8.4.0/24" {
+		t.Errorf("Fetch() = %v, want the mirror's CIDR", got)
+	}
+	if primaryHits != 1 {
+		t.Errorf("primary hits = %d, want 1", primaryHits)
+	}
+
+	// When every endpoint fails, the error names each of them.
+	provider, _ = factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name:   "google",
+		Google: &v1alpha1.GoogleProviderSpec{URL: primary.URL, FallbackURLs: []string{broken.URL}},
+	})
+	_, err = provider.Fetch(context.Background())
+	if err == nil {
+		t.Fatal("Fetch() error = nil, want error when every endpoint fails")
+	}
+	for _, want := range []string{primary.URL, broken.URL} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Fetch() error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestStaticHTTPProvider_FallbackURLsGetNoCredentials(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("ghp_example")},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	var primaryAuth, primarySigned string
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryAuth, primarySigned = r.Header.Get("Authorization"), r.Header.Get("X-Custom-Auth")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+	var mirrorAuth, mirrorSigned string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorAuth, mirrorSigned = r.Header.Get("Authorization"), r.Header.Get("X-Custom-Auth")
+		w.Write([]byte(`{"hooks":["192.30.252.0/22"]}`))
+	}))
+	defer mirror.Close()
+
+	factory := NewFactory(kubeClient, http.DefaultClient, WithRequestSigner("github", &recordingSigner{}))
+	provider, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name: "github",
+		GitHub: &v1alpha1.GitHubProviderSpec{
+			URL:          primary.URL,
+			FallbackURLs: []string{mirror.URL},
+			TokenSecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "github-token"},
+				Key:                  "token",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+
+	if _, err := provider.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if primaryAuth != "Bearer ghp_example" || primarySigned != "signed" {
+		t.Errorf("primary Authorization = %q, signature = %q, want the token and signature", primaryAuth, primarySigned)
+	}
+	if mirrorAuth != "" || mirrorSigned != "" {
+		t.Errorf("mirror Authorization = %q, signature = %q, want neither", mirrorAuth, mirrorSigned)
+	}
+}
+
+func TestStaticHTTPProvider_DataTimestamp(t *testing.T) {
+	lastModified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		w.Write([]byte(`{"prefixes":[{"ipv4Prefix":"8.8.4.0/24"}]}`))
+	}))
+	defer google.Close()
+	aws := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		w.Write([]byte(`{"createDate":"2024-02-15-08-30-00","prefixes":[{"ip_prefix":"3.5.140.0/22","service":"AMAZON","region":"us-east-1"}]}`))
+	}))
+	defer aws.Close()
+
+	factory := NewFactory(nil, http.DefaultClient)
+	tests := []struct {
+		name string
+		spec v1alpha1.ProviderSpec
+		want time.Time
+	}{
+		{
+			name: "Last-Modified header",
+			spec: v1alpha1.ProviderSpec{Name: "google", Google: &v1alpha1.GoogleProviderSpec{URL: google.URL}},
+			want: lastModified,
+		},
+		{
+			name: "AWS createDate takes precedence",
+			spec: v1alpha1.ProviderSpec{Name: "aws", AWS: &v1alpha1.AWSProviderSpec{URL: aws.URL}},
+			want: time.Date(2024, 2, 15, 8, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := factory.FromSpec("default", tt.spec)
+			if err != nil {
+				t.Fatalf("FromSpec() error = %v", err)
+			}
+			if _, err := provider.Fetch(context.Background()); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			reporter, ok := provider.(TimestampReporter)
+			if !ok {
+				t.Fatal("provider does not implement TimestampReporter")
+			}
+			got, ok := reporter.DataTimestamp()
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("DataTimestamp() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticHTTPProvider_EmptyFeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      map[string]any
+		selector  func(map[string]any) ([]string, error)
+		wantEmpty bool
+	}{
+		{name: "google empty prefixes", body: map[string]any{"prefixes": []any{}}, selector: googleSelector, wantEmpty: true},
+		{
+			name: "aws prefixes outside the default filter",
+			body: map[string]any{"prefixes": []any{
+				map[string]any{"ip_prefix": "3.5.140.0/22", "service": "EC2", "region": "ap-northeast-2"},
+			}},
+			selector:  awsSelector,
+			wantEmpty: true,
+		},
+		{name: "github empty hooks", body: map[string]any{"hooks": []any{}}, selector: githubSelector, wantEmpty: true},
+		{name: "google missing prefixes", body: map[string]any{"syncToken": "1"}, selector: googleSelector, wantEmpty: false},
+		{name: "github missing hooks", body: map[string]any{"web": []any{"192.0.2.0/24"}}, selector: githubSelector, wantEmpty: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(tt.body)
+			}))
+			defer server.Close()
+
+			provider := &staticHTTPProvider{client: server.Client(), url: server.URL, selector: tt.selector}
+			_, err := provider.Fetch(context.Background())
+			if err == nil {
+				t.Fatal("Fetch() error = nil, want an error")
+			}
+			if got := errors.Is(err, ErrEmptyFeed); got != tt.wantEmpty {
+				t.Errorf("errors.Is(%v, ErrEmptyFeed) = %v, want %v", err, got, tt.wantEmpty)
+			}
+		})
+	}
+}
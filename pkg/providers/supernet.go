@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// FilterSupernets splits cidrs into those contained in at least one of the supernets and
+// those that are not. Bare IP addresses are treated as single-host prefixes; values that
+// cannot be parsed are never contained. An error is returned for an invalid supernet.
+func FilterSupernets(cidrs, supernets []string) (kept, dropped []string, err error) {
+	parents := make([]netip.Prefix, 0, len(supernets))
+	for _, raw := range supernets {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid supernet %q: %w", raw, err)
+		}
+		parents = append(parents, prefix.Masked())
+	}
+
+	kept = make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if containedInAny(cidr, parents) {
+			kept = append(kept, cidr)
+		} else {
+			dropped = append(dropped, cidr)
+		}
+	}
+	return kept, dropped, nil
+}
+
+func containedInAny(cidr string, parents []netip.Prefix) bool {
+	prefix, ok := parsePrefixOrAddr(strings.TrimSpace(cidr))
+	if !ok {
+		return false
+	}
+	for _, parent := range parents {
+		if parent.Bits() <= prefix.Bits() && parent.Contains(prefix.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePrefixOrAddr(value string) (netip.Prefix, bool) {
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return prefix.Masked(), true
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterSupernets(t *testing.T) {
+	tests := []struct {
+		name        string
+		cidrs       []string
+		supernets   []string
+		wantKept    []string
+		wantDropped []string
+		wantErr     bool
+	}{
+		{
+			name:      "in range",
+			cidrs:     []string{"13.32.0.0/15", "13.0.0.0/8", "13.1.2.3"},
+			supernets: []string{"13.0.0.0/8"},
+			wantKept:  []string{"13.32.0.0/15", "13.0.0.0/8", "13.1.2.3"},
+		},
+		{
+			name:        "out of range",
+			cidrs:       []string{"13.32.0.0/15", "52.94.0.0/16", "12.0.0.0/7"},
+			supernets:   []string{"13.0.0.0/8"},
+			wantKept:    []string{"13.32.0.0/15"},
+			wantDropped: []string{"52.94.0.0/16", "12.0.0.0/7"},
+		},
+		{
+			name:        "mixed families",
+			cidrs:       []string{"2600:1f00::/24", "13.32.0.0/15", "2a05:d000::/25"},
+			supernets:   []string{"13.0.0.0/8", "2600::/12"},
+			wantKept:    []string{"2600:1f00::/24", "13.32.0.0/15"},
+			wantDropped: []string{"2a05:d000::/25"},
+		},
+		{
+			name:        "unparseable value",
+			cidrs:       []string{"not-a-cidr"},
+			supernets:   []string{"0.0.0.0/0"},
+			wantKept:    []string{},
+			wantDropped: []string{"not-a-cidr"},
+		},
+		{
+			name:      "invalid supernet",
+			cidrs:     []string{"13.32.0.0/15"},
+			supernets: []string{"13.0.0.0"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, dropped, err := FilterSupernets(tt.cidrs, tt.supernets)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FilterSupernets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("FilterSupernets() kept = %v, want %v", kept, tt.wantKept)
+			}
+			if !reflect.DeepEqual(dropped, tt.wantDropped) {
+				t.Errorf("FilterSupernets() dropped = %v, want %v", dropped, tt.wantDropped)
+			}
+		})
+	}
+}
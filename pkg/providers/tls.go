@@ -0,0 +1,214 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// clientFor returns the HTTP client a provider should use: the shared client, or when
+// insecure is set its counterpart that skips certificate verification. Both are built once
+// by NewFactory, so that their connection pools are reused across fetches.
+func (f *Factory) clientFor(insecure bool) (*http.Client, error) {
+	if f.clientErr != nil {
+		return nil, f.clientErr
+	}
+	if insecure {
+		return f.insecureClient, nil
+	}
+	return f.httpClient, nil
+}
+
+// setClients derives the clients returned by clientFor from base, recording an error
+// returned for every HTTP provider when base cannot be configured.
+func (f *Factory) setClients(base *http.Client) {
+	f.httpClient, f.clientErr = withMinTLSVersion(base, f.minTLSVersion)
+	if f.clientErr == nil {
+		f.insecureClient, f.clientErr = withInsecureSkipVerify(f.httpClient)
+	}
+}
+
+// cloneTransport returns a copy of base holding a clone of its transport, for the caller
+// to adjust through the returned *http.Transport. base is not modified. A client with a
+// custom RoundTripper is an error, since its TLS and proxy settings cannot be reached and
+// silently dropping them would only surface as handshake failures.
+func cloneTransport(base *http.Client) (*http.Client, *http.Transport, error) {
+	if base == nil {
+		base = &http.Client{}
+	}
+	transport, ok := base.Transport.(*http.Transport)
+	if base.Transport == nil {
+		transport, ok = http.DefaultTransport.(*http.Transport), true
+	}
+	if !ok || transport == nil {
+		return nil, nil, fmt.Errorf("provider HTTP client requires an *http.Transport, got %T", base.Transport)
+	}
+	transport = transport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	client := *base
+	client.Transport = transport
+	return &client, transport, nil
+}
+
+// withMinTLSVersion returns a copy of base whose transport refuses TLS versions older than
+// version.
+func withMinTLSVersion(base *http.Client, version uint16) (*http.Client, error) {
+	client, transport, err := cloneTransport(base)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig.MinVersion = version
+	return client, nil
+}
+
+// withInsecureSkipVerify returns a copy of base whose transport skips certificate
+// verification.
+func withInsecureSkipVerify(base *http.Client) (*http.Client, error) {
+	client, transport, err := cloneTransport(base)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec // explicit per-provider opt-in
+	return client, nil
+}
+
+// clientTLS selects the Secrets and ConfigMaps configuring the TLS client of a provider:
+// the certificate it presents for mutual TLS and the CA bundle it verifies servers
+// against. They are read on every fetch, so that renewed certificates are picked up.
+type clientTLS struct {
+	// certSecret names the kubernetes.io/tls style Secret holding the client certificate.
+	certSecret  string
+	caSecret    *corev1.SecretKeySelector
+	caConfigMap *corev1.ConfigMapKeySelector
+}
+
+func (c clientTLS) enabled() bool {
+	return c.certSecret != "" || c.caSecret != nil || c.caConfigMap != nil
+}
+
+// client returns base, or a copy of it configured from the referenced resources.
+func (c clientTLS) client(ctx context.Context, kubeClient client.Reader, namespace string, base *http.Client) (*http.Client, error) {
+	if !c.enabled() {
+		return base, nil
+	}
+	if kubeClient == nil {
+		return nil, fmt.Errorf("kube client not configured for TLS certificates")
+	}
+
+	var certs []tls.Certificate
+	var roots *x509.CertPool
+	addRoots := func(bundle []byte, source string) error {
+		if roots == nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("%s holds no PEM certificates", source)
+		}
+		return nil
+	}
+
+	if ref := c.caSecret; ref != nil {
+		bundle, err := readSecretKey(ctx, kubeClient, namespace, *ref)
+		if err != nil {
+			return nil, fmt.Errorf("loading CA bundle: %w", err)
+		}
+		if err := addRoots([]byte(bundle), fmt.Sprintf("secret %s/%s key %s", namespace, ref.Name, ref.Key)); err != nil {
+			return nil, err
+		}
+	}
+	if ref := c.caConfigMap; ref != nil {
+		bundle, err := readConfigMapKey(ctx, kubeClient, namespace, *ref)
+		if err != nil {
+			return nil, fmt.Errorf("loading CA bundle: %w", err)
+		}
+		if err := addRoots(bundle, fmt.Sprintf("configmap %s/%s key %s", namespace, ref.Name, ref.Key)); err != nil {
+			return nil, err
+		}
+	}
+	if c.certSecret != "" {
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Name: c.certSecret, Namespace: namespace}
+		if err := kubeClient.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("fetching client certificate secret %s: %w", key.String(), err)
+		}
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate from secret %s keys %s and %s: %w", key.String(), corev1.TLSCertKey, corev1.TLSPrivateKeyKey, err)
+		}
+		certs = append(certs, cert)
+		if bundle, ok := secret.Data[corev1.ServiceAccountRootCAKey]; ok {
+			if err := addRoots(bundle, fmt.Sprintf("secret %s key %s", key.String(), corev1.ServiceAccountRootCAKey)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return withTLSCertificates(base, certs, roots)
+}
+
+// readConfigMapKey returns the value stored under selector.Key in the data or binaryData
+// of the named ConfigMap.
+func readConfigMapKey(ctx context.Context, kubeClient client.Reader, namespace string, selector corev1.ConfigMapKeySelector) ([]byte, error) {
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: selector.Name, Namespace: namespace}
+	if err := kubeClient.Get(ctx, key, configMap); err != nil {
+		return nil, fmt.Errorf("fetching configmap %s: %w", key.String(), err)
+	}
+	if value, ok := configMap.Data[selector.Key]; ok {
+		return []byte(value), nil
+	}
+	if value, ok := configMap.BinaryData[selector.Key]; ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("configmap %s missing key %s", key.String(), selector.Key)
+}
+
+// withTLSCertificates returns a copy of base whose transport presents certs and, when
+// roots is set, verifies servers against roots only.
+func withTLSCertificates(base *http.Client, certs []tls.Certificate, roots *x509.CertPool) (*http.Client, error) {
+	client, transport, err := cloneTransport(base)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) > 0 {
+		transport.TLSClientConfig.Certificates = certs
+	}
+	if roots != nil {
+		transport.TLSClientConfig.RootCAs = roots
+	}
+	return client, nil
+}
+
+// ParseTLSVersion converts a version such as "1.2" into its crypto/tls constant.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q, expected 1.0, 1.1, 1.2 or 1.3", version)
+	}
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func warnInsecure(ctx context.Context, insecure bool, url string) {
+	if insecure {
+		log.FromContext(ctx).Error(nil, "TLS certificate verification is disabled for provider", "url", url)
+	}
+}
@@ -0,0 +1,376 @@
+package providers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/sugaf1204/botnetworkpolicy-operator/api/v1alpha1"
+)
+
+func TestFactory_InsecureSkipTLSVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"prefixes":[{"ipv4Prefix":"8.8.8.0/24"}]}`))
+	}))
+	defer server.Close()
+
+	shared := &http.Client{}
+	factory := NewFactory(nil, shared)
+	insecure := true
+
+	verified, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name:   "google",
+		Google: &v1alpha1.GoogleProviderSpec{URL: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	if _, err := verified.Fetch(context.Background()); err == nil {
+		t.Fatal("expected certificate verification error for self-signed server, got nil")
+	}
+
+	skipped, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name:   "google",
+		Google: &v1alpha1.GoogleProviderSpec{URL: server.URL, InsecureSkipTLSVerify: &insecure},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	got, err := skipped.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != "8.8.8.0/24" {
+		t.Errorf("Fetch() = %v, want [8.8.8.0/24]", got)
+	}
+
+	if shared.Transport != nil {
+		t.Error("shared HTTP client was modified by an insecure provider")
+	}
+
+	// Insecure providers share one client, so that syncs do not leave a connection pool
+	// behind each time they rebuild their providers.
+	again, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name:   "google",
+		Google: &v1alpha1.GoogleProviderSpec{URL: server.URL, InsecureSkipTLSVerify: &insecure},
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	if again.(*staticHTTPProvider).client != skipped.(*staticHTTPProvider).client {
+		t.Error("insecure providers built from the same factory use different HTTP clients")
+	}
+}
+
+func TestFactory_CustomRoundTripper(t *testing.T) {
+	roundTripper := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("unreachable")
+	})
+	factory := NewFactory(nil, &http.Client{Transport: roundTripper})
+
+	_, err := factory.FromSpec("default", v1alpha1.ProviderSpec{
+		Name:   "google",
+		Google: &v1alpha1.GoogleProviderSpec{URL: "https://example.com"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "requires an *http.Transport") {
+		t.Fatalf("FromSpec() with a custom RoundTripper error = %v, want it rejected", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFactory_MinTLSVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"prefixes":[{"ipv4Prefix":"8.8.8.0/24"}]}`))
+	}))
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}
+	server.StartTLS()
+	defer server.Close()
+
+	spec := v1alpha1.ProviderSpec{Name: "google", Google: &v1alpha1.GoogleProviderSpec{URL: server.URL}}
+	fetch := func(opts ...FactoryOption) error {
+		t.Helper()
+		provider, err := NewFactory(nil, server.Client(), opts...).FromSpec("default", spec)
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		_, err = provider.Fetch(context.Background())
+		return err
+	}
+
+	if err := fetch(); err == nil || !strings.Contains(err.Error(), "protocol version") {
+		t.Fatalf("expected handshake failure against a TLS 1.1 server with the default minimum, got %v", err)
+	}
+	if err := fetch(WithMinTLSVersion(tls.VersionTLS13)); err == nil {
+		t.Fatal("expected handshake failure against a TLS 1.1 server with a TLS 1.3 minimum")
+	}
+	if err := fetch(WithMinTLSVersion(tls.VersionTLS10)); err != nil {
+		t.Fatalf("Fetch() with a TLS 1.0 minimum error = %v", err)
+	}
+}
+
+// selfSignedClientCertificate returns a PEM certificate and key usable for TLS client
+// authentication, together with its parsed certificate.
+func selfSignedClientCertificate(t *testing.T) (certPEM, keyPEM []byte, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "botnetworkpolicy-operator"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		cert
+}
+
+func TestJSONEndpointProvider_FetchWithClientCertificate(t *testing.T) {
+	certPEM, keyPEM, clientCert := selfSignedClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cidrs":["10.0.0.0/24"]}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "client-tls", Namespace: "default"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": certPEM, "tls.key": keyPEM, "ca.crt": serverCA},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "client-tls-without-key", Namespace: "default"},
+			Data:       map[string][]byte{"tls.crt": certPEM, "ca.crt": serverCA},
+		},
+	).Build()
+
+	// A plain client does not trust the test server, so a successful fetch also shows that
+	// ca.crt was used to verify it.
+	fetch := func(httpClient *http.Client, secretRef *corev1.LocalObjectReference) ([]string, error) {
+		t.Helper()
+		provider, err := NewFactory(kubeClient, httpClient).FromSpec("default", v1alpha1.ProviderSpec{
+			Name: "jsonEndpoint",
+			JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{
+				URL:                server.URL,
+				FieldPath:          "cidrs",
+				TLSClientSecretRef: secretRef,
+			},
+		})
+		if err != nil {
+			t.Fatalf("FromSpec() error = %v", err)
+		}
+		return provider.Fetch(context.Background())
+	}
+
+	got, err := fetch(&http.Client{}, &corev1.LocalObjectReference{Name: "client-tls"})
+	if err != nil {
+		t.Fatalf("Fetch() with a client certificate error = %v", err)
+	}
+	if want := []string{"10.0.0.0/24"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() = %v, want %v", got, want)
+	}
+
+	if _, err := fetch(server.Client(), nil); err == nil {
+		t.Fatal("expected the server to reject a fetch without a client certificate")
+	}
+
+	_, err = fetch(&http.Client{}, &corev1.LocalObjectReference{Name: "client-tls-without-key"})
+	if err == nil || !strings.Contains(err.Error(), "loading client certificate from secret default/client-tls-without-key") {
+		t.Fatalf("expected a client certificate loading error, got %v", err)
+	}
+
+	_, err = fetch(&http.Client{}, &corev1.LocalObjectReference{Name: "missing"})
+	if err == nil || !strings.Contains(err.Error(), "fetching client certificate secret default/missing") {
+		t.Fatalf("expected a missing secret error, got %v", err)
+	}
+}
+
+// newCASignedTLSServer starts a TLS server for 127.0.0.1 whose certificate is signed by a
+// freshly generated CA, and returns the server with the CA's PEM certificate.
+func newCASignedTLSServer(t *testing.T, handler http.Handler) (*httptest.Server, []byte) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "internal CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "cidrs.internal"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(handler)
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+}
+
+func TestFactory_CABundle(t *testing.T) {
+	server, caPEM := newCASignedTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cidrs":["10.0.0.0/24"],"prefixes":[{"ipv4Prefix":"8.8.8.0/24"}]}`))
+	}))
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: "default"},
+			Data:       map[string][]byte{"ca.crt": caPEM, "garbage": []byte("not a certificate")},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: "default"},
+			Data:       map[string]string{"ca.crt": string(caPEM)},
+		},
+	).Build()
+	caSecret := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "internal-ca"}, Key: "ca.crt"}
+	caConfigMap := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "internal-ca"}, Key: "ca.crt"}
+
+	tests := []struct {
+		name    string
+		spec    v1alpha1.ProviderSpec
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "jsonEndpoint trusting a Secret bundle",
+			spec: v1alpha1.ProviderSpec{Name: "jsonEndpoint", JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "cidrs", CASecretRef: caSecret}},
+			want: []string{"10.0.0.0/24"},
+		},
+		{
+			name: "google trusting a ConfigMap bundle",
+			spec: v1alpha1.ProviderSpec{Name: "google", Google: &v1alpha1.GoogleProviderSpec{URL: server.URL, CAConfigMapRef: caConfigMap}},
+			want: []string{"8.8.8.0/24"},
+		},
+		{
+			name:    "without a bundle",
+			spec:    v1alpha1.ProviderSpec{Name: "jsonEndpoint", JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{URL: server.URL, FieldPath: "cidrs"}},
+			wantErr: "certificate signed by unknown authority",
+		},
+		{
+			name: "bundle without certificates",
+			spec: v1alpha1.ProviderSpec{Name: "jsonEndpoint", JSONEndpoint: &v1alpha1.JSONEndpointProviderSpec{
+				URL:         server.URL,
+				FieldPath:   "cidrs",
+				CASecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "internal-ca"}, Key: "garbage"},
+			}},
+			wantErr: "secret default/internal-ca key garbage holds no PEM certificates",
+		},
+		{
+			name: "missing ConfigMap",
+			spec: v1alpha1.ProviderSpec{Name: "aws", AWS: &v1alpha1.AWSProviderSpec{
+				URL:            server.URL,
+				CAConfigMapRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}, Key: "ca.crt"},
+			}},
+			wantErr: "loading CA bundle: fetching configmap default/missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The shared client trusts only the system roots, so a successful fetch shows
+			// that the bundle was used.
+			shared := &http.Client{}
+			provider, err := NewFactory(kubeClient, shared).FromSpec("default", tt.spec)
+			if err != nil {
+				t.Fatalf("FromSpec() error = %v", err)
+			}
+			got, err := provider.Fetch(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.want)
+			}
+			if shared.Transport != nil {
+				t.Error("the shared HTTP client was modified")
+			}
+		})
+	}
+}